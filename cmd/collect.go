@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/isaacaudet/aitop/internal/config"
+	"github.com/isaacaudet/aitop/internal/otlp"
+	"github.com/spf13/cobra"
+)
+
+var collectAddr string
+
+var collectCmd = &cobra.Command{
+	Use:   "collect",
+	Short: "Receive Claude Code telemetry over OTLP/HTTP",
+	Long: `Run a local OTLP/HTTP receiver (JSON or protobuf) and store the metrics
+and events Claude Code exports, so its costs and lines changed stay
+available after transcripts are pruned.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := config.Load()
+		dir := cfg.TelemetryDir()
+		if dir == "" {
+			return fmt.Errorf("no data directory; set data_dir in %s", config.DefaultConfigPath())
+		}
+
+		fmt.Printf("Storing telemetry in %s\n", dir)
+		fmt.Printf("Listening on http://%s; point Claude Code at it with:\n\n", collectAddr)
+		fmt.Println("  export CLAUDE_CODE_ENABLE_TELEMETRY=1")
+		fmt.Println("  export OTEL_METRICS_EXPORTER=otlp")
+		fmt.Println("  export OTEL_LOGS_EXPORTER=otlp")
+		fmt.Println("  export OTEL_EXPORTER_OTLP_PROTOCOL=http/protobuf")
+		fmt.Printf("  export OTEL_EXPORTER_OTLP_ENDPOINT=http://%s\n\n", collectAddr)
+
+		return http.ListenAndServe(collectAddr, otlp.Handler(&otlp.Store{Dir: dir}))
+	},
+}
+
+func init() {
+	collectCmd.Flags().StringVar(&collectAddr, "addr", "localhost:4318", "address to listen on")
+	rootCmd.AddCommand(collectCmd)
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/isaacaudet/aitop/internal/config"
+	"github.com/isaacaudet/aitop/internal/invoice"
+	"github.com/spf13/cobra"
+)
+
+var importVendor string
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import data aitop can't read from local logs",
+}
+
+var importUsageCmd = &cobra.Command{
+	Use:   "usage <csv>...",
+	Short: "Import vendor usage/cost CSV exports for reconciliation",
+	Long: `Import usage or cost CSV exports from the Anthropic Console, the OpenAI
+usage dashboard or Google Cloud billing as invoiced spend. Re-importing days
+replaces what was imported for them before. Compare with estimates using
+` + "`aitop reconcile`" + ` or the Reconcile view.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := config.Load()
+		applyPricing(cfg)
+		dir := cfg.InvoiceDir()
+		if dir == "" {
+			return fmt.Errorf("no data directory; set data_dir in %s", config.DefaultConfigPath())
+		}
+		store := &invoice.Store{Dir: dir}
+
+		for _, path := range args {
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			vendor, lines, err := invoice.ParseCSV(f, importVendor)
+			f.Close()
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			if len(lines) == 0 {
+				fmt.Printf("%s: no usage rows\n", path)
+				continue
+			}
+			if err := store.Import(vendor, lines); err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			var cost float64
+			for _, l := range lines {
+				cost += l.Cost
+			}
+			fmt.Printf("%s: imported %s $%.2f, %s to %s (%d day/model lines)\n",
+				path, vendor, cost, lines[0].Date, lines[len(lines)-1].Date, len(lines))
+		}
+		return nil
+	},
+}
+
+func init() {
+	importUsageCmd.Flags().StringVar(&importVendor, "vendor", "", "export's vendor: anthropic, openai or gcp (detected by default)")
+	importCmd.AddCommand(importUsageCmd)
+	rootCmd.AddCommand(importCmd)
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/isaacaudet/aitop/internal/apiproxy"
+	"github.com/isaacaudet/aitop/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	proxyAddr         string
+	proxyAnthropicURL string
+	proxyOpenAIURL    string
+	proxyCallerHeader string
+	proxyStreamUsage  bool
+)
+
+var proxyCmd = &cobra.Command{
+	Use:   "proxy",
+	Short: "Record direct Anthropic and OpenAI API usage through a local proxy",
+	Long: `Run a local reverse proxy for the Anthropic and OpenAI APIs. Requests are
+forwarded as sent, less the caller tag header, and each response's token
+usage is recorded with its model, latency and caller tag for the API
+provider.
+
+OpenAI only reports the usage of streaming chat completions when the request
+sets stream_options.include_usage. Pass --include-stream-usage to have the
+proxy add it to requests that don't; otherwise their usage isn't recorded.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := config.Load()
+		dir := cfg.APILedgerDir()
+		if dir == "" {
+			return fmt.Errorf("no data directory; set data_dir in %s", config.DefaultConfigPath())
+		}
+		anthropicURL, err := url.Parse(proxyAnthropicURL)
+		if err != nil {
+			return fmt.Errorf("--anthropic-url: %w", err)
+		}
+		openAIURL, err := url.Parse(proxyOpenAIURL)
+		if err != nil {
+			return fmt.Errorf("--openai-url: %w", err)
+		}
+
+		p := &apiproxy.Proxy{
+			AnthropicURL: anthropicURL,
+			OpenAIURL:    openAIURL,
+			CallerHeader: proxyCallerHeader,
+			Ledger:       &apiproxy.Ledger{Dir: dir},
+
+			IncludeStreamUsage: proxyStreamUsage,
+		}
+
+		fmt.Printf("Recording API usage in %s\n", dir)
+		fmt.Printf("Listening on http://%s; point your apps at it with:\n\n", proxyAddr)
+		fmt.Printf("  export ANTHROPIC_BASE_URL=http://%s/anthropic\n", proxyAddr)
+		fmt.Printf("  export OPENAI_BASE_URL=http://%s/openai/v1\n\n", proxyAddr)
+		fmt.Printf("Tag requests with a %s header to tell callers apart.\n", proxyCallerHeader)
+
+		return http.ListenAndServe(proxyAddr, p.Handler())
+	},
+}
+
+func init() {
+	proxyCmd.Flags().StringVar(&proxyAddr, "addr", "localhost:4319", "address to listen on")
+	proxyCmd.Flags().StringVar(&proxyAnthropicURL, "anthropic-url", "https://api.anthropic.com", "Anthropic API to forward to")
+	proxyCmd.Flags().StringVar(&proxyOpenAIURL, "openai-url", "https://api.openai.com", "OpenAI API to forward to")
+	proxyCmd.Flags().StringVar(&proxyCallerHeader, "caller-header", apiproxy.DefaultCallerHeader, "request header holding the caller tag")
+	proxyCmd.Flags().BoolVar(&proxyStreamUsage, "include-stream-usage", false, "add stream_options.include_usage to streaming OpenAI chat completions requests")
+	rootCmd.AddCommand(proxyCmd)
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/isaacaudet/aitop/internal/config"
+	"github.com/isaacaudet/aitop/internal/invoice"
+	"github.com/isaacaudet/aitop/internal/ledger"
+	"github.com/spf13/cobra"
+)
+
+var reconcileCmd = &cobra.Command{
+	Use:   "reconcile",
+	Short: "Compare imported invoices with estimated spend per day and model",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := config.Load()
+		applyPricing(cfg)
+		invoiced, err := (&invoice.Store{Dir: cfg.InvoiceDir()}).Load()
+		if err != nil {
+			return err
+		}
+		if len(invoiced) == 0 {
+			return fmt.Errorf("no invoices imported; run `aitop import usage <csv>` first")
+		}
+		aggData, _ := ledger.LoadAll(cfg.LedgerPath(), AllProviders(cfg), true)
+		r := invoice.Reconcile(invoiced, invoice.Estimates(aggData.Providers, cfg.Reconcile.Providers))
+
+		fmt.Printf("Reconciliation %s to %s\n", r.From, r.To)
+		fmt.Println("═══════════════════════════════════════════════════════")
+		fmt.Printf("  Invoiced $%.2f  Estimated $%.2f  Gap %s\n", r.Invoiced, r.Estimated,
+			invoice.Gap{Invoiced: r.Invoiced, Estimated: r.Estimated}.FormatDiff())
+		fmt.Println()
+
+		fmt.Println("  By day")
+		fmt.Printf("    %-10s  %-9s  %10s  %10s  %16s\n", "Date", "Vendor", "Invoiced", "Estimated", "Gap")
+		for _, g := range r.Days {
+			printGapRow(g.Date, g.Vendor, g)
+		}
+		fmt.Println()
+		fmt.Println("  By model")
+		fmt.Printf("    %-22s  %10s  %10s  %16s\n", "Model", "Invoiced", "Estimated", "Gap")
+		for _, g := range r.Models {
+			printGapRow(g.Model, "", g)
+		}
+		fmt.Println()
+		fmt.Println("  ! marks gaps of at least $1 and 10%.")
+		return nil
+	},
+}
+
+func printGapRow(label, vendor string, g invoice.Gap) {
+	mark := " "
+	if g.Significant() {
+		mark = "!"
+	}
+	if vendor != "" {
+		fmt.Printf("  %s %-10s  %-9s  %10s  %10s  %16s\n", mark, label, vendor,
+			fmt.Sprintf("$%.2f", g.Invoiced), fmt.Sprintf("$%.2f", g.Estimated), g.FormatDiff())
+		return
+	}
+	fmt.Printf("  %s %-22s  %10s  %10s  %16s\n", mark, label,
+		fmt.Sprintf("$%.2f", g.Invoiced), fmt.Sprintf("$%.2f", g.Estimated), g.FormatDiff())
+}
+
+func init() {
+	rootCmd.AddCommand(reconcileCmd)
+}
@@ -3,9 +3,13 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/isaacaudet/aitop/internal/config"
+	"github.com/isaacaudet/aitop/internal/ledger"
+	"github.com/isaacaudet/aitop/internal/model"
 	"github.com/isaacaudet/aitop/internal/parser"
 	"github.com/isaacaudet/aitop/internal/provider"
 	"github.com/isaacaudet/aitop/internal/tui"
@@ -13,14 +17,195 @@ import (
 	"golang.org/x/term"
 )
 
-// AllProviders returns all registered providers.
-func AllProviders() []provider.Provider {
-	return []provider.Provider{
-		provider.NewClaude(),
-		provider.NewCursor(),
-		provider.NewGemini(),
-		provider.NewCodex(),
+// AllProviders returns all registered providers, reading from the data
+// directories resolved by cfg.
+func AllProviders(cfg config.Config) []provider.Provider {
+	projects := projectResolver(cfg, "gemini-projects.json")
+
+	gemini := provider.NewGemini(cfg.GeminiDirs())
+	gemini.TelemetryPaths = config.UniquePaths(cfg.Paths.GeminiTelemetry)
+	gemini.Projects = projects
+
+	qwen := provider.NewQwen(cfg.QwenDirs())
+	qwen.Projects = projects
+
+	cursor := provider.NewCursor()
+	if plan, ok := cfg.PlanFor("cursor"); ok && plan.Requests > 0 {
+		cursor.Quota = &provider.RequestQuota{
+			Name:          plan.Name,
+			Limit:         plan.Requests,
+			CycleStartDay: plan.CycleStartDay,
+			Models:        plan.RequestModels,
+			Exhausted:     "slow mode",
+		}
+	}
+
+	claude := provider.NewClaude(cfg.ClaudeDirs())
+	claude.StatsCaches = cfg.StatsCachePaths()
+	claudeTelemetry := provider.NewClaudeTelemetry(cfg.TelemetryDir(), cfg.ClaudeDirs())
+	claudeTelemetry.StatsCaches = claude.StatsCaches
+
+	providers := []provider.Provider{
+		claude,
+		cursor,
+		gemini,
+		qwen,
+		provider.NewCodex(cfg.CodexDirs()),
+		provider.NewOpenCode(cfg.OpenCodeDirs()),
+		provider.NewCrush(cfg.CrushDirs(), cfg.WorkspaceRoots),
+		provider.NewLLMCLI(cfg.LLMDirs()),
+		provider.NewGoose(cfg.GooseDirs()),
+		provider.NewAmazonQ(cfg.AmazonQDirs()),
+		provider.NewZed(cfg.ZedDirs()),
+		claudeTelemetry,
+		provider.NewAPI(cfg.APILedgerDir()),
+		provider.NewAider(
+			config.UniquePaths(cfg.Paths.Aider),
+			cfg.WorkspaceRoots,
+			config.UniquePaths(cfg.Paths.AiderAnalytics),
+		),
+	}
+
+	// Copilot Chat and Cline and its forks, in every editor that has them
+	// installed.
+	editors := config.UniquePaths(cfg.Paths.Editors)
+	if len(editors) == 0 {
+		editors = provider.EditorUserDirs()
+	}
+	copilot := provider.NewCopilot(editors)
+	if plan, ok := cfg.PlanFor("copilot"); ok && plan.Requests > 0 {
+		copilot.Quota.Name = plan.Name
+		copilot.Quota.Limit = plan.Requests
+		copilot.Quota.CycleStartDay = plan.CycleStartDay
+		copilot.Quota.Models = plan.RequestModels
+	}
+	providers = append(providers, copilot)
+	for _, flavor := range []provider.VSCodeAgentFlavor{provider.ClineFlavor, provider.RooCodeFlavor, provider.KiloCodeFlavor} {
+		providers = append(providers, provider.NewVSCodeAgent(flavor, editors))
+	}
+
+	// Gemini CLI forks declared in config.
+	for _, fork := range cfg.GeminiForks {
+		if fork.Name == "" || fork.ConfigDir == "" {
+			continue
+		}
+		p := provider.NewGeminiFork(provider.GeminiFlavor{
+			Name:         fork.Name,
+			Icon:         fork.Icon,
+			Color:        fork.Color,
+			DefaultModel: fork.DefaultModel,
+		}, config.UniquePaths([]string{fork.ConfigDir}))
+		p.Projects = projects
+		providers = append(providers, p)
+	}
+
+	// Providers declared entirely in config.
+	for _, c := range cfg.CustomProviders {
+		if c.Name == "" || len(c.Files) == 0 {
+			continue
+		}
+		files := make([]string, len(c.Files))
+		for i, f := range c.Files {
+			files[i] = config.ExpandHome(f)
+		}
+		providers = append(providers, provider.NewCustom(provider.CustomSpec{
+			Name:         c.Name,
+			Icon:         c.Icon,
+			Color:        c.Color,
+			Files:        files,
+			Format:       c.Format,
+			Records:      c.Records,
+			DefaultModel: c.DefaultModel,
+			Fields:       provider.CustomFields(c.Fields),
+			Filters:      c.Filters,
+		}))
+	}
+
+	// External provider commands.
+	for _, pc := range cfg.Plugins {
+		if pc.Command == "" {
+			continue
+		}
+		p := provider.NewPlugin(pc.Name, pc.Icon, pc.Color, config.ExpandHome(pc.Command), pc.Args)
+		if d, err := time.ParseDuration(pc.Timeout); err == nil && d > 0 {
+			p.Timeout = d
+		}
+		if pc.SinceDays > 0 {
+			p.Since = time.Now().AddDate(0, 0, -pc.SinceDays)
+		}
+		providers = append(providers, p)
 	}
+
+	return providers
+}
+
+// applyPricing installs the model prices configured under [pricing]. Each
+// command that prices usage calls it once, right after loading the config.
+func applyPricing(cfg config.Config) {
+	if len(cfg.Pricing) == 0 {
+		return
+	}
+	prices := make(map[string]model.ModelPricing, len(cfg.Pricing))
+	for name, p := range cfg.Pricing {
+		prices[name] = model.ModelPricing{
+			InputPerMTok:      p.Input,
+			OutputPerMTok:     p.Output,
+			CacheReadPerMTok:  p.CacheRead,
+			CacheWritePerMTok: p.CacheWrite,
+		}
+	}
+	model.SetCustomPricing(prices)
+}
+
+// projectResolver builds a resolver for hashed project ids that draws
+// candidate directories from the Claude and Codex data aitop already reads.
+func projectResolver(cfg config.Config, cacheFile string) *provider.ProjectResolver {
+	r := &provider.ProjectResolver{
+		ClaudeDirs:     cfg.ClaudeDirs(),
+		CodexDirs:      cfg.CodexDirs(),
+		WorkspaceRoots: cfg.WorkspaceRoots,
+	}
+	if dir := config.DefaultCacheDir(); dir != "" {
+		r.CachePath = filepath.Join(dir, cacheFile)
+	}
+	return r
+}
+
+// loadStatsCache reads the Claude stats cache from stats_cache_path when it is
+// set, and otherwise merges the caches of every resolved Claude config dir.
+// Each cache is snapshotted in the ledger, and what changed since the
+// previous snapshots is returned alongside it (nil until there are two). If
+// the snapshot fails the cache is still returned, along with the error.
+func loadStatsCache(cfg config.Config) (*model.StatsCache, *model.StatsDelta, error) {
+	paths := cfg.StatsCachePaths()
+	caches := make(map[string]*model.StatsCache, len(paths))
+	var parsed []*model.StatsCache
+	var lastErr error
+	for _, path := range paths {
+		cache, err := parser.ParseStatsCache(path)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		caches[path] = cache
+		parsed = append(parsed, cache)
+	}
+	if len(parsed) == 0 {
+		if lastErr == nil {
+			lastErr = fmt.Errorf("no Claude config directory found")
+		}
+		return nil, nil, lastErr
+	}
+
+	merged := model.MergeStatsCaches(parsed...)
+	if cfg.LedgerPath() == "" {
+		return merged, nil, nil
+	}
+	delta, err := ledger.SnapshotStatsCaches(cfg.LedgerPath(), caches)
+	if err != nil {
+		return merged, nil, fmt.Errorf("recording stats cache snapshot: %w", err)
+	}
+	return merged, delta, nil
 }
 
 var rootCmd = &cobra.Command{
@@ -29,18 +214,16 @@ var rootCmd = &cobra.Command{
 	Long:  "aitop - A beautiful TUI for visualizing AI tool usage, costs, and projections across Claude Code, Cursor, Gemini, Codex, and more.",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cfg := config.Load()
+		applyPricing(cfg)
 
-		statsPath := cfg.StatsCachePath
-		if statsPath == "" {
-			statsPath = parser.DefaultStatsCachePath()
-		}
-
-		cache, err := parser.ParseStatsCache(statsPath)
-		if err != nil {
+		cache, statsDiff, err := loadStatsCache(cfg)
+		if err != nil && cache != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		} else if err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: Could not load Claude stats cache: %v\n", err)
 		}
 
-		providers := AllProviders()
+		providers := AllProviders(cfg)
 
 		// Detect available providers.
 		var available []string
@@ -59,7 +242,7 @@ var rootCmd = &cobra.Command{
 			return summaryCmd.RunE(cmd, args)
 		}
 
-		m := tui.New(cache, providers)
+		m := tui.New(cache, statsDiff, providers)
 		p := tea.NewProgram(m, tea.WithAltScreen())
 		if _, err := p.Run(); err != nil {
 			return err
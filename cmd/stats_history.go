@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/isaacaudet/aitop/internal/config"
+	"github.com/isaacaudet/aitop/internal/ledger"
+	"github.com/isaacaudet/aitop/internal/model"
+	"github.com/spf13/cobra"
+)
+
+var statsHistoryLast int
+
+var statsHistoryCmd = &cobra.Command{
+	Use:   "stats-history",
+	Short: "Show how the Claude stats cache changed between snapshots",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := config.Load()
+		applyPricing(cfg)
+		if cfg.LedgerPath() == "" {
+			return fmt.Errorf("no data directory; set data_dir in the config")
+		}
+		// Loading snapshots the current caches first.
+		if _, _, err := loadStatsCache(cfg); err != nil {
+			return err
+		}
+		l, err := ledger.Open(cfg.LedgerPath())
+		if err != nil {
+			return err
+		}
+		defer l.Close()
+		snaps, err := l.StatsSnapshots()
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("Claude Stats History")
+		fmt.Println("═══════════════════════════════════════════════════════")
+		counts := make(map[string]int)
+		var sources []string
+		for _, s := range snaps {
+			if counts[s.Source] == 0 {
+				sources = append(sources, s.Source)
+			}
+			counts[s.Source]++
+		}
+		for _, source := range sources {
+			fmt.Printf("  %s  %d snapshots\n", source, counts[source])
+		}
+		fmt.Println()
+
+		changes := ledger.StatsChanges(snaps)
+		if len(changes) == 0 {
+			fmt.Println("  No changes yet. A snapshot is taken whenever Claude Code recomputes its stats.")
+			return nil
+		}
+		sort.SliceStable(changes, func(i, j int) bool { return changes[i].To < changes[j].To })
+		if statsHistoryLast > 0 && len(changes) > statsHistoryLast {
+			changes = changes[len(changes)-statsHistoryLast:]
+		}
+		for _, c := range changes {
+			printStatsChange(c, len(sources) > 1)
+		}
+		return nil
+	},
+}
+
+func printStatsChange(c ledger.StatsChange, showSource bool) {
+	fmt.Printf("  %s → %s  +%d sessions  +%d msgs  +%s tokens  +$%.2f\n",
+		c.From, c.To, c.Sessions, c.Messages, formatTokens(c.Tokens()), c.Cost())
+	if showSource {
+		fmt.Printf("      %s\n", c.Source)
+	}
+
+	type modelGrowth struct {
+		name   string
+		tokens int
+		cost   float64
+	}
+	var models []modelGrowth
+	for name, mu := range c.Models {
+		models = append(models, modelGrowth{
+			name:   model.NormalizeModelName(name),
+			tokens: mu.InputTokens + mu.OutputTokens + mu.CacheReadInputTokens + mu.CacheCreationInputTokens,
+			cost:   model.CalculateCostFromModelUsage(name, mu),
+		})
+	}
+	sort.Slice(models, func(i, j int) bool {
+		if models[i].cost != models[j].cost {
+			return models[i].cost > models[j].cost
+		}
+		return models[i].tokens > models[j].tokens
+	})
+	for _, m := range models {
+		fmt.Printf("      %-22s  %9s tokens  +$%.2f\n", m.name, "+"+formatTokens(m.tokens), m.cost)
+	}
+
+	// The three hours that picked up the most activity.
+	hours := make([]string, 0, len(c.Hours))
+	for h := range c.Hours {
+		hours = append(hours, h)
+	}
+	sort.Slice(hours, func(i, j int) bool {
+		if c.Hours[hours[i]] != c.Hours[hours[j]] {
+			return c.Hours[hours[i]] > c.Hours[hours[j]]
+		}
+		return hours[i] < hours[j]
+	})
+	if len(hours) > 0 {
+		fmt.Printf("      %-22s", "busiest hours")
+		for _, h := range hours[:min(3, len(hours))] {
+			hour, _ := strconv.Atoi(h)
+			fmt.Printf("  %02d:00 +%d", hour, c.Hours[h])
+		}
+		fmt.Println()
+	}
+
+	if ls := c.LongestSession; ls != nil {
+		d := (time.Duration(ls.Duration) * time.Millisecond).Round(time.Minute)
+		fmt.Printf("      %-22s  %s  %dh%02dm  %d msgs\n", "new longest session", ls.SessionID,
+			int(d.Hours()), int(d.Minutes())%60, ls.MessageCount)
+	}
+	fmt.Println()
+}
+
+func init() {
+	statsHistoryCmd.Flags().IntVar(&statsHistoryLast, "last", 0, "Show only the most recent N changes")
+	rootCmd.AddCommand(statsHistoryCmd)
+}
@@ -2,10 +2,11 @@ package cmd
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/isaacaudet/aitop/internal/config"
+	"github.com/isaacaudet/aitop/internal/ledger"
 	"github.com/isaacaudet/aitop/internal/model"
-	"github.com/isaacaudet/aitop/internal/parser"
 	"github.com/isaacaudet/aitop/internal/provider"
 	"github.com/spf13/cobra"
 )
@@ -15,15 +16,11 @@ var summaryCmd = &cobra.Command{
 	Short: "Print a one-shot usage summary across all AI tools",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cfg := config.Load()
-
-		statsPath := cfg.StatsCachePath
-		if statsPath == "" {
-			statsPath = parser.DefaultStatsCachePath()
-		}
+		applyPricing(cfg)
 
 		// Load all providers.
-		providers := AllProviders()
-		aggData := provider.LoadAll(providers)
+		providers := AllProviders(cfg)
+		aggData, ledgerErr := ledger.LoadAll(cfg.LedgerPath(), providers, true)
 
 		fmt.Println("aitop — AI Usage Dashboard")
 		fmt.Println("═══════════════════════════════════════════════════════")
@@ -31,9 +28,16 @@ var summaryCmd = &cobra.Command{
 
 		// Per-provider summary.
 		for _, p := range aggData.Providers {
+			// Figures that include estimated token counts are prefixed with "~".
+			est := ""
+			for _, m := range p.Models {
+				if m.Estimated {
+					est = "~"
+				}
+			}
 			fmt.Printf("  %s %s", p.Icon, p.ProviderName)
 			if p.TotalCost > 0 {
-				fmt.Printf("  $%.2f", p.TotalCost)
+				fmt.Printf("  %s$%.2f", est, p.TotalCost)
 			}
 			if p.Generations > 0 {
 				fmt.Printf("  %d generations", p.Generations)
@@ -41,20 +45,35 @@ var summaryCmd = &cobra.Command{
 			if len(p.Sessions) > 0 {
 				fmt.Printf("  %d sessions", len(p.Sessions))
 			}
+			if p.Prompts != nil && p.Prompts.Total > 0 {
+				fmt.Printf("  %d prompts", p.Prompts.Total)
+			}
 			var totalTokens int
 			for _, m := range p.Models {
 				totalTokens += m.InputTokens + m.OutputTokens + m.CacheRead + m.CacheWrite
 			}
 			if totalTokens > 0 {
-				fmt.Printf("  %s tokens", formatTokens(totalTokens))
+				fmt.Printf("  %s%s tokens", est, formatTokens(totalTokens))
 			}
 			fmt.Println()
+			if p.Quota != nil {
+				printQuota(p.Quota)
+			}
+		}
+		for _, e := range aggData.Errors {
+			fmt.Printf("  ! %s failed to load: %v\n", e.Provider, e.Err)
+		}
+		if ledgerErr != nil {
+			fmt.Printf("  ! usage history not recorded: %v\n", ledgerErr)
 		}
 		fmt.Println()
 
 		// Claude-specific detailed stats.
-		cache, err := parser.ParseStatsCache(statsPath)
-		if err == nil {
+		cache, _, err := loadStatsCache(cfg)
+		if err != nil && cache != nil {
+			fmt.Printf("  ! %v\n\n", err)
+		}
+		if cache != nil {
 			today, week, month, allTime := model.ComputeSummaries(cache)
 			days := model.AggregateDaily(cache)
 			burn := model.ComputeBurnRate(days)
@@ -105,6 +124,23 @@ var summaryCmd = &cobra.Command{
 	},
 }
 
+// printQuota prints a request quota's cycle usage and pacing.
+func printQuota(q *provider.QuotaStatus) {
+	now := time.Now()
+	name := "Requests"
+	if q.Name != "" {
+		name = q.Name + " requests"
+	}
+	fmt.Printf("      %s: %s (%.0f%%), resets %s\n",
+		name, q.Usage(), q.Percent(), q.ResetAt.Format("Jan 2"))
+	fmt.Printf("      Pace: %.1f/day so far, %.1f/day left to last the cycle",
+		q.DailyAverage(now), q.DailyBudget(now))
+	if at, ok := q.ExhaustedAt(now); ok {
+		fmt.Printf(", %s from %s", q.Exhausted, at.Format("Jan 2"))
+	}
+	fmt.Println()
+}
+
 func formatTokens(n int) string {
 	switch {
 	case n >= 1_000_000_000:
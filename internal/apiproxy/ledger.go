@@ -0,0 +1,88 @@
+package apiproxy
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Entry records one API response that passed through the proxy.
+type Entry struct {
+	Time       time.Time `json:"time"` // When the request was sent
+	API        string    `json:"api"`  // "anthropic" or "openai"
+	Path       string    `json:"path"`
+	Model      string    `json:"model,omitempty"`
+	Caller     string    `json:"caller,omitempty"` // Tag from the caller header
+	Status     int       `json:"status"`
+	Stream     bool      `json:"stream,omitempty"`
+	LatencyMS  int64     `json:"latency_ms"` // Until the response body was fully read
+	Input      int       `json:"input_tokens,omitempty"`
+	Output     int       `json:"output_tokens,omitempty"`
+	CacheRead  int       `json:"cache_read_tokens,omitempty"`
+	CacheWrite int       `json:"cache_write_tokens,omitempty"`
+}
+
+// Ledger appends entries to JSONL files in Dir, one file per day.
+type Ledger struct {
+	Dir string
+
+	mu sync.Mutex
+}
+
+// Append writes e to the file for the day it was sent.
+func (l *Ledger) Append(e Entry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := os.MkdirAll(l.Dir, 0o755); err != nil {
+		return err
+	}
+	path := filepath.Join(l.Dir, e.Time.Local().Format("2006-01-02")+".jsonl")
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ReadEntries reads every entry in the ledger at dir, oldest file first.
+func ReadEntries(dir string) ([]Entry, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no API ledger in %s", dir)
+	}
+	sort.Strings(files)
+
+	var out []Entry
+	for _, path := range files {
+		f, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var e Entry
+			if json.Unmarshal(scanner.Bytes(), &e) == nil {
+				out = append(out, e)
+			}
+		}
+		f.Close()
+	}
+	return out, nil
+}
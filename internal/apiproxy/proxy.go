@@ -0,0 +1,228 @@
+// Package apiproxy is a local reverse proxy for the Anthropic and OpenAI
+// APIs that records the usage of every response, streamed or not, in a
+// ledger. Apps opt in by pointing ANTHROPIC_BASE_URL or OPENAI_BASE_URL at
+// it.
+package apiproxy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// API names, as recorded in Entry.API.
+const (
+	Anthropic = "anthropic"
+	OpenAI    = "openai"
+)
+
+// DefaultCallerHeader is the request header apps tag their calls with. It
+// is not forwarded upstream.
+const DefaultCallerHeader = "X-Aitop-Caller"
+
+// maxBodySize bounds how much of a non-streamed response is kept for
+// reading its usage; the client always gets the whole body.
+const maxBodySize = 8 << 20
+
+// Proxy forwards /anthropic/... to the Anthropic API and /openai/... to the
+// OpenAI API. Unprefixed /v1/messages requests go to Anthropic and other
+// /v1/ requests to OpenAI, so either base URL can be the bare proxy address.
+type Proxy struct {
+	AnthropicURL *url.URL
+	OpenAIURL    *url.URL
+	CallerHeader string // Defaults to DefaultCallerHeader
+	Ledger       *Ledger
+	// IncludeStreamUsage adds stream_options.include_usage to streaming
+	// chat completions requests, which otherwise report no usage.
+	IncludeStreamUsage bool
+
+	Transport http.RoundTripper // Defaults to http.DefaultTransport
+	ErrorLog  *log.Logger       // Defaults to the log package's logger
+}
+
+type entryKey struct{}
+
+// Handler returns the proxy's HTTP handler.
+func (p *Proxy) Handler() http.Handler {
+	callerHeader := p.CallerHeader
+	if callerHeader == "" {
+		callerHeader = DefaultCallerHeader
+	}
+	rp := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			e := pr.In.Context().Value(entryKey{}).(*Entry)
+			target := p.OpenAIURL
+			if e.API == Anthropic {
+				target = p.AnthropicURL
+			}
+			pr.SetURL(target)
+			pr.Out.Header.Del(callerHeader)
+			// Let the transport negotiate compression so it can decompress
+			// the response for reading.
+			pr.Out.Header.Del("Accept-Encoding")
+		},
+		ModifyResponse: func(resp *http.Response) error {
+			e := resp.Request.Context().Value(entryKey{}).(*Entry)
+			e.Status = resp.StatusCode
+			mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+			e.Stream = mediaType == "text/event-stream"
+			resp.Body = &recorder{body: resp.Body, entry: e, proxy: p}
+			return nil
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			e := r.Context().Value(entryKey{}).(*Entry)
+			e.Status = http.StatusBadGateway
+			p.record(e)
+			p.logf("apiproxy: %s %s: %v", e.API, e.Path, err)
+			w.WriteHeader(http.StatusBadGateway)
+		},
+		Transport: p.Transport,
+		ErrorLog:  p.ErrorLog,
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		api, path := route(r.URL.Path)
+		if api == "" {
+			http.NotFound(w, r)
+			return
+		}
+		e := &Entry{
+			Time:   time.Now().UTC(),
+			API:    api,
+			Path:   path,
+			Caller: r.Header.Get(callerHeader),
+		}
+
+		if r.Body != nil && r.Method == http.MethodPost {
+			body, err := io.ReadAll(r.Body)
+			r.Body.Close()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if p.IncludeStreamUsage && api == OpenAI && strings.HasSuffix(path, "/chat/completions") {
+				body = includeStreamUsage(body)
+			}
+			var req requestInfo
+			if json.Unmarshal(body, &req) == nil {
+				e.Model = req.Model
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			r.ContentLength = int64(len(body))
+		}
+
+		r2 := r.WithContext(context.WithValue(r.Context(), entryKey{}, e))
+		r2.URL.Path = path
+		r2.URL.RawPath = ""
+		rp.ServeHTTP(w, r2)
+	})
+}
+
+// route returns the API a request path is for and the path to forward.
+func route(path string) (string, string) {
+	for _, api := range []string{Anthropic, OpenAI} {
+		prefix := "/" + api
+		if rest, ok := strings.CutPrefix(path, prefix); ok && (rest == "" || rest[0] == '/') {
+			return api, rest
+		}
+	}
+	switch {
+	case strings.HasPrefix(path, "/v1/messages"):
+		return Anthropic, path
+	case strings.HasPrefix(path, "/v1/"):
+		return OpenAI, path
+	}
+	return "", ""
+}
+
+func (p *Proxy) record(e *Entry) {
+	e.LatencyMS = time.Since(e.Time).Milliseconds()
+	if p.Ledger == nil {
+		return
+	}
+	if err := p.Ledger.Append(*e); err != nil {
+		p.logf("apiproxy: recording usage: %v", err)
+	}
+}
+
+func (p *Proxy) logf(format string, args ...any) {
+	if p.ErrorLog != nil {
+		p.ErrorLog.Printf(format, args...)
+		return
+	}
+	log.Printf(format, args...)
+}
+
+// recorder passes a response body through to the client, reading usage
+// from it on the way, and records the entry once the body is finished or
+// the client goes away.
+type recorder struct {
+	body  io.ReadCloser
+	entry *Entry
+	proxy *Proxy
+
+	buf  []byte // Unfinished SSE line, or the body so far
+	once sync.Once
+}
+
+func (r *recorder) Read(b []byte) (int, error) {
+	n, err := r.body.Read(b)
+	r.consume(b[:n])
+	if err == io.EOF {
+		r.finish()
+	}
+	return n, err
+}
+
+func (r *recorder) Close() error {
+	err := r.body.Close()
+	r.finish()
+	return err
+}
+
+func (r *recorder) consume(b []byte) {
+	if !r.entry.Stream {
+		if len(r.buf)+len(b) <= maxBodySize {
+			r.buf = append(r.buf, b...)
+		}
+		return
+	}
+	r.buf = append(r.buf, b...)
+	for {
+		i := bytes.IndexByte(r.buf, '\n')
+		if i < 0 {
+			break
+		}
+		r.line(r.buf[:i])
+		r.buf = r.buf[i+1:]
+	}
+}
+
+// line handles one line of an SSE stream; only data lines carry usage.
+func (r *recorder) line(line []byte) {
+	data, ok := bytes.CutPrefix(bytes.TrimRight(line, "\r"), []byte("data:"))
+	if ok {
+		r.entry.observe(data)
+	}
+}
+
+func (r *recorder) finish() {
+	r.once.Do(func() {
+		if r.entry.Stream {
+			r.line(r.buf)
+		} else {
+			r.entry.observe(r.buf)
+		}
+		r.buf = nil
+		r.proxy.record(r.entry)
+	})
+}
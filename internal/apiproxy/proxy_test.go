@@ -0,0 +1,195 @@
+package apiproxy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+const anthropicStream = `event: message_start
+data: {"type":"message_start","message":{"id":"msg_1","model":"claude-sonnet-4-5","usage":{"input_tokens":120,"cache_read_input_tokens":4000,"cache_creation_input_tokens":300,"output_tokens":1}}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hello"}}
+
+event: message_delta
+data: {"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":42}}
+
+event: message_stop
+data: {"type":"message_stop"}
+
+`
+
+// upstream stands in for both APIs, answering by path and checking what
+// the proxy forwards.
+func upstream(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(DefaultCallerHeader) != "" {
+			t.Errorf("caller header forwarded to %s", r.URL.Path)
+		}
+		body, _ := io.ReadAll(r.Body)
+		var req map[string]any
+		json.Unmarshal(body, &req)
+
+		switch r.URL.Path {
+		case "/v1/messages":
+			if req["stream"] == true {
+				w.Header().Set("Content-Type", "text/event-stream")
+				for _, chunk := range strings.SplitAfter(anthropicStream, "\n\n") {
+					io.WriteString(w, chunk)
+					w.(http.Flusher).Flush()
+				}
+				return
+			}
+			if req["model"] == "claude-overloaded" {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(529)
+				io.WriteString(w, `{"type":"error","error":{"type":"overloaded_error"}}`)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"id":"msg_2","type":"message","model":"claude-haiku-4-5","usage":{"input_tokens":50,"output_tokens":10}}`)
+		case "/v1/chat/completions":
+			opts, _ := req["stream_options"].(map[string]any)
+			if opts["include_usage"] != true {
+				t.Errorf("expected include_usage to be requested, got %s", body)
+			}
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, `data: {"id":"c1","model":"gpt-4.1-2025-04-14","choices":[{"delta":{"content":"Hi"}}],"usage":null}`+"\n\n")
+			fmt.Fprint(w, `data: {"id":"c1","model":"gpt-4.1-2025-04-14","choices":[],"usage":{"prompt_tokens":900,"completion_tokens":30,"prompt_tokens_details":{"cached_tokens":600}}}`+"\n\n")
+			fmt.Fprint(w, "data: [DONE]\n\n")
+		case "/v1/responses":
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"id":"r1","object":"response","model":"gpt-5","usage":{"input_tokens":200,"input_tokens_details":{"cached_tokens":50},"output_tokens":80}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestProxyRecordsUsage(t *testing.T) {
+	up := upstream(t)
+	defer up.Close()
+	upURL, _ := url.Parse(up.URL)
+
+	ledger := &Ledger{Dir: t.TempDir()}
+	p := &Proxy{AnthropicURL: upURL, OpenAIURL: upURL, Ledger: ledger, IncludeStreamUsage: true}
+	srv := httptest.NewServer(p.Handler())
+	defer srv.Close()
+
+	post := func(path, caller, body string) string {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if caller != "" {
+			req.Header.Set(DefaultCallerHeader, caller)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		return string(b)
+	}
+
+	if got := post("/anthropic/v1/messages", "nightly-eval", `{"model":"claude-sonnet-4-5","stream":true}`); got != anthropicStream {
+		t.Errorf("stream not passed through intact:\n%s", got)
+	}
+	post("/v1/messages", "", `{"model":"claude-haiku-4-5"}`)
+	post("/v1/messages", "", `{"model":"claude-overloaded"}`)
+	post("/openai/v1/chat/completions", "summarizer", `{"model":"gpt-4.1","stream":true}`)
+	post("/v1/responses", "summarizer", `{"model":"gpt-5"}`)
+	srv.Close() // Waits for the proxy to finish recording
+
+	entries, err := ReadEntries(ledger.Dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 5 {
+		t.Fatalf("expected 5 entries, got %d", len(entries))
+	}
+	want := []Entry{
+		{API: Anthropic, Path: "/v1/messages", Model: "claude-sonnet-4-5", Caller: "nightly-eval", Status: 200, Stream: true,
+			Input: 120, Output: 42, CacheRead: 4000, CacheWrite: 300},
+		{API: Anthropic, Path: "/v1/messages", Model: "claude-haiku-4-5", Status: 200, Input: 50, Output: 10},
+		{API: Anthropic, Path: "/v1/messages", Model: "claude-overloaded", Status: 529},
+		{API: OpenAI, Path: "/v1/chat/completions", Model: "gpt-4.1-2025-04-14", Caller: "summarizer", Status: 200, Stream: true,
+			Input: 300, Output: 30, CacheRead: 600},
+		{API: OpenAI, Path: "/v1/responses", Model: "gpt-5", Caller: "summarizer", Status: 200, Input: 150, Output: 80, CacheRead: 50},
+	}
+	for i, e := range entries {
+		if e.Time.IsZero() || e.LatencyMS < 0 {
+			t.Errorf("entry %d: missing time or latency %+v", i, e)
+		}
+		e.Time, e.LatencyMS = want[i].Time, 0
+		if e != want[i] {
+			t.Errorf("entry %d:\n got %+v\nwant %+v", i, e, want[i])
+		}
+	}
+}
+
+func TestProxyForwardsBodyAsSent(t *testing.T) {
+	var got string
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		got = string(b)
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer up.Close()
+	upURL, _ := url.Parse(up.URL)
+
+	// Without IncludeStreamUsage, streaming requests aren't rewritten.
+	p := &Proxy{AnthropicURL: upURL, OpenAIURL: upURL, Ledger: &Ledger{Dir: t.TempDir()}}
+	srv := httptest.NewServer(p.Handler())
+	defer srv.Close()
+
+	body := `{"model":"gpt-4.1","stream":true}`
+	resp, err := http.Post(srv.URL+"/openai/v1/chat/completions", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if got != body {
+		t.Errorf("expected the body forwarded as sent, got %s", got)
+	}
+}
+
+func TestProxyUnreachableUpstream(t *testing.T) {
+	ledger := &Ledger{Dir: t.TempDir()}
+	dead, _ := url.Parse("http://127.0.0.1:1")
+	p := &Proxy{AnthropicURL: dead, OpenAIURL: dead, Ledger: ledger, ErrorLog: discardLog}
+	srv := httptest.NewServer(p.Handler())
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/v1/messages", "application/json", strings.NewReader(`{"model":"claude-sonnet-4-5"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway {
+		t.Errorf("expected 502, got %d", resp.StatusCode)
+	}
+	entries, _ := ReadEntries(ledger.Dir)
+	if len(entries) != 1 || entries[0].Status != http.StatusBadGateway || entries[0].Model != "claude-sonnet-4-5" {
+		t.Errorf("expected a failed request in the ledger, got %+v", entries)
+	}
+
+	resp, err = http.Get(srv.URL + "/elsewhere")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown path, got %d", resp.StatusCode)
+	}
+}
+
+var discardLog = log.New(io.Discard, "", 0)
@@ -0,0 +1,89 @@
+package apiproxy
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// usageBlock holds the usage fields of both APIs. Anthropic reports input
+// tokens excluding cache reads; OpenAI reports prompt (or input) tokens
+// including them, with the cached share in the details.
+type usageBlock struct {
+	InputTokens      int `json:"input_tokens"`
+	OutputTokens     int `json:"output_tokens"`
+	CacheRead        int `json:"cache_read_input_tokens"`
+	CacheWrite       int `json:"cache_creation_input_tokens"`
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	PromptDetails    struct {
+		CachedTokens int `json:"cached_tokens"`
+	} `json:"prompt_tokens_details"`
+	InputDetails struct {
+		CachedTokens int `json:"cached_tokens"`
+	} `json:"input_tokens_details"`
+}
+
+// payload is a response body or one streamed event. Usage sits at the top
+// level, under message (Anthropic's message_start) or under response
+// (OpenAI's response.completed).
+type payload struct {
+	Model    string      `json:"model"`
+	Usage    *usageBlock `json:"usage"`
+	Message  *payload    `json:"message"`
+	Response *payload    `json:"response"`
+}
+
+// observe folds the model and usage in a JSON body or SSE data line into e.
+// Streams repeat usage as running totals (Anthropic's message_delta carries
+// the output so far), so each count keeps its largest value.
+func (e *Entry) observe(data []byte) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '{' {
+		return
+	}
+	var p payload
+	if json.Unmarshal(data, &p) != nil {
+		return
+	}
+	for _, q := range []*payload{&p, p.Message, p.Response} {
+		if q == nil {
+			continue
+		}
+		if q.Model != "" {
+			e.Model = q.Model
+		}
+		if u := q.Usage; u != nil {
+			cached := u.PromptDetails.CachedTokens + u.InputDetails.CachedTokens
+			e.Input = max(e.Input, u.InputTokens+u.PromptTokens-cached)
+			e.Output = max(e.Output, u.OutputTokens+u.CompletionTokens)
+			e.CacheRead = max(e.CacheRead, u.CacheRead+cached)
+			e.CacheWrite = max(e.CacheWrite, u.CacheWrite)
+		}
+	}
+}
+
+// requestInfo is what the proxy reads from a request body: the model asked
+// for, in case the response doesn't name it.
+type requestInfo struct {
+	Model string `json:"model"`
+}
+
+// includeStreamUsage asks for the final usage chunk that OpenAI's chat
+// completions API only streams when stream_options.include_usage is set.
+// The body is returned unchanged when it isn't a streaming request or
+// already sets stream_options.
+func includeStreamUsage(body []byte) []byte {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(body, &fields) != nil {
+		return body
+	}
+	if _, ok := fields["stream_options"]; ok || string(fields["stream"]) != "true" {
+		return body
+	}
+	fields["stream_options"] = json.RawMessage(`{"include_usage":true}`)
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return body
+	}
+	return b
+}
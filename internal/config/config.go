@@ -3,22 +3,119 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/pelletier/go-toml/v2"
 )
 
-// PlanConfig holds subscription plan details.
+// PlanConfig holds subscription plan details. Request-based plans (such as
+// Cursor's) set Requests to the number of requests included per cycle.
 type PlanConfig struct {
 	Provider    string  `toml:"provider"`
 	Name        string  `toml:"name"`
 	MonthlyCost float64 `toml:"monthly_cost"`
+
+	Requests      int      `toml:"requests"`        // Included requests per billing cycle
+	CycleStartDay int      `toml:"cycle_start_day"` // Day of month the cycle resets (default 1)
+	RequestModels []string `toml:"request_models"`  // Model prefixes that count; empty counts all
+}
+
+// GeminiForkConfig declares a Gemini CLI fork that keeps Gemini's
+// tmp/<hash>/chats/session-*.json layout under its own config dir.
+type GeminiForkConfig struct {
+	Name         string `toml:"name"`
+	Icon         string `toml:"icon"`
+	Color        string `toml:"color"`
+	ConfigDir    string `toml:"config_dir"`
+	DefaultModel string `toml:"default_model"`
+}
+
+// CustomProviderConfig declares a provider read from JSON or JSONL usage
+// logs, with JSON paths (such as message.usage.input_tokens) locating each
+// field of a record.
+type CustomProviderConfig struct {
+	Name         string             `toml:"name"`
+	Icon         string             `toml:"icon"`
+	Color        string             `toml:"color"`
+	Files        []string           `toml:"files"`   // Glob patterns; ** matches nested directories
+	Format       string             `toml:"format"`  // "jsonl" (default) or "json"
+	Records      string             `toml:"records"` // Path to the record array in a JSON file
+	DefaultModel string             `toml:"default_model"`
+	Fields       CustomFieldsConfig `toml:"fields"`
+	Filters      []string           `toml:"filters"` // e.g. `type == "assistant"`
+}
+
+// CustomFieldsConfig holds the JSON paths of a custom provider's fields.
+type CustomFieldsConfig struct {
+	Timestamp  string `toml:"timestamp"`
+	Session    string `toml:"session"`
+	Project    string `toml:"project"`
+	Model      string `toml:"model"`
+	Input      string `toml:"input_tokens"`
+	Output     string `toml:"output_tokens"`
+	CacheRead  string `toml:"cache_read_tokens"`
+	CacheWrite string `toml:"cache_write_tokens"`
+	Cost       string `toml:"cost"`
+}
+
+// PluginConfig registers an external provider command, such as
+// aitop-provider-foo, that prints usage as JSON or NDJSON.
+type PluginConfig struct {
+	Name      string   `toml:"name"`
+	Icon      string   `toml:"icon"`
+	Color     string   `toml:"color"`
+	Command   string   `toml:"command"`    // Executable name (looked up in $PATH) or path
+	Args      []string `toml:"args"`       // Passed before --since
+	Timeout   string   `toml:"timeout"`    // e.g. "10s"; default 30s
+	SinceDays int      `toml:"since_days"` // History to ask for; 0 asks for all of it
+}
+
+// ReconcileConfig scopes the comparison of imported vendor invoices with
+// estimates.
+type ReconcileConfig struct {
+	Providers []string `toml:"providers"` // Providers whose estimates are billed by the vendors; empty counts all
+}
+
+// PricingConfig overrides or adds a model's prices, in dollars per million
+// tokens. It is keyed by model name or prefix under [pricing].
+type PricingConfig struct {
+	Input      float64 `toml:"input"`
+	Output     float64 `toml:"output"`
+	CacheRead  float64 `toml:"cache_read"`
+	CacheWrite float64 `toml:"cache_write"`
 }
 
 // Config holds application configuration.
 type Config struct {
-	StatsCachePath string     `toml:"stats_cache_path"`
-	ProjectsDir    string     `toml:"projects_dir"`
-	Plan           PlanConfig `toml:"plan"`
+	StatsCachePath string             `toml:"stats_cache_path"`
+	DataDir        string             `toml:"data_dir"`
+	ProjectsDir    string             `toml:"projects_dir"`
+	Plan           PlanConfig         `toml:"plan"`
+	Plans          []PlanConfig       `toml:"plans"`
+	Paths          PathsConfig        `toml:"paths"`
+	WorkspaceRoots []string           `toml:"workspace_roots"`
+	GeminiForks    []GeminiForkConfig `toml:"gemini_forks"`
+
+	CustomProviders []CustomProviderConfig `toml:"custom_providers"`
+	Plugins         []PluginConfig         `toml:"plugins"`
+
+	Reconcile ReconcileConfig `toml:"reconcile"`
+
+	Pricing map[string]PricingConfig `toml:"pricing"`
+}
+
+// PlanFor returns the plan configured for provider (case-insensitive),
+// checking [plan] before the [[plans]] list.
+func (c Config) PlanFor(provider string) (PlanConfig, bool) {
+	if strings.EqualFold(c.Plan.Provider, provider) {
+		return c.Plan, true
+	}
+	for _, p := range c.Plans {
+		if strings.EqualFold(p.Provider, provider) {
+			return p, true
+		}
+	}
+	return PlanConfig{}, false
 }
 
 // DefaultConfigPath returns the path to the config file.
@@ -30,6 +127,65 @@ func DefaultConfigPath() string {
 	return filepath.Join(home, ".config", "aitop", "config.toml")
 }
 
+// DefaultCacheDir returns the directory for aitop's regenerable caches.
+func DefaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "aitop")
+}
+
+// DefaultDataDir returns the directory for data aitop records itself, such
+// as received telemetry: $XDG_DATA_HOME/aitop, else ~/.local/share/aitop.
+func DefaultDataDir() string {
+	if dirs := resolveDataDirs(nil, "aitop"); len(dirs) > 0 {
+		return dirs[0]
+	}
+	return ""
+}
+
+// StoreDir returns aitop's data directory: data_dir, else DefaultDataDir.
+func (c Config) StoreDir() string {
+	if c.DataDir != "" {
+		return c.DataDir
+	}
+	return DefaultDataDir()
+}
+
+// TelemetryDir returns where `aitop collect` stores received telemetry.
+func (c Config) TelemetryDir() string {
+	if dir := c.StoreDir(); dir != "" {
+		return filepath.Join(dir, "telemetry")
+	}
+	return ""
+}
+
+// APILedgerDir returns where `aitop proxy` records API usage.
+func (c Config) APILedgerDir() string {
+	if dir := c.StoreDir(); dir != "" {
+		return filepath.Join(dir, "api")
+	}
+	return ""
+}
+
+// InvoiceDir returns where `aitop import usage` keeps imported invoices.
+func (c Config) InvoiceDir() string {
+	if dir := c.StoreDir(); dir != "" {
+		return filepath.Join(dir, "invoiced")
+	}
+	return ""
+}
+
+// LedgerPath returns the SQLite ledger that keeps usage history after tools
+// clean up their logs.
+func (c Config) LedgerPath() string {
+	if dir := c.StoreDir(); dir != "" {
+		return filepath.Join(dir, "ledger.db")
+	}
+	return ""
+}
+
 // Load reads the config file, returning defaults if it doesn't exist.
 func Load() Config {
 	cfg := Config{
@@ -47,5 +203,11 @@ func Load() Config {
 	}
 
 	_ = toml.Unmarshal(data, &cfg)
+	cfg.StatsCachePath = ExpandHome(cfg.StatsCachePath)
+	cfg.ProjectsDir = ExpandHome(cfg.ProjectsDir)
+	cfg.DataDir = ExpandHome(cfg.DataDir)
+	for i, root := range cfg.WorkspaceRoots {
+		cfg.WorkspaceRoots[i] = ExpandHome(root)
+	}
 	return cfg
 }
@@ -0,0 +1,207 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// PathsConfig holds per-provider data directory overrides. Each entry is a
+// tool home directory (e.g. ~/.claude); listing several merges their data.
+type PathsConfig struct {
+	Claude []string `toml:"claude"`
+	Codex  []string `toml:"codex"`
+	Gemini []string `toml:"gemini"`
+	Qwen   []string `toml:"qwen"`
+
+	// OpenCode lists opencode data dirs (the ones containing storage/).
+	OpenCode []string `toml:"opencode"`
+	// Crush lists Crush's global data dirs; per-project .crush/crush.db
+	// files are found under workspace_roots.
+	Crush []string `toml:"crush"`
+	// LLM lists llm CLI user dirs (the ones containing logs.db).
+	LLM []string `toml:"llm"`
+	// Goose lists Goose data dirs (the ones containing sessions/).
+	Goose []string `toml:"goose"`
+	// AmazonQ lists Amazon Q Developer CLI data dirs (the ones containing
+	// data.sqlite3).
+	AmazonQ []string `toml:"amazon_q"`
+	// Zed lists Zed data dirs (the ones containing threads/threads.db).
+	Zed []string `toml:"zed"`
+
+	// GeminiTelemetry lists Gemini CLI telemetry outfiles to ingest in
+	// addition to the telemetry.outfile set in settings.json.
+	GeminiTelemetry []string `toml:"gemini_telemetry"`
+
+	// Aider lists .aider.chat.history.md files (or repos containing one)
+	// outside workspace_roots; AiderAnalytics lists --analytics-log files.
+	Aider          []string `toml:"aider"`
+	AiderAnalytics []string `toml:"aider_analytics"`
+
+	// Editors lists VS Code-style user data directories (the ones containing
+	// globalStorage) searched for Cline, Roo Code and Kilo Code tasks.
+	Editors []string `toml:"editors"`
+}
+
+// ClaudeDirs returns the Claude Code config directories to read, in order of
+// precedence: [paths].claude, $CLAUDE_CONFIG_DIR, then ~/.claude.
+func (c Config) ClaudeDirs() []string {
+	return resolveDirs(c.Paths.Claude, "CLAUDE_CONFIG_DIR", ".claude")
+}
+
+// StatsCachePaths returns the Claude stats caches to read: stats_cache_path
+// when set, and otherwise each Claude dir's stats-cache.json.
+func (c Config) StatsCachePaths() []string {
+	if c.StatsCachePath != "" {
+		return []string{c.StatsCachePath}
+	}
+	var paths []string
+	for _, dir := range c.ClaudeDirs() {
+		paths = append(paths, filepath.Join(dir, "stats-cache.json"))
+	}
+	return paths
+}
+
+// CodexDirs returns the Codex home directories to read, in order of
+// precedence: [paths].codex, $CODEX_HOME, then ~/.codex.
+func (c Config) CodexDirs() []string {
+	return resolveDirs(c.Paths.Codex, "CODEX_HOME", ".codex")
+}
+
+// GeminiDirs returns the Gemini CLI directories to read: [paths].gemini, then ~/.gemini.
+func (c Config) GeminiDirs() []string {
+	return resolveDirs(c.Paths.Gemini, "", ".gemini")
+}
+
+// QwenDirs returns the Qwen Code directories to read: [paths].qwen, then ~/.qwen.
+func (c Config) QwenDirs() []string {
+	return resolveDirs(c.Paths.Qwen, "", ".qwen")
+}
+
+// OpenCodeDirs returns the opencode data directories to read: [paths].opencode,
+// then $XDG_DATA_HOME/opencode, then ~/.local/share/opencode.
+func (c Config) OpenCodeDirs() []string {
+	return resolveDataDirs(c.Paths.OpenCode, "opencode")
+}
+
+// CrushDirs returns Crush's global data directories: [paths].crush, then
+// $XDG_DATA_HOME/crush, then ~/.local/share/crush.
+func (c Config) CrushDirs() []string {
+	return resolveDataDirs(c.Paths.Crush, "crush")
+}
+
+// GooseDirs returns the Goose data directories to read: [paths].goose, else
+// goose under $XDG_DATA_HOME or ~/.local/share.
+func (c Config) GooseDirs() []string {
+	return resolveDataDirs(c.Paths.Goose, "goose")
+}
+
+// AmazonQDirs returns the Amazon Q Developer CLI data directories to read:
+// [paths].amazon_q, else amazon-q under the platform's local data dir.
+func (c Config) AmazonQDirs() []string {
+	return resolveLocalDataDirs(c.Paths.AmazonQ, "amazon-q", "amazon-q")
+}
+
+// ZedDirs returns the Zed data directories to read: [paths].zed, else Zed's
+// local data dir.
+func (c Config) ZedDirs() []string {
+	return resolveLocalDataDirs(c.Paths.Zed, "zed", "Zed")
+}
+
+// LLMDirs returns the llm CLI user directories to read: [paths].llm, then
+// $LLM_USER_PATH, then io.datasette.llm under the OS config dir.
+func (c Config) LLMDirs() []string {
+	if len(c.Paths.LLM) > 0 {
+		return UniquePaths(c.Paths.LLM)
+	}
+	if v := os.Getenv("LLM_USER_PATH"); v != "" {
+		return UniquePaths(filepath.SplitList(v))
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil
+	}
+	return []string{filepath.Join(dir, "io.datasette.llm")}
+}
+
+// resolveLocalDataDirs is resolveDataDirs for tools that follow platform
+// conventions: on macOS they keep data under ~/Library/Application Support
+// (as macName) rather than ~/.local/share.
+func resolveLocalDataDirs(configured []string, name, macName string) []string {
+	if len(configured) == 0 && runtime.GOOS == "darwin" {
+		if dir, err := os.UserConfigDir(); err == nil {
+			return []string{filepath.Join(dir, macName)}
+		}
+	}
+	return resolveDataDirs(configured, name)
+}
+
+// resolveDataDirs picks the configured directories, falling back to the
+// tool's directory under $XDG_DATA_HOME and then ~/.local/share.
+func resolveDataDirs(configured []string, name string) []string {
+	if len(configured) == 0 {
+		if xdg := os.Getenv("XDG_DATA_HOME"); xdg != "" {
+			return UniquePaths([]string{filepath.Join(xdg, name)})
+		}
+	}
+	return resolveDirs(configured, "", filepath.Join(".local", "share", name))
+}
+
+// resolveDirs picks the configured directories, falling back to the
+// environment variable (which may hold a path list) and then the default
+// directory under $HOME. The result is expanded and deduplicated.
+func resolveDirs(configured []string, envVar, homeDefault string) []string {
+	dirs := configured
+	if len(dirs) == 0 && envVar != "" {
+		if v := os.Getenv(envVar); v != "" {
+			dirs = filepath.SplitList(v)
+		}
+	}
+	if len(dirs) == 0 {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil
+		}
+		dirs = []string{filepath.Join(home, homeDefault)}
+	}
+	return UniquePaths(dirs)
+}
+
+// ExpandHome replaces a leading "~" with the user's home directory.
+func ExpandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
+
+// UniquePaths expands and cleans paths, dropping duplicates that refer to the
+// same location (including via symlinks) so data is never read twice.
+func UniquePaths(paths []string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, p := range paths {
+		if p == "" {
+			continue
+		}
+		p = filepath.Clean(ExpandHome(p))
+		if abs, err := filepath.Abs(p); err == nil {
+			p = abs
+		}
+		key := p
+		if real, err := filepath.EvalSymlinks(p); err == nil {
+			key = real
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, p)
+	}
+	return out
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCodexDirsPrecedence(t *testing.T) {
+	envDir := t.TempDir()
+	t.Setenv("CODEX_HOME", envDir)
+
+	if got := (Config{}).CodexDirs(); len(got) != 1 || got[0] != envDir {
+		t.Errorf("CodexDirs with CODEX_HOME = %v, want [%s]", got, envDir)
+	}
+
+	cfgDir := t.TempDir()
+	cfg := Config{Paths: PathsConfig{Codex: []string{cfgDir}}}
+	if got := cfg.CodexDirs(); len(got) != 1 || got[0] != cfgDir {
+		t.Errorf("CodexDirs with [paths].codex = %v, want [%s]", got, cfgDir)
+	}
+}
+
+func TestUniquePaths(t *testing.T) {
+	dir := t.TempDir()
+	link := filepath.Join(t.TempDir(), "link")
+	if err := os.Symlink(dir, link); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+
+	got := UniquePaths([]string{dir, dir + "/", link, ""})
+	if len(got) != 1 {
+		t.Errorf("UniquePaths = %v, want a single entry", got)
+	}
+}
+
+func TestOpenCodeDirsXDG(t *testing.T) {
+	xdg := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", xdg)
+
+	want := filepath.Join(xdg, "opencode")
+	if got := (Config{}).OpenCodeDirs(); len(got) != 1 || got[0] != want {
+		t.Errorf("OpenCodeDirs with XDG_DATA_HOME = %v, want [%s]", got, want)
+	}
+}
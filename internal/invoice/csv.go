@@ -0,0 +1,262 @@
+package invoice
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/isaacaudet/aitop/internal/model"
+)
+
+// exportFormat locates a vendor export's fields by header name. Each field
+// lists the header names vendors have used for it, first match wins.
+type exportFormat struct {
+	date, model, cost                []string
+	input, output, cached, cacheRead []string
+	cacheWritePrefix                 string
+	inputIncludesCached              bool
+	modelFrom                        func(string) string // Turns the model column into a model name
+	keep                             func(row func(...string) string) bool
+}
+
+var formats = map[string]exportFormat{
+	// Anthropic Console cost and usage exports.
+	Anthropic: {
+		date:             []string{"usage_date_utc", "usage_date", "date"},
+		model:            []string{"model_version", "model"},
+		cost:             []string{"cost_usd", "cost", "amount"},
+		input:            []string{"uncached_input_tokens", "input_tokens"},
+		output:           []string{"output_tokens"},
+		cacheRead:        []string{"cache_read_input_tokens"},
+		cacheWritePrefix: "cache_creation",
+	},
+	// OpenAI usage dashboard cost exports (line items such as
+	// "gpt-4.1-2025-04-14, input") and activity exports.
+	OpenAI: {
+		date:                []string{"start_time_iso", "start_time", "date"},
+		model:               []string{"model", "line_item"},
+		cost:                []string{"amount_value", "cost"},
+		input:               []string{"input_tokens", "n_context_tokens_total"},
+		output:              []string{"output_tokens", "n_generated_tokens_total"},
+		cached:              []string{"input_cached_tokens"},
+		inputIncludesCached: true,
+		modelFrom: func(item string) string {
+			name, _, _ := strings.Cut(item, ",")
+			return name
+		},
+	},
+	// Google Cloud billing exports, keeping Vertex AI and Gemini API rows.
+	GCP: {
+		date:      []string{"usage start date", "usage_start_time", "date"},
+		model:     []string{"sku description", "sku"},
+		cost:      []string{"unrounded cost ($)", "cost ($)", "cost"},
+		modelFrom: gcpSKUModel,
+		keep: func(row func(...string) string) bool {
+			service := strings.ToLower(row("service description", "service"))
+			return service == "" || strings.Contains(service, "vertex ai") ||
+				strings.Contains(service, "gemini") || strings.Contains(service, "generative language")
+		},
+	},
+}
+
+// DetectVendor returns the vendor whose export has these column headers.
+func DetectVendor(header []string) string {
+	cols := make(map[string]bool, len(header))
+	for _, h := range header {
+		cols[normalizeHeader(h)] = true
+	}
+	switch {
+	case cols["usage_date_utc"]:
+		return Anthropic
+	case cols["line_item"] || cols["num_model_requests"]:
+		return OpenAI
+	case cols["sku description"]:
+		return GCP
+	}
+	return ""
+}
+
+// ParseCSV reads a vendor's usage or cost export into per-day, per-model
+// lines. If vendor is empty it is detected from the header. Rows without a
+// cost are priced from their token counts.
+func ParseCSV(r io.Reader, vendor string) (string, []Line, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return "", nil, fmt.Errorf("reading header: %w", err)
+	}
+	if vendor == "" {
+		vendor = DetectVendor(header)
+		if vendor == "" {
+			return "", nil, errors.New("unrecognized export; name its vendor with --vendor")
+		}
+	}
+	f, ok := formats[vendor]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown vendor %q (want anthropic, openai or gcp)", vendor)
+	}
+
+	index := make(map[string]int, len(header))
+	for i, h := range header {
+		index[normalizeHeader(h)] = i
+	}
+	if !hasAny(index, f.date) || !hasAny(index, f.model) {
+		return "", nil, fmt.Errorf("%s export needs a date and a model column", vendor)
+	}
+	var cacheWrite []int
+	if f.cacheWritePrefix != "" {
+		for h, i := range index {
+			if strings.HasPrefix(h, f.cacheWritePrefix) {
+				cacheWrite = append(cacheWrite, i)
+			}
+		}
+	}
+
+	type key struct{ date, model string }
+	sums := make(map[key]*Line)
+	for n := 2; ; n++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		row := func(names ...string) string {
+			for _, name := range names {
+				if i, ok := index[name]; ok && i < len(rec) {
+					return strings.TrimSpace(rec[i])
+				}
+			}
+			return ""
+		}
+		if f.keep != nil && !f.keep(row) {
+			continue
+		}
+		date, err := parseDate(row(f.date...))
+		if err != nil {
+			return "", nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		name := row(f.model...)
+		if f.modelFrom != nil {
+			name = f.modelFrom(name)
+		}
+		if name == "" {
+			continue
+		}
+
+		usage := model.TokenUsage{
+			InputTokens:  parseInt(row(f.input...)),
+			OutputTokens: parseInt(row(f.output...)),
+			CacheRead:    parseInt(row(f.cacheRead...)) + parseInt(row(f.cached...)),
+		}
+		if f.inputIncludesCached {
+			usage.InputTokens -= parseInt(row(f.cached...))
+		}
+		for _, i := range cacheWrite {
+			if i < len(rec) {
+				usage.CacheWrite += parseInt(rec[i])
+			}
+		}
+		cost, err := parseAmount(row(f.cost...))
+		if err != nil {
+			return "", nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		if !hasAny(index, f.cost) {
+			cost = model.CalculateCost(name, usage)
+		}
+
+		k := key{date, ModelKey(name)}
+		l, ok := sums[k]
+		if !ok {
+			l = &Line{Date: k.date, Vendor: vendor, Model: k.model}
+			sums[k] = l
+		}
+		l.Cost += cost
+		l.InputTokens += usage.InputTokens + usage.CacheRead + usage.CacheWrite
+		l.OutputTokens += usage.OutputTokens
+	}
+
+	lines := make([]Line, 0, len(sums))
+	for _, l := range sums {
+		lines = append(lines, *l)
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		if lines[i].Date != lines[j].Date {
+			return lines[i].Date < lines[j].Date
+		}
+		return lines[i].Model < lines[j].Model
+	})
+	return vendor, lines, nil
+}
+
+// gcpSKUModel derives a model name from a billing SKU description, e.g.
+// "Gemini 2.5 Pro Input Text Tokens" is gemini-2.5-pro.
+func gcpSKUModel(sku string) string {
+	var words []string
+	for _, w := range strings.Fields(strings.ToLower(sku)) {
+		switch w {
+		case "input", "output", "text", "image", "audio", "video", "tokens", "characters",
+			"cached", "caching", "batch", "predictions", "long", "short", "context", "for", "-":
+			return strings.Join(words, "-")
+		}
+		words = append(words, w)
+	}
+	return strings.Join(words, "-")
+}
+
+func normalizeHeader(h string) string {
+	return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+}
+
+func hasAny(index map[string]int, names []string) bool {
+	for _, name := range names {
+		if _, ok := index[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// parseDate returns the UTC day of a date, timestamp or Unix time.
+func parseDate(s string) (string, error) {
+	if s == "" {
+		return "", errors.New("missing date")
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil && len(s) >= 9 {
+		return time.Unix(n, 0).UTC().Format("2006-01-02"), nil
+	}
+	for _, layout := range []string{
+		time.RFC3339Nano, "2006-01-02 15:04:05 MST", "2006-01-02 15:04:05",
+		"2006-01-02T15:04:05", "2006-01-02", "1/2/2006",
+	} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC().Format("2006-01-02"), nil
+		}
+	}
+	return "", fmt.Errorf("unrecognized date %q", s)
+}
+
+// parseAmount parses a currency amount such as "$1,234.50".
+func parseAmount(s string) (float64, error) {
+	s = strings.NewReplacer("$", "", ",", "", " ", "").Replace(s)
+	if s == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unrecognized amount %q", s)
+	}
+	return v, nil
+}
+
+func parseInt(s string) int {
+	v, _ := parseAmount(s)
+	return int(v)
+}
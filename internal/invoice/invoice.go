@@ -0,0 +1,172 @@
+// Package invoice imports the usage and cost exports of vendor consoles as
+// an "invoiced" dataset and reconciles it with the spend aitop estimates
+// from local logs.
+package invoice
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/isaacaudet/aitop/internal/model"
+)
+
+// Vendors.
+const (
+	Anthropic = "anthropic"
+	OpenAI    = "openai"
+	GCP       = "gcp"
+)
+
+// Line is one day's invoiced cost for a model, summed over the rows of an
+// export that share them.
+type Line struct {
+	Date         string  `json:"date"` // YYYY-MM-DD, in UTC as vendors bill
+	Vendor       string  `json:"vendor"`
+	Model        string  `json:"model"` // Normalized with ModelKey
+	Cost         float64 `json:"cost"`
+	InputTokens  int     `json:"input_tokens,omitempty"`
+	OutputTokens int     `json:"output_tokens,omitempty"`
+}
+
+// openAIDateSuffixRe strips OpenAI snapshot dates like -2025-04-14.
+var openAIDateSuffixRe = regexp.MustCompile(`-\d{4}-\d{2}-\d{2}$`)
+
+// ModelKey normalizes a model name so invoiced and estimated names match:
+// provider prefixes, version tags (gemini-2.5-pro@001) and snapshot dates
+// are dropped, as is Claude's "claude-" prefix.
+func ModelKey(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	if i := strings.Index(name, "@"); i >= 0 {
+		name = name[:i]
+	}
+	name = openAIDateSuffixRe.ReplaceAllString(name, "")
+	return model.NormalizeModelName(name)
+}
+
+// VendorOf returns the vendor that bills for a model, or "" if unknown.
+func VendorOf(modelName string) string {
+	name := strings.ToLower(modelName)
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	switch {
+	case strings.HasPrefix(name, "claude"):
+		return Anthropic
+	case strings.HasPrefix(name, "gemini"):
+		return GCP
+	}
+	for _, prefix := range []string{"gpt-", "chatgpt", "o1", "o3", "o4", "codex", "text-embedding", "dall-e", "whisper", "tts-"} {
+		if strings.HasPrefix(name, prefix) {
+			return OpenAI
+		}
+	}
+	return ""
+}
+
+// Store keeps the invoiced dataset in Dir, one JSONL file per vendor.
+type Store struct {
+	Dir string
+}
+
+// Import adds lines to the dataset. An export replaces what was imported
+// before for the same vendor and days, so re-importing a month, or a later
+// export that overlaps it, doesn't double count.
+func (s *Store) Import(vendor string, lines []Line) error {
+	if len(lines) == 0 {
+		return nil
+	}
+	path := filepath.Join(s.Dir, vendor+".jsonl")
+	existing, err := readLines(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	days := make(map[string]bool)
+	for _, l := range lines {
+		days[l.Date] = true
+	}
+	merged := lines
+	for _, l := range existing {
+		if !days[l.Date] {
+			merged = append(merged, l)
+		}
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		if merged[i].Date != merged[j].Date {
+			return merged[i].Date < merged[j].Date
+		}
+		return merged[i].Model < merged[j].Model
+	})
+
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.Dir, vendor+".*.tmp")
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	for _, l := range merged {
+		if err := enc.Encode(l); err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Load returns every imported line, or none if nothing was imported.
+func (s *Store) Load() ([]Line, error) {
+	files, err := filepath.Glob(filepath.Join(s.Dir, "*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	var out []Line
+	for _, path := range files {
+		lines, err := readLines(path)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, lines...)
+	}
+	return out, nil
+}
+
+func readLines(path string) ([]Line, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var out []Line
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		var l Line
+		if err := json.Unmarshal(scanner.Bytes(), &l); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+		out = append(out, l)
+	}
+	return out, scanner.Err()
+}
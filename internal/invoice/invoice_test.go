@@ -0,0 +1,112 @@
+package invoice
+
+import (
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/isaacaudet/aitop/internal/provider"
+)
+
+func parseFixture(t *testing.T, name string) (string, []Line) {
+	t.Helper()
+	f, err := os.Open("../../testdata/invoices/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	vendor, lines, err := ParseCSV(f, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return vendor, lines
+}
+
+func approx(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+func TestParseCSV(t *testing.T) {
+	vendor, lines := parseFixture(t, "anthropic.csv")
+	if vendor != Anthropic || len(lines) != 3 {
+		t.Fatalf("expected 3 anthropic lines, got %s %+v", vendor, lines)
+	}
+	if l := lines[1]; l.Date != "2026-03-03" || l.Model != "sonnet-4-5" || !approx(l.Cost, 4.5) {
+		t.Errorf("expected sonnet's rows summed per day, got %+v", l)
+	}
+
+	vendor, lines = parseFixture(t, "openai.csv")
+	if vendor != OpenAI || len(lines) != 2 {
+		t.Fatalf("expected 2 openai lines, got %s %+v", vendor, lines)
+	}
+	if l := lines[0]; l.Model != "gpt-4.1" || l.Date != "2026-03-03" || !approx(l.Cost, 1.2) {
+		t.Errorf("unexpected line item model %+v", l)
+	}
+
+	// Only Vertex AI rows are kept, priced at the unrounded cost.
+	vendor, lines = parseFixture(t, "gcp.csv")
+	if vendor != GCP || len(lines) != 1 {
+		t.Fatalf("expected 1 gcp line, got %s %+v", vendor, lines)
+	}
+	if l := lines[0]; l.Model != "gemini-2.5-pro" || !approx(l.Cost, 2.4013) {
+		t.Errorf("unexpected SKU model %+v", l)
+	}
+}
+
+func TestStoreImportReplacesDays(t *testing.T) {
+	s := &Store{Dir: t.TempDir()}
+	_, lines := parseFixture(t, "anthropic.csv")
+	if err := s.Import(Anthropic, lines); err != nil {
+		t.Fatal(err)
+	}
+	// A later export covering 2026-03-04 again replaces that day only.
+	if err := s.Import(Anthropic, []Line{{Date: "2026-03-04", Vendor: Anthropic, Model: "sonnet-4-5", Cost: 2.5}}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var total float64
+	for _, l := range got {
+		total += l.Cost
+	}
+	if len(got) != 3 || !approx(total, 4.5+0.05+2.5) {
+		t.Errorf("expected the re-imported day replaced, got %+v", got)
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	_, invoiced := parseFixture(t, "anthropic.csv")
+	day := func(d, h int) time.Time { return time.Date(2026, 3, d, h, 0, 0, 0, time.UTC) }
+	providers := []*provider.ProviderData{{
+		ProviderName: "API",
+		Sessions: []provider.SessionInfo{{
+			Turns: []provider.TurnUsage{
+				{Timestamp: day(3, 10), Model: "claude-sonnet-4-5", Cost: 4.4},
+				{Timestamp: day(3, 11), Model: "claude-haiku-4-5", Cost: 0.05},
+				{Timestamp: day(4, 9), Model: "claude-sonnet-4-5", Cost: 0.5},
+				{Timestamp: day(9, 9), Model: "claude-sonnet-4-5", Cost: 7}, // After the export
+				{Timestamp: day(3, 9), Model: "gpt-4.1", Cost: 3},           // No OpenAI import
+			},
+		}},
+	}, {
+		ProviderName: "Claude Code",
+		Sessions: []provider.SessionInfo{{
+			StartTime: day(3, 8), Model: "claude-opus-4-1", Cost: 50,
+		}},
+	}}
+
+	r := Reconcile(invoiced, Estimates(providers, []string{"api"}))
+	if r.From != "2026-03-03" || r.To != "2026-03-04" {
+		t.Errorf("unexpected period %s to %s", r.From, r.To)
+	}
+	if !approx(r.Invoiced, 6.55) || !approx(r.Estimated, 4.95) {
+		t.Errorf("unexpected totals %.2f invoiced, %.2f estimated", r.Invoiced, r.Estimated)
+	}
+	if len(r.Days) != 2 || r.Days[0].Significant() || !r.Days[1].Significant() {
+		t.Errorf("expected only 2026-03-04 flagged, got %+v", r.Days)
+	}
+	if g := r.Models[0]; g.Model != "sonnet-4-5" || !approx(g.Diff(), 1.6) {
+		t.Errorf("expected sonnet's gap first, got %+v", g)
+	}
+}
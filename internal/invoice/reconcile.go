@@ -0,0 +1,193 @@
+package invoice
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/isaacaudet/aitop/internal/provider"
+)
+
+// Gap compares invoiced and estimated spend for one day or one model.
+type Gap struct {
+	Date      string // Empty for per-model totals
+	Vendor    string
+	Model     string // Empty for per-day totals
+	Invoiced  float64
+	Estimated float64
+}
+
+// Diff is how much more was invoiced than estimated.
+func (g Gap) Diff() float64 { return g.Invoiced - g.Estimated }
+
+// FormatDiff renders the gap with its share of the estimate, e.g.
+// "+$1.60 (+24.4%)".
+func (g Gap) FormatDiff() string {
+	d := g.Diff()
+	sign := "+"
+	if d < 0 {
+		sign = "-"
+		d = -d
+	}
+	if g.Estimated == 0 {
+		return fmt.Sprintf("%s$%.2f", sign, d)
+	}
+	return fmt.Sprintf("%s$%.2f (%s%.1f%%)", sign, d, sign, d/g.Estimated*100)
+}
+
+// Significant reports whether the gap is worth a look: at least $1 and 10%
+// of the larger figure.
+func (g Gap) Significant() bool {
+	d := math.Abs(g.Diff())
+	return d >= 1 && d >= 0.1*math.Max(g.Invoiced, g.Estimated)
+}
+
+// Report is the reconciliation of invoiced against estimated spend, over
+// the days each vendor's imports cover.
+type Report struct {
+	Days      []Gap // Per day and vendor, in date order
+	Models    []Gap // Per vendor and model, largest gap first
+	Invoiced  float64
+	Estimated float64
+	From, To  string
+}
+
+// Estimates returns the spend aitop estimated per UTC day and model from
+// providers' per-response usage, falling back to whole sessions. If names
+// is non-empty, only those providers are counted.
+func Estimates(providers []*provider.ProviderData, names []string) []Line {
+	type key struct{ date, model string }
+	sums := make(map[key]*Line)
+	add := func(date, modelName string, cost float64) {
+		vendor := VendorOf(modelName)
+		if vendor == "" || cost == 0 {
+			return
+		}
+		k := key{date, ModelKey(modelName)}
+		l, ok := sums[k]
+		if !ok {
+			l = &Line{Date: date, Vendor: vendor, Model: k.model}
+			sums[k] = l
+		}
+		l.Cost += cost
+	}
+
+	for _, p := range providers {
+		if len(names) > 0 && !containsFold(names, p.ProviderName) {
+			continue
+		}
+		for _, s := range p.Sessions {
+			if len(s.Turns) == 0 {
+				add(s.StartTime.UTC().Format("2006-01-02"), s.Model, s.Cost)
+				continue
+			}
+			for _, t := range s.Turns {
+				ts := t.Timestamp
+				if ts.IsZero() {
+					ts = s.StartTime
+				}
+				add(ts.UTC().Format("2006-01-02"), t.Model, t.Cost)
+			}
+		}
+	}
+
+	out := make([]Line, 0, len(sums))
+	for _, l := range sums {
+		out = append(out, *l)
+	}
+	return out
+}
+
+// Reconcile compares invoiced lines with estimates. Estimates for vendors
+// with no imports, or for days outside a vendor's imports, are left out.
+func Reconcile(invoiced, estimated []Line) Report {
+	type span struct{ from, to string }
+	spans := make(map[string]*span)
+	for _, l := range invoiced {
+		s, ok := spans[l.Vendor]
+		if !ok {
+			spans[l.Vendor] = &span{l.Date, l.Date}
+			continue
+		}
+		s.from = min(s.from, l.Date)
+		s.to = max(s.to, l.Date)
+	}
+
+	var r Report
+	days := make(map[gapKey]*Gap)
+	models := make(map[gapKey]*Gap)
+	add := func(l Line, invoice bool) {
+		s, ok := spans[l.Vendor]
+		if !ok || l.Date < s.from || l.Date > s.to {
+			return
+		}
+		if r.From == "" || l.Date < r.From {
+			r.From = l.Date
+		}
+		r.To = max(r.To, l.Date)
+
+		for _, g := range []*Gap{
+			gapFor(days, gapKey{l.Date, l.Vendor, ""}),
+			gapFor(models, gapKey{"", l.Vendor, l.Model}),
+		} {
+			if invoice {
+				g.Invoiced += l.Cost
+			} else {
+				g.Estimated += l.Cost
+			}
+		}
+		if invoice {
+			r.Invoiced += l.Cost
+		} else {
+			r.Estimated += l.Cost
+		}
+	}
+	for _, l := range invoiced {
+		add(l, true)
+	}
+	for _, l := range estimated {
+		add(l, false)
+	}
+
+	for _, g := range days {
+		r.Days = append(r.Days, *g)
+	}
+	sort.Slice(r.Days, func(i, j int) bool {
+		if r.Days[i].Date != r.Days[j].Date {
+			return r.Days[i].Date < r.Days[j].Date
+		}
+		return r.Days[i].Vendor < r.Days[j].Vendor
+	})
+	for _, g := range models {
+		r.Models = append(r.Models, *g)
+	}
+	sort.Slice(r.Models, func(i, j int) bool {
+		di, dj := math.Abs(r.Models[i].Diff()), math.Abs(r.Models[j].Diff())
+		if di != dj {
+			return di > dj
+		}
+		return r.Models[i].Model < r.Models[j].Model
+	})
+	return r
+}
+
+type gapKey struct{ date, vendor, model string }
+
+func gapFor(m map[gapKey]*Gap, k gapKey) *Gap {
+	g, ok := m[k]
+	if !ok {
+		g = &Gap{Date: k.date, Vendor: k.vendor, Model: k.model}
+		m[k] = g
+	}
+	return g
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,536 @@
+// Package ledger keeps a permanent, append-only SQLite record of the usage
+// aitop has seen, so session history survives tools cleaning up their logs.
+//
+// Every load snapshots each provider's sessions, daily usage and per-model
+// totals. A new row is written only when one differs from its latest
+// snapshot without having lost usage, and rows are never updated or deleted. Reports merge the latest
+// snapshots back into live data.
+package ledger
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/isaacaudet/aitop/internal/provider"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS providers (
+	name  TEXT PRIMARY KEY,
+	icon  TEXT NOT NULL,
+	color TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS sessions (
+	provider      TEXT NOT NULL,
+	session_id    TEXT NOT NULL,
+	recorded_at   TEXT NOT NULL,
+	title         TEXT NOT NULL,
+	project       TEXT NOT NULL,
+	model         TEXT NOT NULL,
+	start_time    TEXT NOT NULL,
+	end_time      TEXT NOT NULL,
+	messages      INTEGER NOT NULL,
+	user_messages INTEGER NOT NULL,
+	prompts       INTEGER NOT NULL,
+	tokens        INTEGER NOT NULL,
+	cost          REAL NOT NULL,
+	requests      INTEGER NOT NULL,
+	errors        INTEGER NOT NULL,
+	latency_ms    INTEGER NOT NULL,
+	estimated     INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS sessions_key ON sessions (provider, session_id, recorded_at);
+CREATE TABLE IF NOT EXISTS daily (
+	provider    TEXT NOT NULL,
+	date        TEXT NOT NULL,
+	recorded_at TEXT NOT NULL,
+	cost        REAL NOT NULL,
+	tokens      INTEGER NOT NULL,
+	messages    INTEGER NOT NULL,
+	sessions    INTEGER NOT NULL,
+	generations INTEGER NOT NULL,
+	prompts     INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS daily_key ON daily (provider, date, recorded_at);
+CREATE TABLE IF NOT EXISTS models (
+	provider      TEXT NOT NULL,
+	model         TEXT NOT NULL,
+	recorded_at   TEXT NOT NULL,
+	input_tokens  INTEGER NOT NULL,
+	output_tokens INTEGER NOT NULL,
+	cache_read    INTEGER NOT NULL,
+	cache_write   INTEGER NOT NULL,
+	cost          REAL NOT NULL,
+	generations   INTEGER NOT NULL,
+	requests      INTEGER NOT NULL,
+	estimated     INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS models_key ON models (provider, model, recorded_at);
+CREATE TABLE IF NOT EXISTS stats_snapshots (
+	source        TEXT NOT NULL,
+	computed_date TEXT NOT NULL,
+	recorded_at   TEXT NOT NULL,
+	cache         TEXT NOT NULL,
+	PRIMARY KEY (source, computed_date)
+);
+`
+
+// Ledger is an open ledger database.
+type Ledger struct {
+	db *sql.DB
+}
+
+// Open opens the ledger at path, creating it if needed.
+func Open(path string) (*Ledger, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite3", path+"?_busy_timeout=5000")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("ledger %s: %w", path, err)
+	}
+	return &Ledger{db: db}, nil
+}
+
+func (l *Ledger) Close() error { return l.db.Close() }
+
+// session is a snapshot of a SessionInfo, without its turns.
+type session struct {
+	ID, Title, Project, Model       string
+	Start, End                      string // RFC 3339, UTC
+	Messages, UserMessages, Prompts int
+	Tokens                          int
+	Cost                            float64
+	Requests, Errors                int
+	LatencyMS                       int64
+	Estimated                       bool
+}
+
+func snapshotSession(s provider.SessionInfo) session {
+	return session{
+		ID:           s.ID,
+		Title:        s.Title,
+		Project:      s.Project,
+		Model:        s.Model,
+		Start:        formatTime(s.StartTime),
+		End:          formatTime(s.EndTime),
+		Messages:     s.Messages,
+		UserMessages: s.UserMessages,
+		Prompts:      s.Prompts,
+		Tokens:       s.Tokens,
+		Cost:         s.Cost,
+		Requests:     s.Requests,
+		Errors:       s.Errors,
+		LatencyMS:    s.Latency.Milliseconds(),
+		Estimated:    s.Estimated,
+	}
+}
+
+func (s session) info() provider.SessionInfo {
+	return provider.SessionInfo{
+		ID:           s.ID,
+		Title:        s.Title,
+		Project:      s.Project,
+		Model:        s.Model,
+		StartTime:    parseTime(s.Start),
+		EndTime:      parseTime(s.End),
+		Messages:     s.Messages,
+		UserMessages: s.UserMessages,
+		Prompts:      s.Prompts,
+		Tokens:       s.Tokens,
+		Cost:         s.Cost,
+		Requests:     s.Requests,
+		Errors:       s.Errors,
+		Latency:      time.Duration(s.LatencyMS) * time.Millisecond,
+		Estimated:    s.Estimated,
+	}
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// recordedFormat is RFC 3339 with a fixed nine-digit fraction, so that
+// recorded_at values compare as text in time order, as MAX() needs.
+const recordedFormat = "2006-01-02T15:04:05.000000000Z07:00"
+
+func recordedNow() string {
+	return time.Now().UTC().Format(recordedFormat)
+}
+
+func parseTime(s string) time.Time {
+	t, _ := time.Parse(time.RFC3339Nano, s)
+	return t
+}
+
+// history is the latest snapshot of everything recorded for one provider.
+type history struct {
+	name, icon, color string
+	sessions          map[string]session
+	order             []string // Session ids by start time
+	daily             map[string]provider.DailyUsage
+	models            map[string]provider.ModelBreakdown
+}
+
+// Record snapshots providers' sessions, daily usage and model totals, adding
+// rows only for those that changed since they were last recorded. Usage only
+// grows, so a snapshot with fewer tokens, messages or generations than the
+// recorded one lost logs rather than usage; the recorded peak is kept.
+func (l *Ledger) Record(providers []*provider.ProviderData) error {
+	now := recordedNow()
+	tx, err := l.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, p := range providers {
+		if _, err := tx.Exec(`INSERT INTO providers (name, icon, color) VALUES (?, ?, ?)
+			ON CONFLICT (name) DO UPDATE SET icon = excluded.icon, color = excluded.color`,
+			p.ProviderName, p.Icon, p.Color); err != nil {
+			return err
+		}
+		h, err := latest(tx, p.ProviderName)
+		if err != nil {
+			return err
+		}
+
+		for _, si := range p.Sessions {
+			if si.ID == "" {
+				continue
+			}
+			s := snapshotSession(si)
+			if prev, ok := h.sessions[s.ID]; ok && (prev == s || s.Tokens < prev.Tokens || s.Messages < prev.Messages) {
+				continue
+			}
+			if _, err := tx.Exec(`INSERT INTO sessions (provider, session_id, recorded_at, title, project, model,
+				start_time, end_time, messages, user_messages, prompts, tokens, cost, requests, errors, latency_ms, estimated)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				p.ProviderName, s.ID, now, s.Title, s.Project, s.Model, s.Start, s.End,
+				s.Messages, s.UserMessages, s.Prompts, s.Tokens, s.Cost, s.Requests, s.Errors, s.LatencyMS, s.Estimated,
+			); err != nil {
+				return err
+			}
+		}
+
+		for _, d := range p.DailyUsage {
+			if prev, ok := h.daily[d.Date]; ok && (prev == d || dayShrank(d, prev)) {
+				continue
+			}
+			if _, err := tx.Exec(`INSERT INTO daily (provider, date, recorded_at, cost, tokens, messages, sessions, generations, prompts)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				p.ProviderName, d.Date, now, d.Cost, d.Tokens, d.Messages, d.Sessions, d.Generations, d.Prompts,
+			); err != nil {
+				return err
+			}
+		}
+
+		for _, m := range p.Models {
+			if prev, ok := h.models[m.Model]; ok && (prev == m || modelTokens(m) < modelTokens(prev) || m.Generations < prev.Generations) {
+				continue
+			}
+			if _, err := tx.Exec(`INSERT INTO models (provider, model, recorded_at, input_tokens, output_tokens,
+				cache_read, cache_write, cost, generations, requests, estimated)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				p.ProviderName, m.Model, now, m.InputTokens, m.OutputTokens, m.CacheRead, m.CacheWrite,
+				m.Cost, m.Generations, m.Requests, m.Estimated,
+			); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+type querier interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// latest reads the newest snapshot of each of a provider's sessions, days
+// and models. SQLite takes the bare columns of a MAX() aggregate from the row
+// holding the maximum.
+func latest(q querier, name string) (*history, error) {
+	h := &history{
+		name:     name,
+		sessions: make(map[string]session),
+		daily:    make(map[string]provider.DailyUsage),
+		models:   make(map[string]provider.ModelBreakdown),
+	}
+
+	rows, err := q.Query(`SELECT session_id, title, project, model, start_time, end_time, messages, user_messages,
+		prompts, tokens, cost, requests, errors, latency_ms, estimated, MAX(recorded_at)
+		FROM sessions WHERE provider = ? GROUP BY session_id ORDER BY start_time`, name)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var s session
+		var recorded string
+		if err := rows.Scan(&s.ID, &s.Title, &s.Project, &s.Model, &s.Start, &s.End, &s.Messages, &s.UserMessages,
+			&s.Prompts, &s.Tokens, &s.Cost, &s.Requests, &s.Errors, &s.LatencyMS, &s.Estimated, &recorded); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		h.sessions[s.ID] = s
+		h.order = append(h.order, s.ID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = q.Query(`SELECT date, cost, tokens, messages, sessions, generations, prompts, MAX(recorded_at)
+		FROM daily WHERE provider = ? GROUP BY date`, name)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var d provider.DailyUsage
+		var recorded string
+		if err := rows.Scan(&d.Date, &d.Cost, &d.Tokens, &d.Messages, &d.Sessions, &d.Generations, &d.Prompts, &recorded); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		h.daily[d.Date] = d
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = q.Query(`SELECT model, input_tokens, output_tokens, cache_read, cache_write, cost, generations,
+		requests, estimated, MAX(recorded_at) FROM models WHERE provider = ? GROUP BY model`, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var m provider.ModelBreakdown
+		var recorded string
+		if err := rows.Scan(&m.Model, &m.InputTokens, &m.OutputTokens, &m.CacheRead, &m.CacheWrite, &m.Cost,
+			&m.Generations, &m.Requests, &m.Estimated, &recorded); err != nil {
+			return nil, err
+		}
+		h.models[m.Model] = m
+	}
+	return h, rows.Err()
+}
+
+// histories reads the latest snapshots of every recorded provider.
+func (l *Ledger) histories() ([]*history, error) {
+	rows, err := l.db.Query(`SELECT name, icon, color FROM providers ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	type meta struct{ name, icon, color string }
+	var metas []meta
+	for rows.Next() {
+		var m meta
+		if err := rows.Scan(&m.name, &m.icon, &m.color); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		metas = append(metas, m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var out []*history
+	for _, m := range metas {
+		h, err := latest(l.db, m.name)
+		if err != nil {
+			return nil, err
+		}
+		h.icon, h.color = m.icon, m.color
+		out = append(out, h)
+	}
+	return out, nil
+}
+
+// Merge adds recorded history that live data no longer has: sessions whose
+// logs are gone, days and models where more usage was recorded than is
+// left, and providers that stopped loading altogether. Recorded usage only
+// wins when it has more tokens, messages or generations, so repricing still
+// applies to what's live. The totals are then recomputed.
+func (l *Ledger) Merge(agg *provider.AggregatedData) (*provider.AggregatedData, error) {
+	histories, err := l.histories()
+	if err != nil {
+		return nil, err
+	}
+	live := make(map[string]*provider.ProviderData, len(agg.Providers))
+	for _, p := range agg.Providers {
+		live[p.ProviderName] = p
+	}
+
+	providers := agg.Providers
+	for _, h := range histories {
+		p, ok := live[h.name]
+		if !ok {
+			p = &provider.ProviderData{
+				ProviderName: h.name,
+				Icon:         h.icon,
+				Color:        h.color,
+				Metadata:     map[string]string{"source": "ledger"},
+			}
+			providers = append(providers, p)
+		}
+		mergeHistory(p, h)
+	}
+	return provider.Aggregate(providers, agg.Errors), nil
+}
+
+func mergeHistory(p *provider.ProviderData, h *history) {
+	seen := make(map[string]bool, len(p.Sessions))
+	for _, s := range p.Sessions {
+		seen[s.ID] = true
+	}
+	archived := 0
+	var sessionCost float64
+	for _, id := range h.order {
+		if seen[id] {
+			continue
+		}
+		si := h.sessions[id].info()
+		p.Sessions = append(p.Sessions, si)
+		archived++
+		sessionCost += si.Cost
+		extendRange(p, si.StartTime, si.EndTime)
+	}
+
+	days := make(map[string]int, len(p.DailyUsage))
+	for i, d := range p.DailyUsage {
+		days[d.Date] = i
+	}
+	var dailyCost float64
+	for date, d := range h.daily {
+		if i, ok := days[date]; !ok {
+			p.DailyUsage = append(p.DailyUsage, d)
+			dailyCost += d.Cost
+		} else if live := p.DailyUsage[i]; d.Tokens > live.Tokens || d.Messages > live.Messages || d.Generations > live.Generations {
+			dailyCost += d.Cost - live.Cost
+			p.DailyUsage[i] = d
+		} else {
+			continue
+		}
+		if t, err := time.ParseInLocation("2006-01-02", date, time.Local); err == nil {
+			extendRange(p, t, t)
+		}
+	}
+	sort.Slice(p.DailyUsage, func(i, j int) bool {
+		return p.DailyUsage[i].Date < p.DailyUsage[j].Date
+	})
+
+	models := make(map[string]int, len(p.Models))
+	for i, m := range p.Models {
+		models[m.Model] = i
+	}
+	var modelCost float64
+	for name, m := range h.models {
+		if i, ok := models[name]; !ok {
+			p.Models = append(p.Models, m)
+			modelCost += m.Cost
+		} else if live := p.Models[i]; modelTokens(m) > modelTokens(live) || m.Generations > live.Generations {
+			modelCost += m.Cost - live.Cost
+			p.Models[i] = m
+		}
+	}
+	sort.SliceStable(p.Models, func(i, j int) bool {
+		return p.Models[i].Cost > p.Models[j].Cost
+	})
+
+	// Providers total their models' cost where they have models, and their
+	// days' or sessions' otherwise.
+	switch {
+	case len(h.models) > 0:
+		p.TotalCost += modelCost
+	case len(h.daily) > 0:
+		p.TotalCost += dailyCost
+	default:
+		p.TotalCost += sessionCost
+	}
+	if archived > 0 {
+		if p.Metadata == nil {
+			p.Metadata = make(map[string]string)
+		}
+		p.Metadata["archived_sessions"] = fmt.Sprint(archived)
+	}
+}
+
+// dayShrank reports whether d has less usage than was recorded for its date.
+func dayShrank(d, prev provider.DailyUsage) bool {
+	return d.Tokens < prev.Tokens || d.Messages < prev.Messages || d.Generations < prev.Generations
+}
+
+func modelTokens(m provider.ModelBreakdown) int {
+	return m.InputTokens + m.OutputTokens + m.CacheRead + m.CacheWrite
+}
+
+func extendRange(p *provider.ProviderData, start, end time.Time) {
+	if !start.IsZero() && (p.FirstSeen.IsZero() || start.Before(p.FirstSeen)) {
+		p.FirstSeen = start
+	}
+	if end.After(p.LastSeen) {
+		p.LastSeen = end
+	}
+}
+
+// Sync records agg in the ledger at path and returns it merged with the
+// recorded history. Callers that reload often, such as the live view, should
+// record once and use Load afterwards so the ledger doesn't grow with every
+// refresh.
+func Sync(path string, agg *provider.AggregatedData) (*provider.AggregatedData, error) {
+	l, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer l.Close()
+	if err := l.Record(agg.Providers); err != nil {
+		return nil, fmt.Errorf("ledger %s: %w", path, err)
+	}
+	return l.Merge(agg)
+}
+
+// Load returns agg merged with the history recorded in the ledger at path,
+// without recording agg.
+func Load(path string, agg *provider.AggregatedData) (*provider.AggregatedData, error) {
+	l, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer l.Close()
+	return l.Merge(agg)
+}
+
+// LoadAll loads providers and merges in the history kept in the ledger at
+// path, recording what was loaded first if record is set. Without a ledger
+// the live data is returned alongside the error.
+func LoadAll(path string, providers []provider.Provider, record bool) (*provider.AggregatedData, error) {
+	agg := provider.LoadAll(providers)
+	if path == "" {
+		return agg, nil
+	}
+	merge := Load
+	if record {
+		merge = Sync
+	}
+	merged, err := merge(path, agg)
+	if err != nil {
+		return agg, err
+	}
+	return merged, nil
+}
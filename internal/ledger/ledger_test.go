@@ -0,0 +1,204 @@
+package ledger
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/isaacaudet/aitop/internal/provider"
+)
+
+func countRows(t *testing.T, l *Ledger, table string) int {
+	t.Helper()
+	var n int
+	if err := l.db.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestLedgerKeepsPrunedHistory(t *testing.T) {
+	l, err := Open(filepath.Join(t.TempDir(), "aitop", "ledger.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	at := func(day, hour int) time.Time { return time.Date(2026, 3, day, hour, 0, 0, 0, time.Local) }
+	claude := &provider.ProviderData{
+		ProviderName: "Claude Code", Icon: "◈", Color: "#b4befe", TotalCost: 5,
+		Sessions: []provider.SessionInfo{
+			{ID: "a", Project: "/src/api", Model: "claude-sonnet-4-5", StartTime: at(1, 9), EndTime: at(1, 10), Messages: 4, Tokens: 1000, Cost: 2},
+			{ID: "b", Project: "/src/web", Model: "claude-sonnet-4-5", StartTime: at(2, 9), EndTime: at(2, 10), Messages: 6, Tokens: 1500, Cost: 3, Latency: 1500 * time.Millisecond},
+		},
+		DailyUsage: []provider.DailyUsage{
+			{Date: "2026-03-01", Cost: 2, Tokens: 1000, Messages: 4, Sessions: 1},
+			{Date: "2026-03-02", Cost: 3, Tokens: 1500, Messages: 6, Sessions: 1},
+		},
+	}
+	gemini := &provider.ProviderData{
+		ProviderName: "Gemini", Icon: "✦", Color: "#74c7ec", TotalCost: 0.5,
+		Sessions:   []provider.SessionInfo{{ID: "g1", StartTime: at(1, 12), EndTime: at(1, 13), Tokens: 800, Cost: 0.5}},
+		DailyUsage: []provider.DailyUsage{{Date: "2026-03-01", Cost: 0.5, Tokens: 800, Sessions: 1}},
+		Models:     []provider.ModelBreakdown{{Model: "gemini-2.5-pro", InputTokens: 600, OutputTokens: 200, Cost: 0.5}},
+	}
+	for range 2 {
+		if err := l.Record([]*provider.ProviderData{claude, gemini}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Unchanged snapshots aren't recorded twice.
+	if n := countRows(t, l, "sessions"); n != 3 {
+		t.Errorf("expected 3 session rows, got %d", n)
+	}
+
+	// Session a's transcript is pruned, b grows, and Gemini's tmp dir is gone.
+	live := &provider.ProviderData{
+		ProviderName: "Claude Code", Icon: "◈", Color: "#b4befe", TotalCost: 4,
+		Sessions: []provider.SessionInfo{
+			{ID: "b", Project: "/src/web", Model: "claude-sonnet-4-5", StartTime: at(2, 9), EndTime: at(2, 11), Messages: 8, Tokens: 2500, Cost: 4},
+		},
+		DailyUsage: []provider.DailyUsage{{Date: "2026-03-02", Cost: 4, Tokens: 2500, Messages: 8, Sessions: 1}},
+	}
+	agg := provider.Aggregate([]*provider.ProviderData{live}, nil)
+	if err := l.Record(agg.Providers); err != nil {
+		t.Fatal(err)
+	}
+	if n := countRows(t, l, "sessions"); n != 4 {
+		t.Errorf("expected a new row for the changed session only, got %d rows", n)
+	}
+
+	merged, err := l.Merge(agg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(merged.Providers) != 2 {
+		t.Fatalf("expected Gemini restored from the ledger, got %d providers", len(merged.Providers))
+	}
+	c := merged.Providers[0]
+	if len(c.Sessions) != 2 || c.Sessions[1].ID != "a" || c.Sessions[0].Tokens != 2500 {
+		t.Errorf("expected live b plus archived a, got %+v", c.Sessions)
+	}
+	if c.Metadata["archived_sessions"] != "1" {
+		t.Errorf("expected 1 archived session, got %v", c.Metadata)
+	}
+	if len(c.DailyUsage) != 2 || math.Abs(c.TotalCost-6) > 1e-9 {
+		t.Errorf("expected 2026-03-01 restored and $6 total, got %+v $%.2f", c.DailyUsage, c.TotalCost)
+	}
+	if c.FirstSeen.IsZero() || !c.FirstSeen.Before(at(2, 0)) {
+		t.Errorf("expected first seen extended to March 1, got %v", c.FirstSeen)
+	}
+
+	g := merged.Providers[1]
+	if g.ProviderName != "Gemini" || g.Icon != "✦" || g.Metadata["source"] != "ledger" ||
+		math.Abs(g.TotalCost-0.5) > 1e-9 || len(g.Models) != 1 || g.Models[0].OutputTokens != 200 {
+		t.Errorf("unexpected ledger-only provider %+v", g)
+	}
+	if merged.TotalSessions != 3 || math.Abs(merged.TotalCost-6.5) > 1e-9 || len(merged.DailyUsage) != 2 {
+		t.Errorf("unexpected merged totals: %d sessions, $%.2f, %d days",
+			merged.TotalSessions, merged.TotalCost, len(merged.DailyUsage))
+	}
+}
+
+func TestLedgerKeepsPeakOfShrunkDay(t *testing.T) {
+	l, err := Open(filepath.Join(t.TempDir(), "ledger.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	at := func(hour int) time.Time { return time.Date(2026, 3, 1, hour, 0, 0, 0, time.Local) }
+	a := provider.SessionInfo{ID: "a", StartTime: at(9), EndTime: at(10), Messages: 4, Tokens: 1000, Cost: 2}
+	b := provider.SessionInfo{ID: "b", StartTime: at(14), EndTime: at(15), Messages: 6, Tokens: 1500, Cost: 3}
+	full := &provider.ProviderData{
+		ProviderName: "Claude Code", Icon: "◈", Color: "#b4befe", TotalCost: 5,
+		Sessions:   []provider.SessionInfo{a, b},
+		DailyUsage: []provider.DailyUsage{{Date: "2026-03-01", Cost: 5, Tokens: 2500, Messages: 10, Sessions: 2}},
+	}
+	if err := l.Record([]*provider.ProviderData{full}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Session a's transcript is pruned, and b's shrinks as its log is
+	// truncated: neither the day nor b is recorded again.
+	b.Messages, b.Tokens, b.Cost = 2, 500, 1
+	pruned := &provider.ProviderData{
+		ProviderName: "Claude Code", Icon: "◈", Color: "#b4befe", TotalCost: 1,
+		Sessions:   []provider.SessionInfo{b},
+		DailyUsage: []provider.DailyUsage{{Date: "2026-03-01", Cost: 1, Tokens: 500, Messages: 2, Sessions: 1}},
+	}
+	for range 2 {
+		if err := l.Record([]*provider.ProviderData{pruned}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := countRows(t, l, "daily"); n != 1 {
+		t.Errorf("expected the shrunk day not recorded, got %d rows", n)
+	}
+	if n := countRows(t, l, "sessions"); n != 2 {
+		t.Errorf("expected the shrunk session not recorded, got %d rows", n)
+	}
+
+	merged, err := l.Merge(provider.Aggregate([]*provider.ProviderData{pruned}, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := merged.Providers[0]
+	if len(c.DailyUsage) != 1 || c.DailyUsage[0].Sessions != 2 || c.DailyUsage[0].Tokens != 2500 {
+		t.Errorf("expected the day's recorded peak, got %+v", c.DailyUsage)
+	}
+	if len(c.Sessions) != 2 || math.Abs(c.TotalCost-5) > 1e-9 {
+		t.Errorf("expected live b plus archived a at $5, got %d sessions at $%.2f", len(c.Sessions), c.TotalCost)
+	}
+}
+
+func TestRecordedAtSortsAsText(t *testing.T) {
+	// RFC3339Nano drops trailing zeros, so "…:00Z" would sort after
+	// "…:00.5Z"; recorded_at must compare in time order for MAX().
+	whole := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	times := []time.Time{whole, whole.Add(5 * time.Millisecond), whole.Add(500 * time.Millisecond), whole.Add(time.Second)}
+	for i := 1; i < len(times); i++ {
+		prev, cur := times[i-1].Format(recordedFormat), times[i].Format(recordedFormat)
+		if prev >= cur || len(prev) != len(cur) {
+			t.Errorf("%s should sort before %s", prev, cur)
+		}
+		if !parseTime(cur).Equal(times[i]) {
+			t.Errorf("parseTime(%s) = %v", cur, parseTime(cur))
+		}
+	}
+}
+
+func TestLoadAllRecordsOnlyWhenAsked(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.db")
+	providers := []provider.Provider{provider.NewAPI("../../testdata/api")}
+
+	count := func() int {
+		l, err := Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer l.Close()
+		return countRows(t, l, "sessions")
+	}
+
+	if _, err := LoadAll(path, providers, false); err != nil {
+		t.Fatal(err)
+	}
+	if n := count(); n != 0 {
+		t.Errorf("expected a read-only load to record nothing, got %d sessions", n)
+	}
+	agg, err := LoadAll(path, providers, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := count(); n != len(agg.Providers[0].Sessions) || n == 0 {
+		t.Errorf("expected every session recorded, got %d", n)
+	}
+
+	// A ledger that can't be opened still yields the live data.
+	agg, err = LoadAll(t.TempDir(), providers, true)
+	if err == nil || agg == nil || len(agg.Providers) != 1 {
+		t.Errorf("expected live data alongside an error, got %v %v", agg, err)
+	}
+}
@@ -0,0 +1,132 @@
+package ledger
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/isaacaudet/aitop/internal/model"
+)
+
+// Claude Code overwrites stats-cache.json in place, so its cumulative totals
+// can't be compared over time. The ledger keeps a copy of each cache every
+// time its LastComputedDate moves on.
+
+// StatsSnapshot is a stats cache as it was when first seen at a
+// LastComputedDate.
+type StatsSnapshot struct {
+	Source     string // Path of the stats-cache.json
+	RecordedAt time.Time
+	Cache      *model.StatsCache
+}
+
+// StatsChange is what changed in one stats cache between two consecutive
+// snapshots.
+type StatsChange struct {
+	Source     string
+	RecordedAt time.Time // When the later snapshot was taken
+	model.StatsDelta
+}
+
+// RecordStatsCache snapshots the stats cache read from source unless one
+// with the same LastComputedDate was already recorded.
+func (l *Ledger) RecordStatsCache(source string, cache *model.StatsCache) error {
+	if cache == nil || cache.LastComputedDate == "" {
+		return nil
+	}
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	_, err = l.db.Exec(`INSERT INTO stats_snapshots (source, computed_date, recorded_at, cache)
+		VALUES (?, ?, ?, ?) ON CONFLICT (source, computed_date) DO NOTHING`,
+		source, cache.LastComputedDate, recordedNow(), string(data))
+	return err
+}
+
+// StatsSnapshots returns every recorded stats cache, by source and then
+// LastComputedDate.
+func (l *Ledger) StatsSnapshots() ([]StatsSnapshot, error) {
+	rows, err := l.db.Query(`SELECT source, recorded_at, cache FROM stats_snapshots
+		ORDER BY source, computed_date`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snaps []StatsSnapshot
+	for rows.Next() {
+		var s StatsSnapshot
+		var recorded, data string
+		if err := rows.Scan(&s.Source, &recorded, &data); err != nil {
+			return nil, err
+		}
+		s.RecordedAt = parseTime(recorded)
+		if err := json.Unmarshal([]byte(data), &s.Cache); err != nil {
+			return nil, fmt.Errorf("stats snapshot %s of %s: %w", recorded, s.Source, err)
+		}
+		snaps = append(snaps, s)
+	}
+	return snaps, rows.Err()
+}
+
+// StatsChanges diffs each source's consecutive snapshots, oldest first.
+func StatsChanges(snaps []StatsSnapshot) []StatsChange {
+	var changes []StatsChange
+	for i := 1; i < len(snaps); i++ {
+		prev, cur := snaps[i-1], snaps[i]
+		if prev.Source != cur.Source {
+			continue
+		}
+		changes = append(changes, StatsChange{
+			Source:     cur.Source,
+			RecordedAt: cur.RecordedAt,
+			StatsDelta: model.DiffStatsCaches(prev.Cache, cur.Cache),
+		})
+	}
+	return changes
+}
+
+// LatestStatsChange combines the most recent change of each of sources, or
+// of every source if none are given. It returns nil if none has been
+// snapshotted twice.
+func LatestStatsChange(changes []StatsChange, sources ...string) *model.StatsDelta {
+	latest := make(map[string]model.StatsDelta)
+	for _, c := range changes {
+		if len(sources) == 0 || slices.Contains(sources, c.Source) {
+			latest[c.Source] = c.StatsDelta
+		}
+	}
+	if len(latest) == 0 {
+		return nil
+	}
+	deltas := make([]model.StatsDelta, 0, len(latest))
+	for _, d := range latest {
+		deltas = append(deltas, d)
+	}
+	merged := model.MergeStatsDeltas(deltas...)
+	return &merged
+}
+
+// SnapshotStatsCaches records each source's stats cache in the ledger at
+// path and returns the latest changes across them.
+func SnapshotStatsCaches(path string, caches map[string]*model.StatsCache) (*model.StatsDelta, error) {
+	l, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer l.Close()
+	sources := make([]string, 0, len(caches))
+	for source, cache := range caches {
+		if err := l.RecordStatsCache(source, cache); err != nil {
+			return nil, fmt.Errorf("ledger %s: %w", path, err)
+		}
+		sources = append(sources, source)
+	}
+	snaps, err := l.StatsSnapshots()
+	if err != nil {
+		return nil, err
+	}
+	return LatestStatsChange(StatsChanges(snaps), sources...), nil
+}
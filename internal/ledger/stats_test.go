@@ -0,0 +1,84 @@
+package ledger
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/isaacaudet/aitop/internal/model"
+)
+
+func TestStatsSnapshots(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.db")
+	cache := func(date string, sessions, sonnetOut, opusOut, hour14 int, longest int64) *model.StatsCache {
+		return &model.StatsCache{
+			LastComputedDate: date,
+			TotalSessions:    sessions,
+			ModelUsage: map[string]model.ModelUsage{
+				"claude-sonnet-4-5": {InputTokens: 1000, OutputTokens: sonnetOut},
+				"claude-opus-4-5":   {OutputTokens: opusOut},
+			},
+			HourCounts:     map[string]int{"9": 5, "14": hour14},
+			LongestSession: model.LongestSession{SessionID: "s1", Duration: longest},
+		}
+	}
+	work := "/home/dev/.claude-work/stats-cache.json"
+
+	for _, c := range []*model.StatsCache{
+		cache("2026-03-01", 10, 500, 100, 2, 60_000),
+		cache("2026-03-01", 11, 600, 100, 2, 60_000), // Same date: not snapshotted again
+		cache("2026-03-08", 15, 1_000_500, 100, 10, 60_000),
+	} {
+		if _, err := SnapshotStatsCaches(path, map[string]*model.StatsCache{work: c}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	latest, err := SnapshotStatsCaches(path, map[string]*model.StatsCache{
+		work: cache("2026-03-15", 18, 1_000_500, 2_000_100, 10, 3_600_000),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	snaps, err := l.StatsSnapshots()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snaps) != 3 || snaps[0].Cache.TotalSessions != 10 {
+		t.Fatalf("expected 3 snapshots keeping the first of 2026-03-01, got %d", len(snaps))
+	}
+
+	changes := StatsChanges(snaps)
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes, got %d", len(changes))
+	}
+	week := changes[0]
+	if week.From != "2026-03-01" || week.To != "2026-03-08" || week.Sessions != 5 {
+		t.Errorf("unexpected first change %+v", week.StatsDelta)
+	}
+	if len(week.Models) != 1 || week.Models["claude-sonnet-4-5"].OutputTokens != 1_000_000 || week.Tokens() != 1_000_000 {
+		t.Errorf("expected only sonnet's output to grow, got %+v", week.Models)
+	}
+	if week.Hours["14"] != 8 || len(week.Hours) != 1 || week.LongestSession != nil {
+		t.Errorf("unexpected hours %v or longest session %v", week.Hours, week.LongestSession)
+	}
+
+	if latest == nil || latest.From != "2026-03-08" || latest.Sessions != 3 {
+		t.Fatalf("expected the latest change returned, got %+v", latest)
+	}
+	if latest.Models["claude-opus-4-5"].OutputTokens != 2_000_000 || latest.Cost() <= 0 {
+		t.Errorf("expected opus's growth priced, got %+v", latest.Models)
+	}
+	if latest.LongestSession == nil || latest.LongestSession.Duration != 3_600_000 {
+		t.Errorf("expected the new longest session, got %v", latest.LongestSession)
+	}
+
+	// Caches aitop no longer reads don't count towards the latest change.
+	if LatestStatsChange(changes, "/home/dev/.claude/stats-cache.json") != nil {
+		t.Error("expected no change for an unrecorded source")
+	}
+}
@@ -1,6 +1,9 @@
 package model
 
-import "time"
+import (
+	"sort"
+	"time"
+)
 
 // AggregateDaily converts StatsCache data into a slice of DailyStats with costs.
 func AggregateDaily(cache *StatsCache) []DailyStats {
@@ -130,3 +133,100 @@ func ComputeBurnRate(days []DailyStats) BurnRate {
 		TrendVsLastWeek: trend,
 	}
 }
+
+// MergeStatsCaches combines stats caches from several Claude config dirs
+// (e.g. separate work and personal accounts) into one. Nil caches are skipped,
+// as are copies of a cache already merged (e.g. a copied ~/.claude), which
+// share its first session and computed dates; a single cache is returned
+// as-is.
+func MergeStatsCaches(caches ...*StatsCache) *StatsCache {
+	type identity struct{ first, computed string }
+	seen := make(map[identity]bool)
+	var nonNil []*StatsCache
+	for _, c := range caches {
+		if c == nil {
+			continue
+		}
+		if c.FirstSessionDate != "" {
+			id := identity{c.FirstSessionDate, c.LastComputedDate}
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+		}
+		nonNil = append(nonNil, c)
+	}
+	switch len(nonNil) {
+	case 0:
+		return nil
+	case 1:
+		return nonNil[0]
+	}
+
+	merged := &StatsCache{
+		ModelUsage: make(map[string]ModelUsage),
+		HourCounts: make(map[string]int),
+	}
+	activity := make(map[string]DailyActivity)
+	tokens := make(map[string]map[string]int)
+
+	for _, c := range nonNil {
+		if c.Version > merged.Version {
+			merged.Version = c.Version
+		}
+		if c.LastComputedDate > merged.LastComputedDate {
+			merged.LastComputedDate = c.LastComputedDate
+		}
+		if merged.FirstSessionDate == "" || (c.FirstSessionDate != "" && c.FirstSessionDate < merged.FirstSessionDate) {
+			merged.FirstSessionDate = c.FirstSessionDate
+		}
+		if c.LongestSession.Duration > merged.LongestSession.Duration {
+			merged.LongestSession = c.LongestSession
+		}
+		merged.TotalSessions += c.TotalSessions
+		merged.TotalMessages += c.TotalMessages
+
+		for _, da := range c.DailyActivity {
+			existing := activity[da.Date]
+			existing.Date = da.Date
+			existing.MessageCount += da.MessageCount
+			existing.SessionCount += da.SessionCount
+			existing.ToolCallCount += da.ToolCallCount
+			activity[da.Date] = existing
+		}
+		for _, dt := range c.DailyModelTokens {
+			if tokens[dt.Date] == nil {
+				tokens[dt.Date] = make(map[string]int)
+			}
+			for m, n := range dt.TokensByModel {
+				tokens[dt.Date][m] += n
+			}
+		}
+		for m, mu := range c.ModelUsage {
+			existing := merged.ModelUsage[m]
+			existing.InputTokens += mu.InputTokens
+			existing.OutputTokens += mu.OutputTokens
+			existing.CacheReadInputTokens += mu.CacheReadInputTokens
+			existing.CacheCreationInputTokens += mu.CacheCreationInputTokens
+			merged.ModelUsage[m] = existing
+		}
+		for h, n := range c.HourCounts {
+			merged.HourCounts[h] += n
+		}
+	}
+
+	for _, da := range activity {
+		merged.DailyActivity = append(merged.DailyActivity, da)
+	}
+	sort.Slice(merged.DailyActivity, func(i, j int) bool {
+		return merged.DailyActivity[i].Date < merged.DailyActivity[j].Date
+	})
+	for date, byModel := range tokens {
+		merged.DailyModelTokens = append(merged.DailyModelTokens, DailyModelTokens{Date: date, TokensByModel: byModel})
+	}
+	sort.Slice(merged.DailyModelTokens, func(i, j int) bool {
+		return merged.DailyModelTokens[i].Date < merged.DailyModelTokens[j].Date
+	})
+
+	return merged
+}
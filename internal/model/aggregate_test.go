@@ -0,0 +1,32 @@
+package model
+
+import "testing"
+
+func TestMergeStatsCaches(t *testing.T) {
+	cache := func(first, computed string, sessions int) *StatsCache {
+		return &StatsCache{
+			FirstSessionDate: first,
+			LastComputedDate: computed,
+			TotalSessions:    sessions,
+			ModelUsage:       map[string]ModelUsage{"claude-sonnet-4-5": {OutputTokens: 1000}},
+			DailyActivity:    []DailyActivity{{Date: computed, SessionCount: sessions}},
+		}
+	}
+	work := cache("2025-06-01T09:00:00Z", "2026-03-01", 10)
+	copied := cache("2025-06-01T09:00:00Z", "2026-03-01", 10)
+	personal := cache("2025-09-12T20:00:00Z", "2026-03-01", 4)
+
+	if got := MergeStatsCaches(work, copied); got != work {
+		t.Errorf("expected a copied cache to be skipped, got %d sessions", got.TotalSessions)
+	}
+	got := MergeStatsCaches(work, copied, nil, personal)
+	if got.TotalSessions != 14 || got.ModelUsage["claude-sonnet-4-5"].OutputTokens != 2000 {
+		t.Errorf("expected work and personal summed once each, got %d sessions %+v", got.TotalSessions, got.ModelUsage)
+	}
+	if len(got.DailyActivity) != 1 || got.DailyActivity[0].SessionCount != 14 {
+		t.Errorf("unexpected daily activity %+v", got.DailyActivity)
+	}
+	if got.FirstSessionDate != "2025-06-01T09:00:00Z" {
+		t.Errorf("expected the earliest first session, got %s", got.FirstSessionDate)
+	}
+}
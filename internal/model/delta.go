@@ -0,0 +1,94 @@
+package model
+
+// StatsDelta is what changed in the stats cache between two snapshots.
+type StatsDelta struct {
+	From, To string // The snapshots' LastComputedDate
+	Sessions int
+	Messages int
+	Models   map[string]ModelUsage
+	Hours    map[string]int
+	// LongestSession is set when a new longest session was recorded.
+	LongestSession *LongestSession
+}
+
+// DiffStatsCaches returns how cur's cumulative totals grew since prev.
+// Totals that shrank, as when Claude Code rebuilds the cache from fewer
+// transcripts, count as unchanged.
+func DiffStatsCaches(prev, cur *StatsCache) StatsDelta {
+	d := StatsDelta{
+		From:     prev.LastComputedDate,
+		To:       cur.LastComputedDate,
+		Sessions: max(cur.TotalSessions-prev.TotalSessions, 0),
+		Messages: max(cur.TotalMessages-prev.TotalMessages, 0),
+		Models:   make(map[string]ModelUsage),
+		Hours:    make(map[string]int),
+	}
+	for name, mu := range cur.ModelUsage {
+		was := prev.ModelUsage[name]
+		grew := ModelUsage{
+			InputTokens:              max(mu.InputTokens-was.InputTokens, 0),
+			OutputTokens:             max(mu.OutputTokens-was.OutputTokens, 0),
+			CacheReadInputTokens:     max(mu.CacheReadInputTokens-was.CacheReadInputTokens, 0),
+			CacheCreationInputTokens: max(mu.CacheCreationInputTokens-was.CacheCreationInputTokens, 0),
+		}
+		if grew != (ModelUsage{}) {
+			d.Models[name] = grew
+		}
+	}
+	for h, n := range cur.HourCounts {
+		if grew := n - prev.HourCounts[h]; grew > 0 {
+			d.Hours[h] = grew
+		}
+	}
+	if cur.LongestSession != prev.LongestSession && cur.LongestSession.Duration > prev.LongestSession.Duration {
+		longest := cur.LongestSession
+		d.LongestSession = &longest
+	}
+	return d
+}
+
+// Tokens is the total token growth across models.
+func (d StatsDelta) Tokens() int {
+	var total int
+	for _, mu := range d.Models {
+		total += mu.InputTokens + mu.OutputTokens + mu.CacheReadInputTokens + mu.CacheCreationInputTokens
+	}
+	return total
+}
+
+// Cost prices the token growth at current pricing.
+func (d StatsDelta) Cost() float64 {
+	return TotalCostFromModelUsage(d.Models)
+}
+
+// MergeStatsDeltas combines deltas from several Claude config dirs into one,
+// spanning the earliest From to the latest To.
+func MergeStatsDeltas(deltas ...StatsDelta) StatsDelta {
+	merged := StatsDelta{
+		Models: make(map[string]ModelUsage),
+		Hours:  make(map[string]int),
+	}
+	for _, d := range deltas {
+		if merged.From == "" || (d.From != "" && d.From < merged.From) {
+			merged.From = d.From
+		}
+		merged.To = max(merged.To, d.To)
+		merged.Sessions += d.Sessions
+		merged.Messages += d.Messages
+		for name, mu := range d.Models {
+			existing := merged.Models[name]
+			existing.InputTokens += mu.InputTokens
+			existing.OutputTokens += mu.OutputTokens
+			existing.CacheReadInputTokens += mu.CacheReadInputTokens
+			existing.CacheCreationInputTokens += mu.CacheCreationInputTokens
+			merged.Models[name] = existing
+		}
+		for h, n := range d.Hours {
+			merged.Hours[h] += n
+		}
+		if d.LongestSession != nil && (merged.LongestSession == nil || d.LongestSession.Duration > merged.LongestSession.Duration) {
+			merged.LongestSession = d.LongestSession
+		}
+	}
+	return merged
+}
@@ -46,6 +46,18 @@ var pricingTable = map[string]ModelPricing{
 		CacheReadPerMTok:  1.50,
 		CacheWritePerMTok: 18.75,
 	},
+	"opus-4": {
+		InputPerMTok:      15.0,
+		OutputPerMTok:     75.0,
+		CacheReadPerMTok:  1.50,
+		CacheWritePerMTok: 18.75,
+	},
+	"sonnet-4": {
+		InputPerMTok:      3.0,
+		OutputPerMTok:     15.0,
+		CacheReadPerMTok:  0.30,
+		CacheWritePerMTok: 3.75,
+	},
 	// OpenAI models
 	"gpt-4o": {
 		InputPerMTok:  2.50,
@@ -72,6 +84,11 @@ var pricingTable = map[string]ModelPricing{
 		InputPerMTok:  0.30,
 		OutputPerMTok: 2.50,
 	},
+	// Alibaba Qwen models (Qwen Code)
+	"qwen3-coder-plus": {
+		InputPerMTok:  1.0,
+		OutputPerMTok: 5.0,
+	},
 }
 
 // dateSuffixRe strips date suffixes like -20251101 or -20250929.
@@ -87,19 +104,47 @@ func NormalizeModelName(model string) string {
 	return name
 }
 
+// customPricing holds user-configured prices, consulted before pricingTable.
+var customPricing map[string]ModelPricing
+
+// SetCustomPricing installs user-configured prices keyed by model name or
+// prefix. They take precedence over the built-in table, so they can both add
+// models (e.g. local or self-hosted ones) and correct built-in prices. It
+// returns the prices it replaced.
+func SetCustomPricing(prices map[string]ModelPricing) map[string]ModelPricing {
+	prev := customPricing
+	customPricing = make(map[string]ModelPricing, len(prices))
+	for name, p := range prices {
+		customPricing[NormalizeModelName(name)] = p
+	}
+	return prev
+}
+
 // GetPricing returns the pricing for a model name (with or without prefix/suffix).
 func GetPricing(model string) (ModelPricing, bool) {
 	key := NormalizeModelName(model)
-	// Try exact match first.
-	if p, ok := pricingTable[key]; ok {
+	if p, ok := lookupPricing(customPricing, key); ok {
 		return p, true
 	}
-	// Try prefix match (e.g., "opus-4-5-thinking" matches "opus-4-5").
-	for prefix, p := range pricingTable {
-		if strings.HasPrefix(key, prefix) {
-			return p, true
+	return lookupPricing(pricingTable, key)
+}
+
+// lookupPricing finds key in table, trying an exact match before the longest
+// matching prefix (e.g., "opus-4-5-thinking" matches "opus-4-5", and
+// "opus-4-5-x" never matches "opus-4").
+func lookupPricing(table map[string]ModelPricing, key string) (ModelPricing, bool) {
+	if p, ok := table[key]; ok {
+		return p, true
+	}
+	var best string
+	for prefix := range table {
+		if strings.HasPrefix(key, prefix) && len(prefix) > len(best) {
+			best = prefix
 		}
 	}
+	if best != "" {
+		return table[best], true
+	}
 	return ModelPricing{}, false
 }
 
@@ -42,11 +42,14 @@ func TestGetPricing(t *testing.T) {
 		{"claude-haiku-4-5-20251001", true, 4.0},
 		{"claude-opus-4-5-thinking", true, 25.0},
 		{"claude-opus-4-1-20250805", true, 75.0},
+		{"claude-opus-4-20250514", true, 75.0},
+		{"claude-sonnet-4-20250514", true, 15.0},
 		{"gpt-4o", true, 10.0},
 		{"o3", true, 40.0},
 		{"o4-mini", true, 4.40},
 		{"models/gemini-2.5-pro", true, 10.0},
 		{"gemini-2.5-flash", true, 2.50},
+		{"qwen3-coder-plus", true, 5.0},
 		{"unknown-model", false, 0},
 	}
 	for _, tt := range tests {
@@ -122,3 +125,21 @@ func TestCalculateCostFromRealData(t *testing.T) {
 	}
 	t.Logf("Total cost from real data: $%.2f", total)
 }
+
+func TestCustomPricing(t *testing.T) {
+	prev := SetCustomPricing(map[string]ModelPricing{
+		"qwen3-coder":     {InputPerMTok: 0.5, OutputPerMTok: 2},
+		"claude-opus-4-6": {InputPerMTok: 1, OutputPerMTok: 1},
+	})
+	t.Cleanup(func() { SetCustomPricing(prev) })
+
+	if p, ok := GetPricing("qwen3-coder:30b"); !ok || p.OutputPerMTok != 2 {
+		t.Errorf("GetPricing(qwen3-coder:30b) = %+v, %v; want custom price", p, ok)
+	}
+	if p, _ := GetPricing("claude-opus-4-6"); p.InputPerMTok != 1 {
+		t.Errorf("custom price should override built-in, got %+v", p)
+	}
+	if p, _ := GetPricing("claude-opus-4-5"); p.InputPerMTok != 5 {
+		t.Errorf("built-in price should still apply, got %+v", p)
+	}
+}
@@ -75,6 +75,14 @@ type Session struct {
 	UserMessages int
 	TokenUsage   TokenUsage
 	Models       map[string]TokenUsage
+	Turns        []MessageUsage
+}
+
+// MessageUsage holds token usage for a single assistant message.
+type MessageUsage struct {
+	Timestamp time.Time
+	Model     string
+	Usage     TokenUsage
 }
 
 // TokenUsage holds aggregated token counts.
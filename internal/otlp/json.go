@@ -0,0 +1,71 @@
+package otlp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// OTLP/JSON encodes 64-bit integers as decimal strings, though some
+// exporters send plain numbers, and enums as either numbers or names.
+
+type jsonUint64 uint64
+
+func (n *jsonUint64) UnmarshalJSON(b []byte) error {
+	v, err := strconv.ParseUint(string(bytes.Trim(b, `"`)), 10, 64)
+	if err != nil {
+		return fmt.Errorf("otlp: bad uint64 %s", b)
+	}
+	*n = jsonUint64(v)
+	return nil
+}
+
+type jsonInt64 int64
+
+func (n *jsonInt64) UnmarshalJSON(b []byte) error {
+	v, err := strconv.ParseInt(string(bytes.Trim(b, `"`)), 10, 64)
+	if err != nil {
+		return fmt.Errorf("otlp: bad int64 %s", b)
+	}
+	*n = jsonInt64(v)
+	return nil
+}
+
+type temporality int
+
+func (t *temporality) UnmarshalJSON(b []byte) error {
+	switch string(b) {
+	case `"AGGREGATION_TEMPORALITY_DELTA"`:
+		*t = temporalityDelta
+	case `"AGGREGATION_TEMPORALITY_CUMULATIVE"`:
+		*t = temporalityCumulative
+	case `"AGGREGATION_TEMPORALITY_UNSPECIFIED"`:
+		*t = 0
+	default:
+		v, err := strconv.Atoi(string(b))
+		if err != nil {
+			return fmt.Errorf("otlp: bad aggregation temporality %s", b)
+		}
+		*t = temporality(v)
+	}
+	return nil
+}
+
+// DecodeMetricsJSON decodes an OTLP/JSON ExportMetricsServiceRequest.
+func DecodeMetricsJSON(b []byte) ([]Record, error) {
+	var req metricsRequest
+	if err := json.Unmarshal(b, &req); err != nil {
+		return nil, fmt.Errorf("otlp: decoding metrics: %w", err)
+	}
+	return req.records(), nil
+}
+
+// DecodeLogsJSON decodes an OTLP/JSON ExportLogsServiceRequest.
+func DecodeLogsJSON(b []byte) ([]Record, error) {
+	var req logsRequest
+	if err := json.Unmarshal(b, &req); err != nil {
+		return nil, fmt.Errorf("otlp: decoding logs: %w", err)
+	}
+	return req.records(), nil
+}
@@ -0,0 +1,237 @@
+// Package otlp receives OpenTelemetry metrics and log events over OTLP/HTTP,
+// in either the JSON or the protobuf encoding, and stores them as flat
+// records. It is how aitop collects the telemetry Claude Code exports when
+// CLAUDE_CODE_ENABLE_TELEMETRY is set.
+package otlp
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Record kinds.
+const (
+	KindMetric = "metric"
+	KindEvent  = "event"
+)
+
+// Record is one metric data point or log event. Resource and point
+// attributes are merged into Attrs, the point's own taking precedence.
+type Record struct {
+	Kind       string            `json:"kind"`
+	Name       string            `json:"name"`
+	Time       time.Time         `json:"time"`
+	Start      time.Time         `json:"start,omitzero"` // Start of the interval a metric point covers
+	Value      float64           `json:"value,omitempty"`
+	Unit       string            `json:"unit,omitempty"`
+	Cumulative bool              `json:"cumulative,omitempty"` // Value is a running total since Start, not a delta
+	Attrs      map[string]string `json:"attrs,omitempty"`
+}
+
+// Aggregation temporality of a sum (opentelemetry.proto.metrics.v1).
+const (
+	temporalityDelta      = 1
+	temporalityCumulative = 2
+)
+
+// The types below mirror the OTLP messages aitop uses, named as in the JSON
+// encoding; the protobuf decoder fills the same structs.
+
+type metricsRequest struct {
+	ResourceMetrics []resourceMetrics `json:"resourceMetrics"`
+}
+
+type resourceMetrics struct {
+	Resource     resource       `json:"resource"`
+	ScopeMetrics []scopeMetrics `json:"scopeMetrics"`
+}
+
+type resource struct {
+	Attributes []keyValue `json:"attributes"`
+}
+
+type scopeMetrics struct {
+	Metrics []metric `json:"metrics"`
+}
+
+// metric holds a gauge or a sum; other metric types are ignored.
+type metric struct {
+	Name  string      `json:"name"`
+	Unit  string      `json:"unit"`
+	Gauge *numberData `json:"gauge"`
+	Sum   *numberData `json:"sum"`
+}
+
+type numberData struct {
+	DataPoints             []numberDataPoint `json:"dataPoints"`
+	AggregationTemporality temporality       `json:"aggregationTemporality"`
+}
+
+type numberDataPoint struct {
+	Attributes        []keyValue `json:"attributes"`
+	StartTimeUnixNano jsonUint64 `json:"startTimeUnixNano"`
+	TimeUnixNano      jsonUint64 `json:"timeUnixNano"`
+	AsDouble          *float64   `json:"asDouble"`
+	AsInt             *jsonInt64 `json:"asInt"`
+}
+
+type logsRequest struct {
+	ResourceLogs []resourceLogs `json:"resourceLogs"`
+}
+
+type resourceLogs struct {
+	Resource  resource    `json:"resource"`
+	ScopeLogs []scopeLogs `json:"scopeLogs"`
+}
+
+type scopeLogs struct {
+	LogRecords []logRecord `json:"logRecords"`
+}
+
+type logRecord struct {
+	TimeUnixNano         jsonUint64 `json:"timeUnixNano"`
+	ObservedTimeUnixNano jsonUint64 `json:"observedTimeUnixNano"`
+	Body                 anyValue   `json:"body"`
+	Attributes           []keyValue `json:"attributes"`
+	EventName            string     `json:"eventName"`
+}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+type anyValue struct {
+	StringValue *string    `json:"stringValue"`
+	BoolValue   *bool      `json:"boolValue"`
+	IntValue    *jsonInt64 `json:"intValue"`
+	DoubleValue *float64   `json:"doubleValue"`
+	ArrayValue  *struct {
+		Values []anyValue `json:"values"`
+	} `json:"arrayValue"`
+	KvlistValue *struct {
+		Values []keyValue `json:"values"`
+	} `json:"kvlistValue"`
+	BytesValue []byte `json:"bytesValue"`
+}
+
+// String renders the value as a string: arrays and maps as JSON, bytes as
+// hex.
+func (v anyValue) String() string {
+	switch {
+	case v.StringValue != nil:
+		return *v.StringValue
+	case v.BoolValue != nil:
+		return strconv.FormatBool(*v.BoolValue)
+	case v.IntValue != nil:
+		return strconv.FormatInt(int64(*v.IntValue), 10)
+	case v.DoubleValue != nil:
+		return strconv.FormatFloat(*v.DoubleValue, 'f', -1, 64)
+	case v.ArrayValue != nil:
+		vals := make([]string, len(v.ArrayValue.Values))
+		for i, e := range v.ArrayValue.Values {
+			vals[i] = e.String()
+		}
+		b, _ := json.Marshal(vals)
+		return string(b)
+	case v.KvlistValue != nil:
+		m := attrMap(nil, v.KvlistValue.Values)
+		b, _ := json.Marshal(m)
+		return string(b)
+	case v.BytesValue != nil:
+		return hex.EncodeToString(v.BytesValue)
+	}
+	return ""
+}
+
+// attrMap adds attributes to m (allocating it if nil) and returns it.
+func attrMap(m map[string]string, attrs []keyValue) map[string]string {
+	if m == nil {
+		m = make(map[string]string, len(attrs))
+	}
+	for _, kv := range attrs {
+		m[kv.Key] = kv.Value.String()
+	}
+	return m
+}
+
+func unixNano(n jsonUint64) time.Time {
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, int64(n)).UTC()
+}
+
+// records flattens a metrics request into one record per data point.
+func (req *metricsRequest) records() []Record {
+	var out []Record
+	for _, rm := range req.ResourceMetrics {
+		for _, sm := range rm.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				data, cumulative := m.Gauge, false
+				if m.Sum != nil {
+					data, cumulative = m.Sum, m.Sum.AggregationTemporality == temporalityCumulative
+				}
+				if data == nil {
+					continue
+				}
+				for _, dp := range data.DataPoints {
+					r := Record{
+						Kind:       KindMetric,
+						Name:       m.Name,
+						Unit:       m.Unit,
+						Time:       unixNano(dp.TimeUnixNano),
+						Start:      unixNano(dp.StartTimeUnixNano),
+						Cumulative: cumulative,
+						Attrs:      attrMap(attrMap(nil, rm.Resource.Attributes), dp.Attributes),
+					}
+					switch {
+					case dp.AsDouble != nil:
+						r.Value = *dp.AsDouble
+					case dp.AsInt != nil:
+						r.Value = float64(*dp.AsInt)
+					}
+					out = append(out, r)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// records flattens a logs request into one event record per log record. The
+// event name comes from the record's event name, its event.name attribute,
+// or else its body.
+func (req *logsRequest) records() []Record {
+	var out []Record
+	for _, rl := range req.ResourceLogs {
+		for _, sl := range rl.ScopeLogs {
+			for _, lr := range sl.LogRecords {
+				r := Record{
+					Kind:  KindEvent,
+					Name:  lr.EventName,
+					Time:  unixNano(lr.TimeUnixNano),
+					Attrs: attrMap(attrMap(nil, rl.Resource.Attributes), lr.Attributes),
+				}
+				if r.Name == "" {
+					r.Name = r.Attrs["event.name"]
+				}
+				if r.Name == "" {
+					r.Name = strings.TrimSpace(lr.Body.String())
+				}
+				if r.Time.IsZero() {
+					if t, err := time.Parse(time.RFC3339Nano, r.Attrs["event.timestamp"]); err == nil {
+						r.Time = t.UTC()
+					} else {
+						r.Time = unixNano(lr.ObservedTimeUnixNano)
+					}
+				}
+				out = append(out, r)
+			}
+		}
+	}
+	return out
+}
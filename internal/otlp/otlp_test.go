@@ -0,0 +1,177 @@
+package otlp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// pb builds protobuf messages for the fake exporter.
+type pb []byte
+
+func (b pb) tag(field, wire int) pb {
+	return binary.AppendUvarint(b, uint64(field<<3|wire))
+}
+
+func (b pb) msg(field int, m pb) pb {
+	b = binary.AppendUvarint(b.tag(field, wireBytes), uint64(len(m)))
+	return append(b, m...)
+}
+
+func (b pb) str(field int, s string) pb { return b.msg(field, pb(s)) }
+
+func (b pb) varint(field int, v uint64) pb {
+	return binary.AppendUvarint(b.tag(field, wireVarint), v)
+}
+
+func (b pb) fixed64(field int, v uint64) pb {
+	return binary.LittleEndian.AppendUint64(b.tag(field, wireFixed64), v)
+}
+
+func pbAttr(key, value string) pb {
+	return pb{}.str(1, key).msg(2, pb{}.str(1, value))
+}
+
+var exportTime = time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC)
+
+// fakeMetricsProto is what Claude Code's exporter sends: a delta cost sum
+// and a delta token sum, with an unknown field thrown in.
+func fakeMetricsProto() []byte {
+	ts := uint64(exportTime.UnixNano())
+	costPoint := pb{}.fixed64(2, ts-60e9).fixed64(3, ts).
+		fixed64(4, math.Float64bits(0.42)).
+		msg(7, pbAttr("model", "claude-sonnet-4-5")).
+		msg(7, pbAttr("session.id", "sess-1"))
+	tokenPoint := pb{}.fixed64(3, ts).
+		fixed64(6, 1200).
+		msg(7, pbAttr("type", "input")).
+		msg(7, pbAttr("model", "claude-sonnet-4-5"))
+	metrics := pb{}.
+		msg(2, pb{}.str(1, "claude_code.cost.usage").str(3, "USD").
+			msg(7, pb{}.msg(1, costPoint).varint(2, temporalityDelta).varint(3, 1))).
+		msg(2, pb{}.str(1, "claude_code.token.usage").str(3, "tokens").
+			msg(7, pb{}.msg(1, tokenPoint).varint(2, temporalityDelta))).
+		str(99, "ignored")
+	return pb{}.msg(1, pb{}.
+		msg(1, pb{}.msg(1, pbAttr("service.name", "claude-code"))).
+		msg(2, append(pb{}.msg(1, pb{}.str(1, "com.anthropic.claude_code")), metrics...)))
+}
+
+func fakeLogsProto() []byte {
+	record := pb{}.fixed64(1, uint64(exportTime.UnixNano())).
+		msg(5, pb{}.str(1, "claude_code.api_request")).
+		msg(6, pbAttr("event.name", "api_request")).
+		msg(6, pbAttr("cost_usd", "0.0123")).
+		msg(6, pb{}.str(1, "duration_ms").msg(2, pb{}.varint(3, 2100)))
+	return pb{}.msg(1, pb{}.msg(2, pb{}.msg(2, record)))
+}
+
+const fakeMetricsJSON = `{"resourceMetrics":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"claude-code"}}]},
+ "scopeMetrics":[{"scope":{"name":"com.anthropic.claude_code"},"metrics":[
+  {"name":"claude_code.lines_of_code.count","unit":"count","sum":{"aggregationTemporality":"AGGREGATION_TEMPORALITY_CUMULATIVE","isMonotonic":true,
+   "dataPoints":[{"startTimeUnixNano":"1772615000000000000","timeUnixNano":"1772618400000000000","asInt":"57",
+    "attributes":[{"key":"type","value":{"stringValue":"added"}},{"key":"session.id","value":{"stringValue":"sess-2"}}]}]}},
+  {"name":"claude_code.active_time.total","unit":"s","gauge":{"dataPoints":[{"timeUnixNano":1772618400000000000,"asDouble":12.5}]}},
+  {"name":"claude_code.histogram","histogram":{"dataPoints":[]}}
+ ]}]}]}`
+
+func post(t *testing.T, url, contentType string, body []byte, gzipped bool) *http.Response {
+	t.Helper()
+	if gzipped {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write(body)
+		zw.Close()
+		body = buf.Bytes()
+	}
+	req, _ := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	if gzipped {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp
+}
+
+func TestReceiver(t *testing.T) {
+	dir := t.TempDir()
+	srv := httptest.NewServer(Handler(&Store{Dir: dir}))
+	defer srv.Close()
+
+	for _, c := range []struct {
+		path, contentType string
+		body              []byte
+		gzipped           bool
+	}{
+		{"/v1/metrics", "application/x-protobuf", fakeMetricsProto(), false},
+		{"/v1/logs", "application/x-protobuf", fakeLogsProto(), true},
+		{"/v1/metrics", "application/json; charset=utf-8", []byte(fakeMetricsJSON), true},
+		{"/v1/traces", "application/json", []byte(`{"resourceSpans":[]}`), false},
+	} {
+		if resp := post(t, srv.URL+c.path, c.contentType, c.body, c.gzipped); resp.StatusCode != http.StatusOK {
+			t.Fatalf("%s %s: status %d", c.path, c.contentType, resp.StatusCode)
+		}
+	}
+	if resp := post(t, srv.URL+"/v1/metrics", "text/plain", nil, false); resp.StatusCode != http.StatusUnsupportedMediaType {
+		t.Errorf("expected text/plain to be rejected, got %d", resp.StatusCode)
+	}
+	if resp := post(t, srv.URL+"/v1/metrics", "application/x-protobuf", []byte{0x0a, 0x05}, false); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected a truncated message to be rejected, got %d", resp.StatusCode)
+	}
+
+	records, err := ReadRecords(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 5 {
+		t.Fatalf("expected 5 records, got %d: %+v", len(records), records)
+	}
+
+	cost := records[0]
+	if cost.Name != "claude_code.cost.usage" || cost.Value != 0.42 || cost.Cumulative || !cost.Time.Equal(exportTime) {
+		t.Errorf("unexpected cost point %+v", cost)
+	}
+	if cost.Attrs["service.name"] != "claude-code" || cost.Attrs["session.id"] != "sess-1" || cost.Start.IsZero() {
+		t.Errorf("unexpected cost attributes %+v", cost)
+	}
+	if tokens := records[1]; tokens.Value != 1200 || tokens.Attrs["type"] != "input" {
+		t.Errorf("unexpected token point %+v", tokens)
+	}
+
+	event := records[2]
+	if event.Kind != KindEvent || event.Name != "api_request" || event.Attrs["cost_usd"] != "0.0123" || event.Attrs["duration_ms"] != "2100" {
+		t.Errorf("unexpected event %+v", event)
+	}
+
+	lines := records[3]
+	if !lines.Cumulative || lines.Value != 57 || lines.Attrs["type"] != "added" {
+		t.Errorf("unexpected cumulative point %+v", lines)
+	}
+	if active := records[4]; active.Name != "claude_code.active_time.total" || active.Value != 12.5 {
+		t.Errorf("unexpected gauge point %+v", active)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	if _, err := DecodeMetricsJSON([]byte(`{"resourceMetrics":[{"scopeMetrics":[{"metrics":[{"sum":{"aggregationTemporality":"SIDEWAYS"}}]}]}]}`)); err == nil {
+		t.Error("expected an unknown temporality to fail")
+	}
+	// Truncated messages may still parse at a field boundary, but must
+	// never panic.
+	msg := fakeMetricsProto()
+	for i := 1; i < len(msg); i++ {
+		DecodeMetricsProto(msg[:i])
+	}
+	if _, err := DecodeMetricsProto(msg[:len(msg)-1]); err == nil {
+		t.Error("expected a message cut mid-field to fail")
+	}
+}
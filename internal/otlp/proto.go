@@ -0,0 +1,383 @@
+package otlp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// A minimal protobuf wire-format decoder for the OTLP messages in otlp.go.
+// Unknown fields are skipped, as protobuf requires.
+
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var errTruncated = errors.New("otlp: truncated protobuf message")
+
+type protoReader struct {
+	b []byte
+}
+
+func (r *protoReader) done() bool { return len(r.b) == 0 }
+
+func (r *protoReader) varint() (uint64, error) {
+	v, n := binary.Uvarint(r.b)
+	if n <= 0 {
+		return 0, errTruncated
+	}
+	r.b = r.b[n:]
+	return v, nil
+}
+
+// field reads the next field's number and wire type.
+func (r *protoReader) field() (int, int, error) {
+	tag, err := r.varint()
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(tag >> 3), int(tag & 7), nil
+}
+
+func (r *protoReader) fixed64() (uint64, error) {
+	if len(r.b) < 8 {
+		return 0, errTruncated
+	}
+	v := binary.LittleEndian.Uint64(r.b)
+	r.b = r.b[8:]
+	return v, nil
+}
+
+func (r *protoReader) bytes() ([]byte, error) {
+	n, err := r.varint()
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(r.b)) < n {
+		return nil, errTruncated
+	}
+	b := r.b[:n]
+	r.b = r.b[n:]
+	return b, nil
+}
+
+func (r *protoReader) skip(wire int) error {
+	switch wire {
+	case wireVarint:
+		_, err := r.varint()
+		return err
+	case wireFixed64:
+		_, err := r.fixed64()
+		return err
+	case wireBytes:
+		_, err := r.bytes()
+		return err
+	case wireFixed32:
+		if len(r.b) < 4 {
+			return errTruncated
+		}
+		r.b = r.b[4:]
+		return nil
+	}
+	return fmt.Errorf("otlp: unsupported protobuf wire type %d", wire)
+}
+
+// decodeMessage calls fn for each field of the message in b. fn returns
+// false for fields it doesn't handle, which are then skipped.
+func decodeMessage(b []byte, fn func(r *protoReader, field, wire int) (bool, error)) error {
+	r := &protoReader{b: b}
+	for !r.done() {
+		field, wire, err := r.field()
+		if err != nil {
+			return err
+		}
+		handled, err := fn(r, field, wire)
+		if err != nil {
+			return err
+		}
+		if !handled {
+			if err := r.skip(wire); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// submessage reads a length-delimited field and decodes it with decode.
+func submessage[T any](r *protoReader, wire int, decode func([]byte, *T) error, dst *T) error {
+	if wire != wireBytes {
+		return fmt.Errorf("otlp: expected a message, got wire type %d", wire)
+	}
+	b, err := r.bytes()
+	if err != nil {
+		return err
+	}
+	return decode(b, dst)
+}
+
+// appendSubmessage decodes a repeated message field element onto list.
+func appendSubmessage[T any](r *protoReader, wire int, decode func([]byte, *T) error, list *[]T) error {
+	var v T
+	if err := submessage(r, wire, decode, &v); err != nil {
+		return err
+	}
+	*list = append(*list, v)
+	return nil
+}
+
+func (r *protoReader) string(wire int) (string, error) {
+	if wire != wireBytes {
+		return "", fmt.Errorf("otlp: expected a string, got wire type %d", wire)
+	}
+	b, err := r.bytes()
+	return string(b), err
+}
+
+// DecodeMetricsProto decodes a protobuf ExportMetricsServiceRequest.
+func DecodeMetricsProto(b []byte) ([]Record, error) {
+	var req metricsRequest
+	err := decodeMessage(b, func(r *protoReader, field, wire int) (bool, error) {
+		if field == 1 {
+			return true, appendSubmessage(r, wire, decodeResourceMetrics, &req.ResourceMetrics)
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("otlp: decoding metrics: %w", err)
+	}
+	return req.records(), nil
+}
+
+// DecodeLogsProto decodes a protobuf ExportLogsServiceRequest.
+func DecodeLogsProto(b []byte) ([]Record, error) {
+	var req logsRequest
+	err := decodeMessage(b, func(r *protoReader, field, wire int) (bool, error) {
+		if field == 1 {
+			return true, appendSubmessage(r, wire, decodeResourceLogs, &req.ResourceLogs)
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("otlp: decoding logs: %w", err)
+	}
+	return req.records(), nil
+}
+
+func decodeResourceMetrics(b []byte, rm *resourceMetrics) error {
+	return decodeMessage(b, func(r *protoReader, field, wire int) (bool, error) {
+		switch field {
+		case 1:
+			return true, submessage(r, wire, decodeResource, &rm.Resource)
+		case 2:
+			return true, appendSubmessage(r, wire, decodeScopeMetrics, &rm.ScopeMetrics)
+		}
+		return false, nil
+	})
+}
+
+func decodeResource(b []byte, res *resource) error {
+	return decodeMessage(b, func(r *protoReader, field, wire int) (bool, error) {
+		if field == 1 {
+			return true, appendSubmessage(r, wire, decodeKeyValue, &res.Attributes)
+		}
+		return false, nil
+	})
+}
+
+func decodeScopeMetrics(b []byte, sm *scopeMetrics) error {
+	return decodeMessage(b, func(r *protoReader, field, wire int) (bool, error) {
+		if field == 2 {
+			return true, appendSubmessage(r, wire, decodeMetric, &sm.Metrics)
+		}
+		return false, nil
+	})
+}
+
+func decodeMetric(b []byte, m *metric) error {
+	return decodeMessage(b, func(r *protoReader, field, wire int) (bool, error) {
+		var err error
+		switch field {
+		case 1:
+			m.Name, err = r.string(wire)
+		case 3:
+			m.Unit, err = r.string(wire)
+		case 5:
+			m.Gauge = &numberData{}
+			err = submessage(r, wire, decodeNumberData, m.Gauge)
+		case 7:
+			m.Sum = &numberData{}
+			err = submessage(r, wire, decodeNumberData, m.Sum)
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+}
+
+// decodeNumberData decodes a Gauge or a Sum; a gauge has no field 2.
+func decodeNumberData(b []byte, d *numberData) error {
+	return decodeMessage(b, func(r *protoReader, field, wire int) (bool, error) {
+		switch {
+		case field == 1:
+			return true, appendSubmessage(r, wire, decodeNumberDataPoint, &d.DataPoints)
+		case field == 2 && wire == wireVarint:
+			v, err := r.varint()
+			d.AggregationTemporality = temporality(v)
+			return true, err
+		}
+		return false, nil
+	})
+}
+
+func decodeNumberDataPoint(b []byte, dp *numberDataPoint) error {
+	return decodeMessage(b, func(r *protoReader, field, wire int) (bool, error) {
+		if wire == wireFixed64 {
+			v, err := r.fixed64()
+			if err != nil {
+				return true, err
+			}
+			switch field {
+			case 2:
+				dp.StartTimeUnixNano = jsonUint64(v)
+			case 3:
+				dp.TimeUnixNano = jsonUint64(v)
+			case 4:
+				f := math.Float64frombits(v)
+				dp.AsDouble = &f
+			case 6:
+				n := jsonInt64(v)
+				dp.AsInt = &n
+			}
+			return true, nil
+		}
+		if field == 7 {
+			return true, appendSubmessage(r, wire, decodeKeyValue, &dp.Attributes)
+		}
+		return false, nil
+	})
+}
+
+func decodeResourceLogs(b []byte, rl *resourceLogs) error {
+	return decodeMessage(b, func(r *protoReader, field, wire int) (bool, error) {
+		switch field {
+		case 1:
+			return true, submessage(r, wire, decodeResource, &rl.Resource)
+		case 2:
+			return true, appendSubmessage(r, wire, decodeScopeLogs, &rl.ScopeLogs)
+		}
+		return false, nil
+	})
+}
+
+func decodeScopeLogs(b []byte, sl *scopeLogs) error {
+	return decodeMessage(b, func(r *protoReader, field, wire int) (bool, error) {
+		if field == 2 {
+			return true, appendSubmessage(r, wire, decodeLogRecord, &sl.LogRecords)
+		}
+		return false, nil
+	})
+}
+
+func decodeLogRecord(b []byte, lr *logRecord) error {
+	return decodeMessage(b, func(r *protoReader, field, wire int) (bool, error) {
+		var err error
+		switch {
+		case (field == 1 || field == 11) && wire == wireFixed64:
+			var v uint64
+			v, err = r.fixed64()
+			if field == 1 {
+				lr.TimeUnixNano = jsonUint64(v)
+			} else {
+				lr.ObservedTimeUnixNano = jsonUint64(v)
+			}
+		case field == 5:
+			err = submessage(r, wire, decodeAnyValue, &lr.Body)
+		case field == 6:
+			err = appendSubmessage(r, wire, decodeKeyValue, &lr.Attributes)
+		case field == 12:
+			lr.EventName, err = r.string(wire)
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+}
+
+func decodeKeyValue(b []byte, kv *keyValue) error {
+	return decodeMessage(b, func(r *protoReader, field, wire int) (bool, error) {
+		var err error
+		switch field {
+		case 1:
+			kv.Key, err = r.string(wire)
+		case 2:
+			err = submessage(r, wire, decodeAnyValue, &kv.Value)
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+}
+
+func decodeAnyValue(b []byte, v *anyValue) error {
+	return decodeMessage(b, func(r *protoReader, field, wire int) (bool, error) {
+		var err error
+		switch {
+		case field == 1:
+			var s string
+			s, err = r.string(wire)
+			v.StringValue = &s
+		case field == 2 && wire == wireVarint:
+			var n uint64
+			n, err = r.varint()
+			bv := n != 0
+			v.BoolValue = &bv
+		case field == 3 && wire == wireVarint:
+			var n uint64
+			n, err = r.varint()
+			iv := jsonInt64(int64(n))
+			v.IntValue = &iv
+		case field == 4 && wire == wireFixed64:
+			var n uint64
+			n, err = r.fixed64()
+			f := math.Float64frombits(n)
+			v.DoubleValue = &f
+		case field == 5:
+			v.ArrayValue = &struct {
+				Values []anyValue `json:"values"`
+			}{}
+			err = submessage(r, wire, func(b []byte, dst *[]anyValue) error {
+				return decodeMessage(b, func(r *protoReader, field, wire int) (bool, error) {
+					if field == 1 {
+						return true, appendSubmessage(r, wire, decodeAnyValue, dst)
+					}
+					return false, nil
+				})
+			}, &v.ArrayValue.Values)
+		case field == 6:
+			v.KvlistValue = &struct {
+				Values []keyValue `json:"values"`
+			}{}
+			err = submessage(r, wire, func(b []byte, dst *[]keyValue) error {
+				return decodeMessage(b, func(r *protoReader, field, wire int) (bool, error) {
+					if field == 1 {
+						return true, appendSubmessage(r, wire, decodeKeyValue, dst)
+					}
+					return false, nil
+				})
+			}, &v.KvlistValue.Values)
+		case field == 7 && wire == wireBytes:
+			v.BytesValue, err = r.bytes()
+			if v.BytesValue == nil {
+				v.BytesValue = []byte{}
+			}
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+}
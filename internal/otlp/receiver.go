@@ -0,0 +1,95 @@
+package otlp
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+)
+
+// maxRequestSize bounds an export request's (decompressed) body.
+const maxRequestSize = 16 << 20
+
+// Handler returns an OTLP/HTTP receiver that stores metrics and log events
+// in store. It serves /v1/metrics and /v1/logs, accepting JSON and protobuf
+// bodies, optionally gzip-compressed, and acknowledges /v1/traces without
+// storing anything.
+func Handler(store *Store) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/metrics", receive(store, DecodeMetricsJSON, DecodeMetricsProto))
+	mux.HandleFunc("/v1/logs", receive(store, DecodeLogsJSON, DecodeLogsProto))
+	mux.HandleFunc("/v1/traces", receive(nil, nil, nil))
+	return mux
+}
+
+type decodeFunc func([]byte) ([]Record, error)
+
+func receive(store *Store, decodeJSON, decodeProto decodeFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		isJSON := mediaType == "application/json"
+		if !isJSON && mediaType != "application/x-protobuf" {
+			http.Error(w, "unsupported content type", http.StatusUnsupportedMediaType)
+			return
+		}
+
+		body := io.Reader(r.Body)
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			defer zr.Close()
+			body = zr
+		}
+		raw, err := io.ReadAll(io.LimitReader(body, maxRequestSize+1))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(raw) > maxRequestSize {
+			http.Error(w, "request too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		if store != nil {
+			decode := decodeProto
+			if isJSON {
+				decode = decodeJSON
+			}
+			records, err := decode(raw)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			// Points without a timestamp are stamped on arrival.
+			now := time.Now().UTC()
+			for i := range records {
+				if records[i].Time.IsZero() {
+					records[i].Time = now
+				}
+			}
+			if err := store.Append(records); err != nil {
+				http.Error(w, fmt.Sprintf("storing telemetry: %v", err), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		// An empty Export*ServiceResponse means everything was accepted.
+		if isJSON {
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, "{}")
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		w.WriteHeader(http.StatusOK)
+	}
+}
@@ -35,8 +35,10 @@ func ParseSessionFile(path, project string) (*model.Session, error) {
 			session.ID = msg.SessionID
 		}
 
+		var ts time.Time
 		if msg.Timestamp != "" {
-			if ts, err := time.Parse(time.RFC3339, msg.Timestamp); err == nil {
+			if t, err := time.Parse(time.RFC3339, msg.Timestamp); err == nil {
+				ts = t
 				if session.StartTime.IsZero() || ts.Before(session.StartTime) {
 					session.StartTime = ts
 				}
@@ -63,11 +65,23 @@ func ParseSessionFile(path, project string) (*model.Session, error) {
 			session.TokenUsage.CacheRead += u.CacheReadInputTokens
 			session.TokenUsage.CacheWrite += u.CacheCreationInputTokens
 
+			turn := model.TokenUsage{
+				InputTokens:  u.InputTokens,
+				OutputTokens: u.OutputTokens,
+				CacheRead:    u.CacheReadInputTokens,
+				CacheWrite:   u.CacheCreationInputTokens,
+			}
+			session.Turns = append(session.Turns, model.MessageUsage{
+				Timestamp: ts,
+				Model:     modelName,
+				Usage:     turn,
+			})
+
 			mu := session.Models[modelName]
-			mu.InputTokens += u.InputTokens
-			mu.OutputTokens += u.OutputTokens
-			mu.CacheRead += u.CacheReadInputTokens
-			mu.CacheWrite += u.CacheCreationInputTokens
+			mu.InputTokens += turn.InputTokens
+			mu.OutputTokens += turn.OutputTokens
+			mu.CacheRead += turn.CacheRead
+			mu.CacheWrite += turn.CacheWrite
 			session.Models[modelName] = mu
 		}
 	}
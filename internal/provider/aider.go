@@ -0,0 +1,477 @@
+package provider
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/isaacaudet/aitop/internal/model"
+)
+
+const (
+	aiderHistoryFile = ".aider.chat.history.md"
+	aiderConfFile    = ".aider.conf.yml"
+)
+
+// Aider implements Provider for Aider. It reads the .aider.chat.history.md
+// each repo accumulates and, when analytics logging is enabled, the JSONL
+// analytics log whose message_send events carry exact tokens and cost.
+type Aider struct {
+	HistoryFiles   []string // Chat history files read in addition to those under WorkspaceRoots
+	WorkspaceRoots []string // Searched for git repos containing a chat history
+	AnalyticsPaths []string // Analytics logs in addition to analytics-log in .aider.conf.yml
+}
+
+// NewAider returns an Aider provider. paths may name chat history files or
+// the repos containing them; ~/.aider.chat.history.md is always included.
+func NewAider(paths, workspaceRoots, analyticsPaths []string) *Aider {
+	a := &Aider{WorkspaceRoots: workspaceRoots, AnalyticsPaths: analyticsPaths}
+	if home, err := os.UserHomeDir(); err == nil {
+		a.HistoryFiles = append(a.HistoryFiles, filepath.Join(home, aiderHistoryFile))
+	}
+	for _, p := range paths {
+		if info, err := os.Stat(p); err == nil && info.IsDir() {
+			p = filepath.Join(p, aiderHistoryFile)
+		}
+		a.HistoryFiles = append(a.HistoryFiles, p)
+	}
+	return a
+}
+
+func (a *Aider) Name() string  { return "Aider" }
+func (a *Aider) Icon() string  { return "▲" }
+func (a *Aider) Color() string { return "#94e2d5" } // Teal
+
+func (a *Aider) Available() bool {
+	histories, analytics := a.discover()
+	return len(histories) > 0 || len(analytics) > 0
+}
+
+// aiderSession is one "# aider chat started at" section of a chat history,
+// or one launch recorded in the analytics log.
+type aiderSession struct {
+	project      string
+	start        time.Time
+	end          time.Time
+	userMessages int
+	prompts      []string
+	turns        []aiderTurn
+}
+
+// aiderTurn is a single LLM call.
+type aiderTurn struct {
+	timestamp  time.Time // Zero for chat history turns, which aren't timed
+	model      string
+	input      int
+	output     int
+	cacheRead  int
+	cacheWrite int
+	cost       float64 // As reported by Aider
+	hasCost    bool
+}
+
+// aiderEvent is a line of Aider's analytics log (--analytics-log).
+type aiderEvent struct {
+	Event      string `json:"event"`
+	Time       int64  `json:"time"`
+	Properties struct {
+		MainModel        string   `json:"main_model"`
+		PromptTokens     int      `json:"prompt_tokens"`
+		CompletionTokens int      `json:"completion_tokens"`
+		Cost             *float64 `json:"cost"`
+	} `json:"properties"`
+}
+
+// aiderMatchWindow is how far apart a chat history's start and an analytics
+// launch event may be and still be treated as the same session.
+const aiderMatchWindow = 2 * time.Minute
+
+func (a *Aider) Load() (*ProviderData, error) {
+	data := &ProviderData{
+		ProviderName: a.Name(),
+		Icon:         a.Icon(),
+		Color:        a.Color(),
+		Metadata:     make(map[string]string),
+	}
+
+	historyFiles, analyticsFiles := a.discover()
+	if len(historyFiles) == 0 && len(analyticsFiles) == 0 {
+		return nil, fmt.Errorf("no aider chat history or analytics log found")
+	}
+
+	var sessions []*aiderSession
+	for _, path := range historyFiles {
+		parsed, err := parseAiderHistory(path)
+		if err != nil {
+			continue
+		}
+		sessions = append(sessions, parsed...)
+	}
+	var launches []*aiderSession
+	for _, path := range analyticsFiles {
+		parsed, err := parseAiderAnalytics(path)
+		if err != nil {
+			continue
+		}
+		launches = append(launches, parsed...)
+	}
+	sessions = mergeAiderAnalytics(sessions, launches)
+
+	totals := newUsageTotals()
+
+	var promptLengths []int
+	for _, s := range sessions {
+		// Sessions have no id of their own; the project and start time identify one.
+		si := SessionInfo{
+			ID:           "aider@" + s.start.Format(time.RFC3339),
+			Project:      s.project,
+			StartTime:    s.start,
+			EndTime:      s.end,
+			UserMessages: s.userMessages,
+			Messages:     s.userMessages + len(s.turns),
+			Prompts:      len(s.prompts),
+		}
+		if s.project != "" {
+			si.ID = s.project + "@" + s.start.Format(time.RFC3339)
+		}
+		if len(s.prompts) > 0 {
+			si.Title = promptTitle(s.prompts[0])
+		}
+
+		for _, t := range s.turns {
+			name := bareModelName(t.model)
+			cost := t.cost
+			if !t.hasCost {
+				cost = model.CalculateCost(name, model.TokenUsage{
+					InputTokens:  t.input,
+					OutputTokens: t.output,
+					CacheRead:    t.cacheRead,
+					CacheWrite:   t.cacheWrite,
+				})
+			}
+			tokens := t.input + t.output + t.cacheRead + t.cacheWrite
+
+			si.Tokens += tokens
+			si.Cost += cost
+			si.Model = name
+			si.Turns = append(si.Turns, TurnUsage{
+				Timestamp:    t.timestamp,
+				Model:        name,
+				InputTokens:  t.input,
+				OutputTokens: t.output,
+				CacheRead:    t.cacheRead,
+				CacheWrite:   t.cacheWrite,
+				Cost:         cost,
+			})
+
+			mb := totals.model(name)
+			mb.InputTokens += t.input
+			mb.OutputTokens += t.output
+			mb.CacheRead += t.cacheRead
+			mb.CacheWrite += t.cacheWrite
+			mb.Cost += cost
+			mb.Requests++
+
+			// Untimed turns count towards the day the session started.
+			day := t.timestamp
+			if day.IsZero() {
+				day = s.start
+			}
+			du := totals.day(day)
+			du.Cost += cost
+			du.Tokens += tokens
+		}
+
+		du := totals.day(s.start)
+		du.Sessions++
+		du.Messages += si.Messages
+		du.Prompts += len(s.prompts)
+		for _, p := range s.prompts {
+			promptLengths = append(promptLengths, utf8.RuneCountInString(p))
+		}
+
+		data.TotalCost += si.Cost
+		data.Sessions = append(data.Sessions, si)
+		totals.span(s.start, s.end)
+	}
+	if len(promptLengths) > 0 {
+		data.Prompts = newPromptStats(promptLengths, len(sessions))
+	}
+
+	totals.fill(data)
+
+	return data, nil
+}
+
+// discover returns the chat history files and analytics logs that exist,
+// including those found in repos under the workspace roots and analytics-log
+// settings in ~/.aider.conf.yml and each repo's .aider.conf.yml.
+func (a *Aider) discover() (histories, analytics []string) {
+	seen := make(map[string]bool)
+	add := func(list *[]string, path string) {
+		if path == "" || seen[path] {
+			return
+		}
+		if _, err := os.Stat(path); err != nil {
+			return
+		}
+		seen[path] = true
+		*list = append(*list, path)
+	}
+
+	dirs := []string{}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, home)
+	}
+	for _, p := range a.HistoryFiles {
+		add(&histories, p)
+		dirs = append(dirs, filepath.Dir(p))
+	}
+	for _, root := range a.WorkspaceRoots {
+		for _, repo := range findGitRepos(root, 3) {
+			add(&histories, filepath.Join(repo, aiderHistoryFile))
+			dirs = append(dirs, repo)
+		}
+	}
+
+	for _, p := range a.AnalyticsPaths {
+		add(&analytics, p)
+	}
+	for _, dir := range dirs {
+		if p := aiderAnalyticsSetting(filepath.Join(dir, aiderConfFile)); p != "" {
+			if !filepath.IsAbs(p) {
+				p = filepath.Join(dir, p)
+			}
+			add(&analytics, p)
+		}
+	}
+	return histories, analytics
+}
+
+// aiderAnalyticsSetting reads the analytics-log key from an .aider.conf.yml.
+func aiderAnalyticsSetting(path string) string {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(raw), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok || strings.TrimSpace(key) != "analytics-log" {
+			continue
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+		if strings.HasPrefix(value, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				value = filepath.Join(home, value[2:])
+			}
+		}
+		return value
+	}
+	return ""
+}
+
+var (
+	aiderStartRe  = regexp.MustCompile(`^# aider chat started at (\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2})`)
+	aiderModelRe  = regexp.MustCompile(`^> (?:Main model|Models?): (\S+)`)
+	aiderTokensRe = regexp.MustCompile(`^> Tokens: (.+?)\.(?: Cost: \$([\d.,]+) message.*)?$`)
+)
+
+// parseAiderHistory splits a chat history into sessions. User prompts are
+// "#### " lines; each "> Tokens:" line reports one LLM call.
+func parseAiderHistory(path string) ([]*aiderSession, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	project := filepath.Dir(path)
+	var sessions []*aiderSession
+	var cur *aiderSession
+	var currentModel string
+	var prompt []string
+
+	flushPrompt := func() {
+		if cur != nil && len(prompt) > 0 {
+			cur.userMessages++
+			cur.prompts = append(cur.prompts, strings.Join(prompt, "\n"))
+		}
+		prompt = nil
+	}
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 1024*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if m := aiderStartRe.FindStringSubmatch(line); m != nil {
+			flushPrompt()
+			start, err := time.ParseInLocation("2006-01-02 15:04:05", m[1], time.Local)
+			if err != nil {
+				cur = nil
+				continue
+			}
+			cur = &aiderSession{project: project, start: start, end: start}
+			sessions = append(sessions, cur)
+			currentModel = ""
+			continue
+		}
+		if cur == nil {
+			continue
+		}
+
+		if strings.HasPrefix(line, "#### ") {
+			prompt = append(prompt, strings.TrimPrefix(line, "#### "))
+			continue
+		}
+		flushPrompt()
+
+		if m := aiderModelRe.FindStringSubmatch(line); m != nil {
+			currentModel = m[1]
+			continue
+		}
+		if m := aiderTokensRe.FindStringSubmatch(line); m != nil {
+			t := aiderTurn{model: currentModel}
+			for _, part := range strings.Split(m[1], ", ") {
+				part = strings.TrimSpace(part)
+				num, label, _ := strings.Cut(part, " ")
+				n := parseAiderCount(num)
+				switch label {
+				case "sent":
+					t.input = n
+				case "received":
+					t.output = n
+				case "cache hit":
+					t.cacheRead = n
+				case "cache write":
+					t.cacheWrite = n
+				}
+			}
+			if m[2] != "" {
+				if cost, err := strconv.ParseFloat(strings.ReplaceAll(m[2], ",", ""), 64); err == nil {
+					t.cost = cost
+					t.hasCost = true
+				}
+			}
+			cur.turns = append(cur.turns, t)
+		}
+	}
+	flushPrompt()
+	return sessions, scanner.Err()
+}
+
+// parseAiderCount parses Aider's abbreviated token counts: "345", "2,345",
+// "4.2k" or "1.1M".
+func parseAiderCount(s string) int {
+	s = strings.ReplaceAll(s, ",", "")
+	mult := 1.0
+	switch {
+	case strings.HasSuffix(s, "k"):
+		mult, s = 1_000, strings.TrimSuffix(s, "k")
+	case strings.HasSuffix(s, "M"):
+		mult, s = 1_000_000, strings.TrimSuffix(s, "M")
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return int(v*mult + 0.5)
+}
+
+// parseAiderAnalytics groups an analytics log's message_send events into one
+// session per launched event.
+func parseAiderAnalytics(path string) ([]*aiderSession, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var sessions []*aiderSession
+	var cur *aiderSession
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 1024*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var ev aiderEvent
+		if json.Unmarshal(scanner.Bytes(), &ev) != nil || ev.Time == 0 {
+			continue
+		}
+		ts := time.Unix(ev.Time, 0)
+		switch ev.Event {
+		case "launched":
+			cur = &aiderSession{start: ts, end: ts}
+			sessions = append(sessions, cur)
+		case "message_send":
+			if cur == nil {
+				cur = &aiderSession{start: ts, end: ts}
+				sessions = append(sessions, cur)
+			}
+			t := aiderTurn{
+				timestamp: ts,
+				model:     ev.Properties.MainModel,
+				input:     ev.Properties.PromptTokens,
+				output:    ev.Properties.CompletionTokens,
+			}
+			if ev.Properties.Cost != nil {
+				t.cost = *ev.Properties.Cost
+				t.hasCost = true
+			}
+			cur.turns = append(cur.turns, t)
+			cur.end = ts
+		}
+	}
+
+	// Launches that never sent a message carry no usage.
+	kept := sessions[:0]
+	for _, s := range sessions {
+		if len(s.turns) > 0 {
+			kept = append(kept, s)
+		}
+	}
+	return kept, scanner.Err()
+}
+
+// mergeAiderAnalytics pairs analytics sessions with the chat history session
+// that started at the same time. Paired sessions keep the history's project
+// and prompts but take the analytics log's exact tokens and costs; analytics
+// sessions without a chat history are kept on their own.
+func mergeAiderAnalytics(histories, launches []*aiderSession) []*aiderSession {
+	used := make(map[*aiderSession]bool)
+	for _, l := range launches {
+		var best *aiderSession
+		var bestDiff time.Duration
+		for _, h := range histories {
+			if used[h] {
+				continue
+			}
+			diff := h.start.Sub(l.start)
+			if diff < 0 {
+				diff = -diff
+			}
+			if diff <= aiderMatchWindow && (best == nil || diff < bestDiff) {
+				best, bestDiff = h, diff
+			}
+		}
+		if best == nil {
+			l.userMessages = len(l.turns)
+			histories = append(histories, l)
+			used[l] = true
+			continue
+		}
+		used[best] = true
+		best.turns = l.turns
+		if l.end.After(best.end) {
+			best.end = l.end
+		}
+	}
+	sort.Slice(histories, func(i, j int) bool { return histories[i].start.Before(histories[j].start) })
+	return histories
+}
@@ -0,0 +1,104 @@
+package provider
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAiderHistory(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	a := &Aider{HistoryFiles: []string{"../../testdata/aider/webapp/.aider.chat.history.md"}}
+	data, err := a.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(data.Sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(data.Sessions))
+	}
+	s := data.Sessions[0]
+	if filepath.Base(s.Project) != "webapp" {
+		t.Errorf("expected project webapp, got %q", s.Project)
+	}
+	if s.Title != "add retries to the http client with exponential backoff" {
+		t.Errorf("unexpected title %q", s.Title)
+	}
+	if s.UserMessages != 2 || len(s.Turns) != 2 {
+		t.Errorf("expected 2 prompts and 2 turns, got %d and %d", s.UserMessages, len(s.Turns))
+	}
+	// Aider's own cost figures are used as reported.
+	if math.Abs(s.Cost-0.05) > 1e-9 {
+		t.Errorf("expected reported cost $0.05, got $%.4f", s.Cost)
+	}
+	first := s.Turns[0]
+	if first.Model != "claude-sonnet-4-5-20250929" || first.InputTokens != 4200 || first.CacheWrite != 1100 || first.OutputTokens != 350 {
+		t.Errorf("unexpected first turn %+v", first)
+	}
+	if s.Turns[1].InputTokens != 5120 || s.Turns[1].CacheRead != 4200 {
+		t.Errorf("unexpected second turn %+v", s.Turns[1])
+	}
+	// The history doesn't say when each call was made.
+	if !first.Timestamp.IsZero() {
+		t.Errorf("expected an untimed history turn, got %v", first.Timestamp)
+	}
+
+	// The second session reports no cost, so it is priced from the table.
+	s2 := data.Sessions[1]
+	if s2.Model != "gpt-4.1" || s2.Cost <= 0 {
+		t.Errorf("expected a priced gpt-4.1 session, got %s at $%.4f", s2.Model, s2.Cost)
+	}
+	if data.Prompts == nil || data.Prompts.Total != 3 {
+		t.Errorf("expected 3 prompts, got %+v", data.Prompts)
+	}
+}
+
+func TestAiderAnalyticsMerge(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	// The chat history's start time is local; the analytics log uses epoch seconds.
+	start := time.Date(2026, 2, 7, 10, 0, 0, 0, time.Local).Unix()
+	log := fmt.Sprintf(`{"event": "launched", "properties": {}, "time": %d}
+{"event": "message_send", "properties": {"main_model": "anthropic/claude-sonnet-4-5-20250929", "prompt_tokens": 4213, "completion_tokens": 352, "cost": 0.0213}, "time": %d}
+{"event": "message_send", "properties": {"main_model": "anthropic/claude-sonnet-4-5-20250929", "prompt_tokens": 9330, "completion_tokens": 801, "cost": 0.0305}, "time": %d}
+{"event": "launched", "properties": {}, "time": %d}
+{"event": "message_send", "properties": {"main_model": "deepseek/deepseek-chat", "prompt_tokens": 1000, "completion_tokens": 100, "cost": 0.0004}, "time": %d}
+`, start+2, start+30, start+90, start+86400*3, start+86400*3+10)
+	logPath := filepath.Join(t.TempDir(), "analytics.jsonl")
+	if err := os.WriteFile(logPath, []byte(log), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	a := &Aider{
+		HistoryFiles:   []string{"../../testdata/aider/webapp/.aider.chat.history.md"},
+		AnalyticsPaths: []string{logPath},
+	}
+	data, err := a.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Sessions) != 3 {
+		t.Fatalf("expected 3 sessions, got %d", len(data.Sessions))
+	}
+
+	// The first history session takes the analytics log's exact figures.
+	s := data.Sessions[0]
+	if filepath.Base(s.Project) != "webapp" || s.UserMessages != 2 {
+		t.Errorf("expected history project and prompts, got %q with %d", s.Project, s.UserMessages)
+	}
+	if s.Tokens != 4213+352+9330+801 || math.Abs(s.Cost-0.0518) > 1e-9 {
+		t.Errorf("expected analytics tokens and cost, got %d and $%.4f", s.Tokens, s.Cost)
+	}
+	if len(s.Turns) != 2 || !s.Turns[1].Timestamp.Equal(time.Unix(start+90, 0)) {
+		t.Errorf("expected turns timed by the analytics log, got %+v", s.Turns)
+	}
+
+	// The unmatched launch becomes a session of its own.
+	last := data.Sessions[2]
+	if last.Project != "" || last.Model != "deepseek-chat" || last.Tokens != 1100 {
+		t.Errorf("unexpected analytics-only session %+v", last)
+	}
+}
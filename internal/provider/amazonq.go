@@ -0,0 +1,396 @@
+package provider
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/isaacaudet/aitop/internal/model"
+)
+
+// AmazonQ implements Provider for the Amazon Q Developer CLI. It keeps each
+// working directory's conversation as JSON in data.sqlite3: older versions in
+// conversations (keyed by directory), newer ones in conversations_v2 (keyed
+// by directory and conversation id).
+//
+// Amazon Q doesn't record token counts, so they are estimated from message
+// sizes and the resulting sessions and models are marked Estimated.
+type AmazonQ struct {
+	DataDirs []string // Directories containing data.sqlite3
+}
+
+func NewAmazonQ(dataDirs []string) *AmazonQ {
+	return &AmazonQ{DataDirs: dataDirs}
+}
+
+func (q *AmazonQ) Name() string  { return "Amazon Q" }
+func (q *AmazonQ) Icon() string  { return "◭" }
+func (q *AmazonQ) Color() string { return "#f2cdcd" } // Flamingo
+
+func (q *AmazonQ) Available() bool {
+	for _, dir := range q.DataDirs {
+		if _, err := os.Stat(filepath.Join(dir, "data.sqlite3")); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// amazonQCharsPerToken is the ratio used to estimate tokens from text.
+const amazonQCharsPerToken = 4
+
+// amazonQConversation is a stored conversation. Older versions record the
+// model as a bare id in model; newer ones use model_info.
+type amazonQConversation struct {
+	ConversationID string            `json:"conversation_id"`
+	History        []json.RawMessage `json:"history"`
+	Model          string            `json:"model"`
+	ModelInfo      *struct {
+		ModelID string `json:"model_id"`
+	} `json:"model_info"`
+}
+
+// amazonQHistoryEntry is one exchange in a conversation's history. Older
+// versions store a [user, assistant] pair instead of this object.
+type amazonQHistoryEntry struct {
+	User            json.RawMessage `json:"user"`
+	Assistant       json.RawMessage `json:"assistant"`
+	RequestMetadata *struct {
+		ModelID               string `json:"model_id"`
+		RequestStartTimestamp int64  `json:"request_start_timestamp_ms"`
+		StreamEndTimestamp    int64  `json:"stream_end_timestamp_ms"`
+	} `json:"request_metadata"`
+}
+
+// amazonQUserMessage is the user side of an exchange: a typed prompt or the
+// results of tools the assistant ran.
+type amazonQUserMessage struct {
+	Content struct {
+		Prompt *struct {
+			Prompt string `json:"prompt"`
+		} `json:"Prompt"`
+		ToolUseResults *struct {
+			ToolUseResults []struct {
+				Content []struct {
+					Text string          `json:"Text"`
+					JSON json.RawMessage `json:"Json"`
+				} `json:"content"`
+			} `json:"tool_use_results"`
+		} `json:"ToolUseResults"`
+	} `json:"content"`
+	Timestamp string `json:"timestamp"`
+}
+
+// chars is the length of the text sent to the model: the prompt, or the
+// output of the tools.
+func (u amazonQUserMessage) chars() int {
+	n := 0
+	if p := u.Content.Prompt; p != nil {
+		n += utf8.RuneCountInString(p.Prompt)
+	}
+	if r := u.Content.ToolUseResults; r != nil {
+		for _, result := range r.ToolUseResults {
+			for _, c := range result.Content {
+				n += utf8.RuneCountInString(c.Text) + utf8.RuneCount(c.JSON)
+			}
+		}
+	}
+	return n
+}
+
+// amazonQAssistantMessage is the assistant side of an exchange: a plain
+// response, or text along with the tools it asked to run.
+type amazonQAssistantMessage struct {
+	Response *struct {
+		Content string `json:"content"`
+	} `json:"Response"`
+	ToolUse *struct {
+		Content  string `json:"content"`
+		ToolUses []struct {
+			Name string          `json:"name"`
+			Args json.RawMessage `json:"args"`
+		} `json:"tool_uses"`
+	} `json:"ToolUse"`
+}
+
+// chars is the length of what the model wrote, including tool calls.
+func (a amazonQAssistantMessage) chars() int {
+	n := 0
+	if r := a.Response; r != nil {
+		n += utf8.RuneCountInString(r.Content)
+	}
+	if t := a.ToolUse; t != nil {
+		n += utf8.RuneCountInString(t.Content)
+		for _, use := range t.ToolUses {
+			n += utf8.RuneCountInString(use.Name) + utf8.RuneCount(use.Args)
+		}
+	}
+	return n
+}
+
+// amazonQRow is a conversation row with the directory it was keyed by.
+type amazonQRow struct {
+	cwd       string
+	value     string
+	updatedAt int64 // Unix ms; the database's mtime for the original table
+}
+
+func (q *AmazonQ) Load() (*ProviderData, error) {
+	data := &ProviderData{
+		ProviderName: q.Name(),
+		Icon:         q.Icon(),
+		Color:        q.Color(),
+		Metadata:     make(map[string]string),
+	}
+
+	var rows []amazonQRow
+	var lastErr error
+	loaded := 0
+	for _, dir := range q.DataDirs {
+		path := filepath.Join(dir, "data.sqlite3")
+		if _, err := os.Stat(path); err != nil {
+			lastErr = err
+			continue
+		}
+		rs, err := loadAmazonQConversations(path)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		loaded++
+		rows = append(rows, rs...)
+	}
+	if loaded == 0 {
+		if lastErr == nil {
+			lastErr = fmt.Errorf("no amazon q database found")
+		}
+		return nil, lastErr
+	}
+
+	totals := newUsageTotals()
+
+	seen := make(map[string]bool)
+	var promptLengths []int
+	for _, row := range rows {
+		var conv amazonQConversation
+		if json.Unmarshal([]byte(row.value), &conv) != nil || len(conv.History) == 0 {
+			continue
+		}
+		if conv.ConversationID == "" {
+			conv.ConversationID = row.cwd
+		}
+		if seen[conv.ConversationID] {
+			continue
+		}
+		seen[conv.ConversationID] = true
+
+		defaultModel := conv.Model
+		if conv.ModelInfo != nil && conv.ModelInfo.ModelID != "" {
+			defaultModel = conv.ModelInfo.ModelID
+		}
+
+		si := SessionInfo{
+			ID:        conv.ConversationID,
+			Project:   row.cwd,
+			Estimated: true,
+		}
+		// Each request resends the conversation so far, so a turn's input is
+		// everything before its response.
+		var context int
+		var prompts []string
+		for _, raw := range conv.History {
+			entry, ok := parseAmazonQEntry(raw)
+			if !ok {
+				continue
+			}
+			var user amazonQUserMessage
+			_ = json.Unmarshal(entry.User, &user)
+			var assistant amazonQAssistantMessage
+			_ = json.Unmarshal(entry.Assistant, &assistant)
+
+			m := defaultModel
+			var ts, end time.Time
+			if md := entry.RequestMetadata; md != nil {
+				if md.ModelID != "" {
+					m = md.ModelID
+				}
+				if md.RequestStartTimestamp > 0 {
+					ts = time.UnixMilli(md.RequestStartTimestamp)
+				}
+				if md.StreamEndTimestamp > 0 {
+					end = time.UnixMilli(md.StreamEndTimestamp)
+				}
+				if !ts.IsZero() && end.After(ts) {
+					si.Requests++
+					si.Latency += end.Sub(ts)
+				}
+			}
+			if ts.IsZero() {
+				ts, _ = time.Parse(time.RFC3339Nano, user.Timestamp)
+			}
+			if ts.IsZero() && row.updatedAt > 0 {
+				ts = time.UnixMilli(row.updatedAt)
+			}
+			if end.Before(ts) {
+				end = ts
+			}
+			m = amazonQModelName(m)
+
+			context += user.chars()
+			input := context / amazonQCharsPerToken
+			output := assistant.chars() / amazonQCharsPerToken
+			context += assistant.chars()
+			cost := model.CalculateCost(m, model.TokenUsage{InputTokens: input, OutputTokens: output})
+
+			si.Messages += 2
+			if p := user.Content.Prompt; p != nil {
+				si.UserMessages++
+				prompts = append(prompts, p.Prompt)
+				if si.Title == "" {
+					si.Title = promptTitle(p.Prompt)
+				}
+			}
+			si.Model = m
+			si.Tokens += input + output
+			si.Cost += cost
+			si.Turns = append(si.Turns, TurnUsage{
+				Timestamp:    ts,
+				Model:        m,
+				InputTokens:  input,
+				OutputTokens: output,
+				Cost:         cost,
+			})
+			if si.StartTime.IsZero() || ts.Before(si.StartTime) {
+				si.StartTime = ts
+			}
+			if end.After(si.EndTime) {
+				si.EndTime = end
+			}
+
+			mb := totals.model(m)
+			mb.Estimated = true
+			mb.InputTokens += input
+			mb.OutputTokens += output
+			mb.Cost += cost
+			mb.Requests++
+
+			du := totals.day(ts)
+			du.Cost += cost
+			du.Tokens += input + output
+		}
+		if len(si.Turns) == 0 {
+			continue
+		}
+		si.Prompts = len(prompts)
+
+		du := totals.day(si.StartTime)
+		du.Sessions++
+		du.Messages += si.Messages
+		du.Prompts += len(prompts)
+		for _, p := range prompts {
+			promptLengths = append(promptLengths, utf8.RuneCountInString(p))
+		}
+
+		data.TotalCost += si.Cost
+		data.Sessions = append(data.Sessions, si)
+		totals.span(si.StartTime, si.EndTime)
+	}
+	if len(promptLengths) > 0 {
+		data.Prompts = newPromptStats(promptLengths, len(data.Sessions))
+	}
+
+	totals.fill(data)
+
+	return data, nil
+}
+
+// loadAmazonQConversations reads every stored conversation from both
+// conversation tables, newest schema first.
+func loadAmazonQConversations(path string) ([]amazonQRow, error) {
+	db, err := sql.Open("sqlite3", path+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("opening amazon q db: %w", err)
+	}
+	defer db.Close()
+
+	var queries []string
+	if hasColumn(db, "conversations_v2", "value") {
+		queries = append(queries, `SELECT key, value, COALESCE(updated_at, 0) FROM conversations_v2`)
+	}
+	if hasColumn(db, "conversations", "value") {
+		queries = append(queries, `SELECT key, value, 0 FROM conversations`)
+	}
+	if len(queries) == 0 {
+		return nil, fmt.Errorf("no conversations table in %s", path)
+	}
+
+	var modTime int64
+	if info, err := os.Stat(path); err == nil {
+		modTime = info.ModTime().UnixMilli()
+	}
+
+	var out []amazonQRow
+	for _, query := range queries {
+		rows, err := db.Query(query)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var r amazonQRow
+			if rows.Scan(&r.cwd, &r.value, &r.updatedAt) != nil {
+				continue
+			}
+			if r.updatedAt == 0 {
+				r.updatedAt = modTime
+			}
+			out = append(out, r)
+		}
+		rows.Close()
+	}
+	return out, nil
+}
+
+// parseAmazonQEntry decodes a history entry in either the object or the
+// older [user, assistant] pair form.
+func parseAmazonQEntry(raw json.RawMessage) (amazonQHistoryEntry, bool) {
+	var entry amazonQHistoryEntry
+	if len(raw) > 0 && raw[0] == '[' {
+		var pair []json.RawMessage
+		if json.Unmarshal(raw, &pair) != nil || len(pair) != 2 {
+			return entry, false
+		}
+		entry.User, entry.Assistant = pair[0], pair[1]
+		return entry, true
+	}
+	if json.Unmarshal(raw, &entry) != nil || entry.User == nil {
+		return entry, false
+	}
+	return entry, true
+}
+
+// amazonQModelIDRe matches Amazon Q's internal model ids, such as
+// "CLAUDE_SONNET_4_20250514_V1_0".
+var amazonQModelIDRe = regexp.MustCompile(`^(.+?)(?:_\d{8})?_V\d+_\d+$`)
+
+// amazonQModelName turns Amazon Q's model ids into the names the pricing
+// table understands, e.g. "CLAUDE_SONNET_4_20250514_V1_0" and
+// "claude-sonnet-4.5" become "claude-sonnet-4" and "claude-sonnet-4-5".
+func amazonQModelName(id string) string {
+	if id == "" {
+		return "unknown"
+	}
+	if m := amazonQModelIDRe.FindStringSubmatch(id); m != nil {
+		id = strings.ReplaceAll(m[1], "_", "-")
+	}
+	id = strings.ToLower(id)
+	if strings.HasPrefix(id, "claude-") {
+		id = strings.ReplaceAll(id, ".", "-")
+	}
+	return id
+}
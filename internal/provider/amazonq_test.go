@@ -0,0 +1,110 @@
+package provider
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAmazonQConversations(t *testing.T) {
+	dir := t.TempDir()
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "data.sqlite3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A current conversation with request metadata, and an older one
+	// storing [user, assistant] pairs.
+	current := `{"conversation_id":"c-1","model_info":{"model_name":"claude-sonnet-4.5","model_id":"claude-sonnet-4.5"},"history":[
+		{"user":{"content":{"Prompt":{"prompt":"Why is the deploy script failing on CI?"}},"timestamp":"2026-02-10T09:30:00Z"},
+		 "assistant":{"ToolUse":{"message_id":"m1","content":"Let me read it.","tool_uses":[{"id":"t1","name":"fs_read","args":{"path":"deploy.sh"}}]}},
+		 "request_metadata":{"model_id":"claude-sonnet-4.5","request_start_timestamp_ms":1770715800000,"stream_end_timestamp_ms":1770715804000}},
+		{"user":{"content":{"ToolUseResults":{"tool_use_results":[{"tool_use_id":"t1","content":[{"Text":"#!/bin/sh\nset -e\n"}],"status":"Success"}]}},"timestamp":"2026-02-10T09:30:05Z"},
+		 "assistant":{"Response":{"message_id":"m2","content":"The script assumes bash but runs under sh."}},
+		 "request_metadata":{"model_id":"claude-sonnet-4.5","request_start_timestamp_ms":1770715805000,"stream_end_timestamp_ms":1770715811000}}
+	]}`
+	legacy := `{"conversation_id":"c-0","model":"CLAUDE_SONNET_4_20250514_V1_0","history":[
+		[{"content":{"Prompt":{"prompt":"List large files in this repo"}},"timestamp":"2026-01-05T14:00:00Z"},
+		 {"Response":{"message_id":"m0","content":"Run: find . -size +10M"}}]
+	]}`
+	stmts := []string{
+		`CREATE TABLE conversations (key TEXT PRIMARY KEY, value TEXT NOT NULL)`,
+		`CREATE TABLE conversations_v2 (key TEXT NOT NULL, conversation_id TEXT NOT NULL, value TEXT NOT NULL,
+			created_at INTEGER NOT NULL, updated_at INTEGER NOT NULL, PRIMARY KEY (key, conversation_id))`,
+	}
+	for _, s := range stmts {
+		if _, err := db.Exec(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := db.Exec(`INSERT INTO conversations_v2 VALUES ('/home/dev/src/deploy', 'c-1', ?, 1770715800000, 1770715811000)`, current); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO conversations VALUES ('/home/dev/src/webapp', ?)`, legacy); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	q := NewAmazonQ([]string{dir})
+	if !q.Available() {
+		t.Fatal("expected amazon q to be available")
+	}
+	data, err := q.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(data.Sessions))
+	}
+
+	s := data.Sessions[0]
+	if s.Project != "/home/dev/src/deploy" || s.Title != "Why is the deploy script failing on CI?" {
+		t.Errorf("unexpected session %q %q", s.Project, s.Title)
+	}
+	if s.Model != "claude-sonnet-4-5" || !s.Estimated {
+		t.Errorf("expected an estimated claude-sonnet-4-5 session, got %q estimated=%v", s.Model, s.Estimated)
+	}
+	if s.UserMessages != 1 || s.Messages != 4 || len(s.Turns) != 2 {
+		t.Errorf("expected 1 prompt across 2 exchanges, got %d/%d/%d", s.UserMessages, s.Messages, len(s.Turns))
+	}
+	if s.Requests != 2 || s.Latency != 10*time.Second {
+		t.Errorf("expected 2 requests taking 10s, got %d %s", s.Requests, s.Latency)
+	}
+	// Estimates come from the text sent and written, not the stored JSON:
+	// a 39-character prompt, then 42 characters of text and tool call.
+	if s.Turns[0].InputTokens != 9 || s.Turns[0].OutputTokens != 10 {
+		t.Errorf("unexpected first estimate %+v", s.Turns[0])
+	}
+	// The second request resends the first exchange with 17 characters of
+	// tool output.
+	if s.Turns[1].InputTokens != (39+42+17)/4 || s.Tokens == 0 || s.Cost <= 0 {
+		t.Errorf("unexpected estimates %+v", s.Turns)
+	}
+
+	legacySession := data.Sessions[1]
+	if legacySession.Model != "claude-sonnet-4" || legacySession.Project != "/home/dev/src/webapp" {
+		t.Errorf("unexpected legacy session %+v", legacySession)
+	}
+	if !legacySession.StartTime.Equal(time.Date(2026, 1, 5, 14, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the prompt timestamp, got %s", legacySession.StartTime)
+	}
+	for _, m := range data.Models {
+		if !m.Estimated {
+			t.Errorf("expected model %s to be marked estimated", m.Model)
+		}
+	}
+}
+
+func TestAmazonQModelName(t *testing.T) {
+	for id, want := range map[string]string{
+		"CLAUDE_SONNET_4_20250514_V1_0":   "claude-sonnet-4",
+		"CLAUDE_3_7_SONNET_20250219_V1_0": "claude-3-7-sonnet",
+		"claude-sonnet-4.5":               "claude-sonnet-4-5",
+		"":                                "unknown",
+	} {
+		if got := amazonQModelName(id); got != want {
+			t.Errorf("amazonQModelName(%q) = %q, want %q", id, got, want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package provider
+
+import (
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/isaacaudet/aitop/internal/apiproxy"
+	"github.com/isaacaudet/aitop/internal/model"
+)
+
+// API implements Provider for direct Anthropic and OpenAI API calls recorded
+// by `aitop proxy`. Each caller tag's requests on one day form a session.
+type API struct {
+	Dir string // The proxy's ledger directory
+}
+
+func NewAPI(dir string) *API {
+	return &API{Dir: dir}
+}
+
+func (a *API) Name() string  { return "API" }
+func (a *API) Icon() string  { return "⇄" }
+func (a *API) Color() string { return "#8aadf4" } // Macchiato Blue
+
+func (a *API) Available() bool {
+	files, _ := filepath.Glob(filepath.Join(a.Dir, "*.jsonl"))
+	return len(files) > 0
+}
+
+func (a *API) Load() (*ProviderData, error) {
+	data := &ProviderData{
+		ProviderName: a.Name(),
+		Icon:         a.Icon(),
+		Color:        a.Color(),
+		Metadata:     make(map[string]string),
+	}
+
+	entries, err := apiproxy.ReadEntries(a.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	type requestStats struct {
+		requests, errors int
+		latency          time.Duration
+	}
+	stats := make(map[string]*requestStats)
+	byAPI := make(map[string]int)
+	byCaller := make(map[string]int)
+	failed := 0
+
+	agg := newEventAggregator()
+	for _, e := range entries {
+		caller := e.Caller
+		if caller == "" {
+			caller = "(untagged)"
+		}
+		session := caller + " " + e.Time.Local().Format("2006-01-02")
+		st, ok := stats[session]
+		if !ok {
+			st = &requestStats{}
+			stats[session] = st
+		}
+		st.requests++
+		st.latency += time.Duration(e.LatencyMS) * time.Millisecond
+		byAPI[e.API]++
+		byCaller[caller]++
+
+		if e.Status < 200 || e.Status >= 300 {
+			// A failed request has no usage, but its caller and day still
+			// get a session so the error and latency show up.
+			agg.session(session, e.Time).Project = caller
+			st.errors++
+			failed++
+			continue
+		}
+		agg.add(usageEvent{
+			Timestamp: e.Time,
+			Session:   session,
+			Project:   caller,
+			Model:     e.Model,
+			Usage: model.TokenUsage{
+				InputTokens:  e.Input,
+				OutputTokens: e.Output,
+				CacheRead:    e.CacheRead,
+				CacheWrite:   e.CacheWrite,
+			},
+		})
+	}
+	agg.fill(data)
+
+	for i := range data.Sessions {
+		si := &data.Sessions[i]
+		st := stats[si.ID]
+		si.Title = si.Project
+		si.Requests = st.requests
+		si.Errors = st.errors
+		si.Latency = st.latency
+	}
+	data.Dimensions = appendDimension(data.Dimensions, "Requests by API", byAPI)
+	data.Dimensions = appendDimension(data.Dimensions, "Requests by caller", byCaller)
+	data.Metadata["requests"] = strconv.Itoa(len(entries))
+	if failed > 0 {
+		data.Metadata["failed_requests"] = strconv.Itoa(failed)
+	}
+	return data, nil
+}
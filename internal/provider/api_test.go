@@ -0,0 +1,44 @@
+package provider
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAPILedger(t *testing.T) {
+	a := NewAPI("../../testdata/api")
+	if !a.Available() {
+		t.Fatal("expected the API ledger to be available")
+	}
+	data, err := a.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Sessions) != 3 {
+		t.Fatalf("expected a session per caller, got %d", len(data.Sessions))
+	}
+
+	s := data.Sessions[0]
+	if s.Project != "nightly-eval" || s.Model != "claude-sonnet-4-5" {
+		t.Errorf("unexpected session %q %q", s.Project, s.Model)
+	}
+	if s.Requests != 3 || s.Errors != 1 || s.Messages != 2 || s.Latency != 4500*time.Millisecond {
+		t.Errorf("expected 3 requests, 1 error, 2 responses and 4.5s, got %d/%d/%d/%v",
+			s.Requests, s.Errors, s.Messages, s.Latency)
+	}
+	if s.Tokens != 6700 || s.Cost <= 0 {
+		t.Errorf("expected priced usage of 6700 tokens, got %d $%.4f", s.Tokens, s.Cost)
+	}
+	if data.Sessions[1].Project != "(untagged)" {
+		t.Errorf("expected untagged calls grouped together, got %q", data.Sessions[1].Project)
+	}
+	// A caller whose only request failed still gets a session.
+	failed := data.Sessions[2]
+	if failed.Project != "smoke-test" || failed.Requests != 1 || failed.Errors != 1 ||
+		failed.Latency != 120*time.Millisecond || failed.Tokens != 0 {
+		t.Errorf("unexpected error-only session %+v", failed)
+	}
+	if len(data.Models) != 2 || data.Metadata["failed_requests"] != "2" {
+		t.Errorf("unexpected models %+v or metadata %v", data.Models, data.Metadata)
+	}
+}
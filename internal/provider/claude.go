@@ -2,23 +2,29 @@ package provider
 
 import (
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/isaacaudet/aitop/internal/model"
 	"github.com/isaacaudet/aitop/internal/parser"
 )
 
-// Claude implements Provider for Claude Code / OpenClaw.
+// Claude implements Provider for Claude Code / OpenClaw. It reads one or more
+// Claude config dirs (~/.claude or $CLAUDE_CONFIG_DIR), each holding
+// stats-cache.json and a projects/ directory of session transcripts.
 type Claude struct {
-	StatsPath   string
-	ProjectsDir string
+	ConfigDirs []string
+	// StatsCaches are the stats-cache.json files to read, by default one
+	// per config dir.
+	StatsCaches []string
 }
 
-func NewClaude() *Claude {
-	return &Claude{
-		StatsPath:   parser.DefaultStatsCachePath(),
-		ProjectsDir: parser.DefaultProjectsDir(),
+func NewClaude(configDirs []string) *Claude {
+	c := &Claude{ConfigDirs: configDirs}
+	for _, dir := range configDirs {
+		c.StatsCaches = append(c.StatsCaches, filepath.Join(dir, "stats-cache.json"))
 	}
+	return c
 }
 
 func (c *Claude) Name() string  { return "Claude Code" }
@@ -26,14 +32,28 @@ func (c *Claude) Icon() string  { return "◈" }
 func (c *Claude) Color() string { return "#b4befe" } // Lavender
 
 func (c *Claude) Available() bool {
-	_, err := os.Stat(c.StatsPath)
-	return err == nil
+	for _, path := range c.StatsCaches {
+		if _, err := os.Stat(path); err == nil {
+			return true
+		}
+	}
+	return false
 }
 
 func (c *Claude) Load() (*ProviderData, error) {
-	cache, err := parser.ParseStatsCache(c.StatsPath)
-	if err != nil {
-		return nil, err
+	var caches []*model.StatsCache
+	var lastErr error
+	for _, path := range c.StatsCaches {
+		cache, err := parser.ParseStatsCache(path)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		caches = append(caches, cache)
+	}
+	cache := model.MergeStatsCaches(caches...)
+	if cache == nil {
+		return nil, lastErr
 	}
 
 	data := &ProviderData{
@@ -84,8 +104,23 @@ func (c *Claude) Load() (*ProviderData, error) {
 		}
 	}
 
-	// Load session data in the background-capable way.
-	sessions, _ := parser.LoadAllSessions(c.ProjectsDir)
+	// Load session data from every config dir. The same session can show up
+	// under more than one dir (e.g. a copied ~/.claude), so keep one per ID.
+	byID := make(map[string]*model.Session)
+	var sessions []*model.Session
+	for _, dir := range c.ConfigDirs {
+		loaded, _ := parser.LoadAllSessions(filepath.Join(dir, "projects"))
+		for _, s := range loaded {
+			if prev, ok := byID[s.ID]; ok {
+				if len(s.Turns) > len(prev.Turns) {
+					*prev = *s
+				}
+				continue
+			}
+			byID[s.ID] = s
+			sessions = append(sessions, s)
+		}
+	}
 	for _, s := range sessions {
 		var cost float64
 		var totalTokens int
@@ -93,6 +128,18 @@ func (c *Claude) Load() (*ProviderData, error) {
 			cost += model.CalculateCost(m, mu)
 			totalTokens += mu.InputTokens + mu.OutputTokens + mu.CacheRead + mu.CacheWrite
 		}
+		var turns []TurnUsage
+		for _, t := range s.Turns {
+			turns = append(turns, TurnUsage{
+				Timestamp:    t.Timestamp,
+				Model:        t.Model,
+				InputTokens:  t.Usage.InputTokens,
+				OutputTokens: t.Usage.OutputTokens,
+				CacheRead:    t.Usage.CacheRead,
+				CacheWrite:   t.Usage.CacheWrite,
+				Cost:         model.CalculateCost(t.Model, t.Usage),
+			})
+		}
 		data.Sessions = append(data.Sessions, SessionInfo{
 			ID:           s.ID,
 			Project:      s.Project,
@@ -102,6 +149,7 @@ func (c *Claude) Load() (*ProviderData, error) {
 			UserMessages: s.UserMessages,
 			Tokens:       totalTokens,
 			Cost:         cost,
+			Turns:        turns,
 		})
 	}
 
@@ -0,0 +1,364 @@
+package provider
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/isaacaudet/aitop/internal/model"
+	"github.com/isaacaudet/aitop/internal/otlp"
+	"github.com/isaacaudet/aitop/internal/parser"
+)
+
+// ClaudeTelemetry implements Provider for the OpenTelemetry metrics and
+// events Claude Code exports, as stored by `aitop collect`. Its costs are the
+// ones Claude Code computed itself.
+//
+// Usage the Claude provider already counts is left to it so it isn't
+// counted twice: sessions whose transcripts are still on disk, and any day
+// up to the stats cache's LastComputedDate, whose cost the cache includes
+// even once transcripts are pruned. That usage only contributes lines of
+// code, commits and other activity Claude Code doesn't otherwise record.
+type ClaudeTelemetry struct {
+	Dir        string   // Where `aitop collect` stores telemetry
+	ClaudeDirs []string // Claude config dirs whose transcripts take precedence
+	// StatsCaches are the stats-cache.json files the Claude provider
+	// reads, by default one per config dir.
+	StatsCaches []string
+}
+
+func NewClaudeTelemetry(dir string, claudeDirs []string) *ClaudeTelemetry {
+	c := &ClaudeTelemetry{Dir: dir, ClaudeDirs: claudeDirs}
+	for _, d := range claudeDirs {
+		c.StatsCaches = append(c.StatsCaches, filepath.Join(d, "stats-cache.json"))
+	}
+	return c
+}
+
+func (c *ClaudeTelemetry) Name() string  { return "Claude Telemetry" }
+func (c *ClaudeTelemetry) Icon() string  { return "⊛" }
+func (c *ClaudeTelemetry) Color() string { return "#c6a0f6" } // Macchiato Mauve
+
+func (c *ClaudeTelemetry) Available() bool {
+	files, _ := filepath.Glob(filepath.Join(c.Dir, "*.jsonl"))
+	return len(files) > 0
+}
+
+// claudeUsage is usage from one api_request event, or from one cost or token
+// metric point when a session has no events.
+type claudeUsage struct {
+	time    time.Time
+	session string
+	model   string
+	usage   model.TokenUsage
+	cost    float64
+	request bool
+	failed  bool
+	latency time.Duration
+}
+
+func (c *ClaudeTelemetry) Load() (*ProviderData, error) {
+	data := &ProviderData{
+		ProviderName: c.Name(),
+		Icon:         c.Icon(),
+		Color:        c.Color(),
+		Metadata:     make(map[string]string),
+	}
+
+	records, err := otlp.ReadRecords(c.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var events, metrics []otlp.Record
+	for _, r := range records {
+		if r.Attrs["service.name"] != "claude-code" && !strings.HasPrefix(r.Name, "claude_code.") {
+			continue
+		}
+		if r.Kind == otlp.KindEvent {
+			events = append(events, r)
+		} else {
+			metrics = append(metrics, r)
+		}
+	}
+	metrics = metricDeltas(metrics)
+
+	// Requests come from api_request events where they were exported, and
+	// from the cost and token metrics otherwise.
+	var usages []claudeUsage
+	fromEvents := make(map[string]bool)
+	var promptLengths []int
+	prompts := make(map[string]int)
+	for _, r := range events {
+		session := r.Attrs["session.id"]
+		switch strings.TrimPrefix(r.Name, "claude_code.") {
+		case "api_request":
+			fromEvents[session] = true
+			u := claudeUsage{
+				time:    r.Time,
+				session: session,
+				model:   r.Attrs["model"],
+				cost:    attrFloat(r.Attrs, "cost_usd"),
+				request: true,
+				latency: time.Duration(attrFloat(r.Attrs, "duration_ms")) * time.Millisecond,
+				usage: model.TokenUsage{
+					InputTokens:  int(attrFloat(r.Attrs, "input_tokens")),
+					OutputTokens: int(attrFloat(r.Attrs, "output_tokens")),
+					CacheRead:    int(attrFloat(r.Attrs, "cache_read_tokens")),
+					CacheWrite:   int(attrFloat(r.Attrs, "cache_creation_tokens")),
+				},
+			}
+			usages = append(usages, u)
+		case "api_error":
+			usages = append(usages, claudeUsage{
+				time:    r.Time,
+				session: session,
+				model:   r.Attrs["model"],
+				request: true,
+				failed:  true,
+				latency: time.Duration(attrFloat(r.Attrs, "duration_ms")) * time.Millisecond,
+			})
+		case "user_prompt":
+			prompts[session]++
+			if n := int(attrFloat(r.Attrs, "prompt_length")); n > 0 {
+				promptLengths = append(promptLengths, n)
+			}
+		}
+	}
+
+	lines := map[string]int{}
+	activity := map[string]int{}
+	decisions := map[string]int{}
+	var activeTime float64
+	for _, r := range metrics {
+		session := r.Attrs["session.id"]
+		switch strings.TrimPrefix(r.Name, "claude_code.") {
+		case "cost.usage":
+			if !fromEvents[session] {
+				usages = append(usages, claudeUsage{time: r.Time, session: session, model: r.Attrs["model"], cost: r.Value})
+			}
+		case "token.usage":
+			if fromEvents[session] {
+				continue
+			}
+			u := claudeUsage{time: r.Time, session: session, model: r.Attrs["model"]}
+			n := int(r.Value)
+			switch r.Attrs["type"] {
+			case "input":
+				u.usage.InputTokens = n
+			case "output":
+				u.usage.OutputTokens = n
+			case "cacheRead":
+				u.usage.CacheRead = n
+			case "cacheCreation":
+				u.usage.CacheWrite = n
+			}
+			usages = append(usages, u)
+		case "lines_of_code.count":
+			lines[r.Attrs["type"]] += int(r.Value)
+		case "commit.count":
+			activity["commits"] += int(r.Value)
+		case "pull_request.count":
+			activity["pull requests"] += int(r.Value)
+		case "code_edit_tool.decision":
+			decisions[r.Attrs["decision"]] += int(r.Value)
+		case "active_time.total":
+			activeTime += r.Value
+		}
+	}
+
+	transcripts := c.transcriptIDs()
+	covered := c.statsCacheDate()
+	sessions := make(map[string]*SessionInfo)
+	var order []string
+	totals := newUsageTotals()
+	sort.SliceStable(usages, func(i, j int) bool { return usages[i].time.Before(usages[j].time) })
+	skipped := make(map[string]bool)
+	for _, u := range usages {
+		if transcripts[u.session] || u.time.Local().Format("2006-01-02") <= covered {
+			if u.session != "" {
+				skipped[u.session] = true
+			}
+			continue
+		}
+		m := u.model
+		if m == "" {
+			m = "unknown"
+		}
+		tokens := u.usage.InputTokens + u.usage.OutputTokens + u.usage.CacheRead + u.usage.CacheWrite
+
+		if u.session != "" {
+			si, ok := sessions[u.session]
+			if !ok {
+				si = &SessionInfo{ID: u.session, StartTime: u.time, Prompts: prompts[u.session]}
+				si.UserMessages = si.Prompts
+				sessions[u.session] = si
+				order = append(order, u.session)
+				du := totals.day(u.time)
+				du.Sessions++
+				du.Prompts += si.Prompts
+			}
+			si.EndTime = u.time
+			si.Tokens += tokens
+			si.Cost += u.cost
+			if u.model != "" {
+				si.Model = u.model
+			}
+			if u.request {
+				si.Requests++
+				si.Latency += u.latency
+				if u.failed {
+					si.Errors++
+					continue
+				}
+				si.Messages++
+				si.Turns = append(si.Turns, TurnUsage{
+					Timestamp:    u.time,
+					Model:        m,
+					InputTokens:  u.usage.InputTokens,
+					OutputTokens: u.usage.OutputTokens,
+					CacheRead:    u.usage.CacheRead,
+					CacheWrite:   u.usage.CacheWrite,
+					Cost:         u.cost,
+				})
+			}
+		} else if u.failed {
+			continue
+		}
+
+		mb := totals.model(m)
+		mb.InputTokens += u.usage.InputTokens
+		mb.OutputTokens += u.usage.OutputTokens
+		mb.CacheRead += u.usage.CacheRead
+		mb.CacheWrite += u.usage.CacheWrite
+		mb.Cost += u.cost
+		if u.request {
+			mb.Requests++
+		}
+
+		du := totals.day(u.time)
+		du.Cost += u.cost
+		du.Tokens += tokens
+		if u.request {
+			du.Messages++
+		}
+		data.TotalCost += u.cost
+		totals.span(u.time, u.time)
+	}
+
+	for _, id := range order {
+		data.Sessions = append(data.Sessions, *sessions[id])
+	}
+	if len(promptLengths) > 0 {
+		data.Prompts = newPromptStats(promptLengths, len(data.Sessions))
+	}
+	totals.fill(data)
+
+	data.Dimensions = appendDimension(data.Dimensions, "Lines of code", lines)
+	data.Dimensions = appendDimension(data.Dimensions, "Git activity", activity)
+	data.Dimensions = appendDimension(data.Dimensions, "Edit decisions", decisions)
+	if activeTime > 0 {
+		data.Metadata["active_time"] = (time.Duration(activeTime) * time.Second).String()
+	}
+	if len(skipped) > 0 {
+		data.Metadata["note"] = fmt.Sprintf("%d sessions with transcripts or in the stats cache are counted under Claude Code", len(skipped))
+	}
+	return data, nil
+}
+
+// transcriptIDs returns the ids of the sessions whose transcripts are still
+// under projects/ in a Claude config dir.
+func (c *ClaudeTelemetry) transcriptIDs() map[string]bool {
+	ids := make(map[string]bool)
+	for _, dir := range c.ClaudeDirs {
+		files, _ := filepath.Glob(filepath.Join(dir, "projects", "*", "*.jsonl"))
+		for _, f := range files {
+			ids[strings.TrimSuffix(filepath.Base(f), ".jsonl")] = true
+		}
+	}
+	return ids
+}
+
+// statsCacheDate returns the latest LastComputedDate among the stats caches,
+// or "" if there are none.
+func (c *ClaudeTelemetry) statsCacheDate() string {
+	var caches []*model.StatsCache
+	for _, path := range c.StatsCaches {
+		if cache, err := parser.ParseStatsCache(path); err == nil {
+			caches = append(caches, cache)
+		}
+	}
+	if merged := model.MergeStatsCaches(caches...); merged != nil {
+		return merged.LastComputedDate
+	}
+	return ""
+}
+
+// appendDimension adds a dimension of the non-zero counts, largest first.
+func appendDimension(dims []Dimension, name string, counts map[string]int) []Dimension {
+	d := Dimension{Name: name}
+	for label, n := range counts {
+		if n > 0 {
+			d.Items = append(d.Items, DimensionItem{Label: label, Count: n})
+		}
+	}
+	if len(d.Items) == 0 {
+		return dims
+	}
+	sort.Slice(d.Items, func(i, j int) bool {
+		if d.Items[i].Count != d.Items[j].Count {
+			return d.Items[i].Count > d.Items[j].Count
+		}
+		return d.Items[i].Label < d.Items[j].Label
+	})
+	return append(dims, d)
+}
+
+// metricDeltas turns cumulative points into the increase since the previous
+// point of the same series, so every metric can be summed. A series is a
+// metric name, start time and attribute set; a value lower than the last
+// means the counter was reset.
+func metricDeltas(records []otlp.Record) []otlp.Record {
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Time.Before(records[j].Time) })
+	last := make(map[string]float64)
+	out := records[:0]
+	for _, r := range records {
+		if r.Cumulative {
+			key := seriesKey(r)
+			prev, seen := last[key]
+			last[key] = r.Value
+			if seen && r.Value >= prev {
+				r.Value -= prev
+			}
+			r.Cumulative = false
+		}
+		if r.Value != 0 {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func seriesKey(r otlp.Record) string {
+	keys := make([]string, 0, len(r.Attrs))
+	for k := range r.Attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var sb strings.Builder
+	sb.WriteString(r.Name)
+	sb.WriteString("\x00")
+	sb.WriteString(r.Start.String())
+	for _, k := range keys {
+		sb.WriteString("\x00" + k + "=" + r.Attrs[k])
+	}
+	return sb.String()
+}
+
+func attrFloat(attrs map[string]string, key string) float64 {
+	n, _ := strconv.ParseFloat(attrs[key], 64)
+	return n
+}
@@ -0,0 +1,103 @@
+package provider
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestClaudeTelemetry(t *testing.T) {
+	c := NewClaudeTelemetry("../../testdata/telemetry/store", []string{"../../testdata/telemetry/claude"})
+	if !c.Available() {
+		t.Fatal("expected telemetry to be available")
+	}
+	data, err := c.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// sess-kept still has a transcript, so only its activity is counted.
+	if len(data.Sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %+v", data.Sessions)
+	}
+	if want := 0.33; math.Abs(data.TotalCost-want) > 1e-9 {
+		t.Errorf("expected total cost %.2f, got %.4f", want, data.TotalCost)
+	}
+	if data.Metadata["note"] == "" {
+		t.Error("expected a note about sessions left to the Claude provider")
+	}
+
+	// Events give per-request turns; the cost metric for the same session
+	// is not added on top.
+	s := data.Sessions[0]
+	if s.ID != "sess-pruned" || s.Project != "" {
+		t.Errorf("unexpected first session %q %q", s.ID, s.Project)
+	}
+	if s.Requests != 3 || s.Errors != 1 || len(s.Turns) != 2 || s.Prompts != 2 {
+		t.Errorf("expected 3 requests, 1 error, 2 turns and 2 prompts, got %d/%d/%d/%d",
+			s.Requests, s.Errors, len(s.Turns), s.Prompts)
+	}
+	if s.Latency != 4*time.Second {
+		t.Errorf("expected 4s latency, got %v", s.Latency)
+	}
+	if math.Abs(s.Cost-0.08) > 1e-9 || s.Tokens != 13850 {
+		t.Errorf("unexpected session cost/tokens %.4f %d", s.Cost, s.Tokens)
+	}
+
+	// Cumulative metrics are turned into deltas.
+	s = data.Sessions[1]
+	if s.ID != "sess-metrics" || math.Abs(s.Cost-0.25) > 1e-9 || s.Tokens != 2700 {
+		t.Errorf("unexpected metrics-only session %+v", s)
+	}
+	if len(data.Models) != 2 || data.Models[0].Model != "claude-opus-4-1" || data.Models[0].InputTokens != 2500 {
+		t.Errorf("unexpected models %+v", data.Models)
+	}
+	if data.Prompts == nil || data.Prompts.Total != 2 || data.Prompts.MaxLength != 120 {
+		t.Errorf("unexpected prompt stats %+v", data.Prompts)
+	}
+
+	dims := make(map[string]map[string]int)
+	for _, d := range data.Dimensions {
+		dims[d.Name] = make(map[string]int)
+		for _, item := range d.Items {
+			dims[d.Name][item.Label] = item.Count
+		}
+	}
+	if lines := dims["Lines of code"]; lines["added"] != 25 || lines["removed"] != 4 {
+		t.Errorf("unexpected lines of code %v", lines)
+	}
+	if git := dims["Git activity"]; git["commits"] != 3 || git["pull requests"] != 1 {
+		t.Errorf("unexpected git activity %v", git)
+	}
+	if edits := dims["Edit decisions"]; edits["accept"] != 3 || edits["reject"] != 1 {
+		t.Errorf("unexpected edit decisions %v", edits)
+	}
+	if data.Metadata["active_time"] != "1m30s" {
+		t.Errorf("unexpected active time %q", data.Metadata["active_time"])
+	}
+}
+
+func TestClaudeTelemetryStatsCacheDays(t *testing.T) {
+	// The stats cache already counts every day it was computed through,
+	// including sessions whose transcripts have since been pruned.
+	cache := filepath.Join(t.TempDir(), "stats-cache.json")
+	if err := os.WriteFile(cache, []byte(`{"version":2,"lastComputedDate":"2026-03-05"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c := NewClaudeTelemetry("../../testdata/telemetry/store", []string{"../../testdata/telemetry/claude"})
+	c.StatsCaches = []string{cache}
+	data, err := c.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Sessions) != 0 || data.TotalCost != 0 || len(data.Models) != 0 {
+		t.Errorf("expected usage on covered days left to Claude Code, got %d sessions at $%.2f",
+			len(data.Sessions), data.TotalCost)
+	}
+	// Activity the stats cache doesn't record is still counted.
+	if len(data.Dimensions) == 0 || data.Metadata["active_time"] == "" {
+		t.Errorf("expected activity kept, got %+v", data.Dimensions)
+	}
+}
@@ -9,22 +9,29 @@ import (
 	"sort"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/isaacaudet/aitop/internal/model"
 )
 
-// Codex implements Provider for OpenAI Codex CLI.
+// Codex implements Provider for OpenAI Codex CLI. It reads the sessions/ and
+// archived_sessions/ rollouts and history.jsonl of one or more Codex homes
+// (~/.codex or $CODEX_HOME).
 type Codex struct {
-	SessionsDir string
-	HistoryPath string
+	SessionsDirs []string
+	HistoryPaths []string
 }
 
-func NewCodex() *Codex {
-	home, _ := os.UserHomeDir()
-	return &Codex{
-		SessionsDir: filepath.Join(home, ".codex", "sessions"),
-		HistoryPath: filepath.Join(home, ".codex", "history.jsonl"),
+func NewCodex(homes []string) *Codex {
+	c := &Codex{}
+	for _, home := range homes {
+		c.SessionsDirs = append(c.SessionsDirs,
+			filepath.Join(home, "sessions"),
+			filepath.Join(home, "archived_sessions"),
+		)
+		c.HistoryPaths = append(c.HistoryPaths, filepath.Join(home, "history.jsonl"))
 	}
+	return c
 }
 
 func (c *Codex) Name() string  { return "Codex" }
@@ -32,8 +39,12 @@ func (c *Codex) Icon() string  { return "⊡" }
 func (c *Codex) Color() string { return "#a6e3a1" } // Green
 
 func (c *Codex) Available() bool {
-	_, err := os.Stat(c.SessionsDir)
-	return err == nil
+	for _, dir := range c.SessionsDirs {
+		if _, err := os.Stat(dir); err == nil {
+			return true
+		}
+	}
+	return false
 }
 
 // codexSessionMeta is the first line of a new-format rollout JSONL.
@@ -75,15 +86,27 @@ type codexEventMsg struct {
 	} `json:"payload"`
 }
 
-// codexTokenInfo contains cumulative token usage from token_count events.
+// codexTokenUsage is a token usage block as reported by token_count events.
+type codexTokenUsage struct {
+	InputTokens           int `json:"input_tokens"`
+	CachedInputTokens     int `json:"cached_input_tokens"`
+	OutputTokens          int `json:"output_tokens"`
+	ReasoningOutputTokens int `json:"reasoning_output_tokens"`
+	TotalTokens           int `json:"total_tokens"`
+}
+
+// codexTokenInfo contains token usage from token_count events. TotalTokenUsage is
+// cumulative for the session; LastTokenUsage, when present, covers only the latest turn.
 type codexTokenInfo struct {
-	TotalTokenUsage struct {
-		InputTokens           int `json:"input_tokens"`
-		CachedInputTokens     int `json:"cached_input_tokens"`
-		OutputTokens          int `json:"output_tokens"`
-		ReasoningOutputTokens int `json:"reasoning_output_tokens"`
-		TotalTokens           int `json:"total_tokens"`
-	} `json:"total_token_usage"`
+	TotalTokenUsage codexTokenUsage  `json:"total_token_usage"`
+	LastTokenUsage  *codexTokenUsage `json:"last_token_usage"`
+}
+
+// codexTurn is the token usage of a single model turn within a rollout.
+type codexTurn struct {
+	timestamp time.Time
+	modelName string
+	usage     codexTokenUsage
 }
 
 // codexResponseItem represents a response_item line.
@@ -117,7 +140,7 @@ type codexSession struct {
 	endTime      time.Time
 	messages     int
 	userMessages int
-	tokens       codexTokenInfo
+	turns        []codexTurn
 	dateKey      string // YYYY-MM-DD from directory path
 }
 
@@ -134,50 +157,70 @@ func (c *Codex) Load() (*ProviderData, error) {
 		return nil, fmt.Errorf("parsing codex sessions: %w", err)
 	}
 
-	// Aggregate by model and by day.
-	modelMap := make(map[string]*ModelBreakdown)
-	dailyMap := make(map[string]*DailyUsage)
+	// Prompt history is optional; older installs and fresh machines lack it.
+	history, _ := c.loadHistory()
+
+	totals := newUsageTotals()
 
 	for _, s := range sessions {
-		tu := s.tokens.TotalTokenUsage
-		inputTokens := tu.InputTokens
-		cachedTokens := tu.CachedInputTokens
-		outputTokens := tu.OutputTokens + tu.ReasoningOutputTokens
-		totalTokens := inputTokens + outputTokens
-
-		cost := model.CalculateCost(s.modelName, model.TokenUsage{
-			InputTokens:  inputTokens,
-			OutputTokens: outputTokens,
-			CacheRead:    cachedTokens,
-		})
+		var cost float64
+		var totalTokens int
+		var turns []TurnUsage
+
+		for _, t := range s.turns {
+			inputTokens := t.usage.InputTokens
+			cachedTokens := t.usage.CachedInputTokens
+			outputTokens := t.usage.OutputTokens + t.usage.ReasoningOutputTokens
+
+			turnCost := model.CalculateCost(t.modelName, model.TokenUsage{
+				InputTokens:  inputTokens,
+				OutputTokens: outputTokens,
+				CacheRead:    cachedTokens,
+			})
+			cost += turnCost
+			totalTokens += inputTokens + outputTokens
+
+			turns = append(turns, TurnUsage{
+				Timestamp:    t.timestamp,
+				Model:        t.modelName,
+				InputTokens:  inputTokens,
+				OutputTokens: outputTokens,
+				CacheRead:    cachedTokens,
+				Cost:         turnCost,
+			})
+
+			mb := totals.model(t.modelName)
+			mb.InputTokens += inputTokens
+			mb.OutputTokens += outputTokens
+			mb.CacheRead += cachedTokens
+			mb.Cost += turnCost
+			mb.Requests++
+
+			// Attribute tokens and cost to the day the turn happened.
+			day := totals.date(s.dateKey)
+			if !t.timestamp.IsZero() {
+				day = totals.day(t.timestamp)
+			}
+			day.Cost += turnCost
+			day.Tokens += inputTokens + outputTokens
+		}
 
 		data.TotalCost += cost
 
-		// Model breakdown.
-		mb, ok := modelMap[s.modelName]
-		if !ok {
-			mb = &ModelBreakdown{Model: s.modelName}
-			modelMap[s.modelName] = mb
-		}
-		mb.InputTokens += inputTokens
-		mb.OutputTokens += outputTokens
-		mb.CacheRead += cachedTokens
-		mb.Cost += cost
-
-		// Daily usage.
-		day, ok := dailyMap[s.dateKey]
-		if !ok {
-			day = &DailyUsage{Date: s.dateKey}
-			dailyMap[s.dateKey] = day
-		}
-		day.Cost += cost
-		day.Tokens += totalTokens
+		// Sessions and messages count toward the day the rollout started.
+		day := totals.date(s.dateKey)
 		day.Messages += s.messages
 		day.Sessions++
 
 		// Session info.
+		prompts := history[s.id]
+		var title string
+		if len(prompts) > 0 {
+			title = promptTitle(prompts[0].Text)
+		}
 		data.Sessions = append(data.Sessions, SessionInfo{
 			ID:           s.id,
+			Title:        title,
 			Project:      s.project,
 			StartTime:    s.startTime,
 			EndTime:      s.endTime,
@@ -186,58 +229,118 @@ func (c *Codex) Load() (*ProviderData, error) {
 			Tokens:       totalTokens,
 			Cost:         cost,
 			Model:        s.modelName,
+			Prompts:      len(prompts),
+			Turns:        turns,
 		})
 
-		// Track first/last seen.
-		if !s.startTime.IsZero() {
-			if data.FirstSeen.IsZero() || s.startTime.Before(data.FirstSeen) {
-				data.FirstSeen = s.startTime
+		totals.span(s.startTime, s.endTime)
+	}
+
+	// Prompt analytics from history.jsonl, attributed to the day each prompt was sent.
+	if len(history) > 0 {
+		var lengths []int
+		for _, entries := range history {
+			for _, e := range entries {
+				lengths = append(lengths, utf8.RuneCountInString(e.Text))
+				totals.day(time.Unix(e.Ts, 0)).Prompts++
 			}
-			if s.endTime.After(data.LastSeen) {
-				data.LastSeen = s.endTime
-			} else if s.startTime.After(data.LastSeen) {
-				data.LastSeen = s.startTime
+		}
+		data.Prompts = newPromptStats(lengths, len(history))
+	}
+
+	totals.fill(data)
+
+	return data, nil
+}
+
+// loadHistory parses every history.jsonl into prompts grouped by session id,
+// each group ordered by submission time. Prompts present in more than one
+// history file are counted once.
+func (c *Codex) loadHistory() (map[string][]codexHistoryEntry, error) {
+	history := make(map[string][]codexHistoryEntry)
+	seen := make(map[codexHistoryEntry]bool)
+	var lastErr error
+
+	for _, path := range c.HistoryPaths {
+		f, err := os.Open(path)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024) // 1MB max line
+
+		for scanner.Scan() {
+			var e codexHistoryEntry
+			if err := json.Unmarshal(scanner.Bytes(), &e); err != nil || e.SessionID == "" {
+				continue
 			}
+			if seen[e] {
+				continue
+			}
+			seen[e] = true
+			history[e.SessionID] = append(history[e.SessionID], e)
+		}
+		if err := scanner.Err(); err != nil {
+			lastErr = err
 		}
+		f.Close()
 	}
 
-	// Convert maps to slices.
-	for _, mb := range modelMap {
-		data.Models = append(data.Models, *mb)
+	for _, entries := range history {
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].Ts < entries[j].Ts })
 	}
-	for _, du := range dailyMap {
-		data.DailyUsage = append(data.DailyUsage, *du)
+	if len(history) == 0 {
+		return nil, lastErr
 	}
+	return history, nil
+}
 
-	// Sort daily usage by date.
-	sort.Slice(data.DailyUsage, func(i, j int) bool {
-		return data.DailyUsage[i].Date < data.DailyUsage[j].Date
-	})
-
-	return data, nil
+// promptTitle turns a prompt into a single-line session title.
+func promptTitle(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) > 80 {
+		text = string([]rune(text)[:79]) + "…"
+	}
+	return text
 }
 
-// parseSessions walks the sessions directory and parses each rollout JSONL file.
+// parseSessions walks the sessions directories and parses each rollout JSONL file.
+// A rollout that appears in several directories (e.g. both sessions/ and
+// archived_sessions/) is kept once, preferring the copy with the most turns.
 func (c *Codex) parseSessions() ([]codexSession, error) {
 	var sessions []codexSession
+	byID := make(map[string]int)
 
-	err := filepath.Walk(c.SessionsDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil // skip unreadable entries
-		}
-		if info.IsDir() || !strings.HasSuffix(path, ".jsonl") {
-			return nil
-		}
+	for _, dir := range c.SessionsDirs {
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return nil // skip unreadable entries
+			}
+			if info.IsDir() || !strings.HasSuffix(path, ".jsonl") {
+				return nil
+			}
 
-		s, err := c.parseRolloutFile(path)
+			s, err := c.parseRolloutFile(path)
+			if err != nil {
+				return nil // skip unparseable files
+			}
+			if s.id != "" {
+				if i, ok := byID[s.id]; ok {
+					if len(s.turns) > len(sessions[i].turns) {
+						sessions[i] = s
+					}
+					return nil
+				}
+				byID[s.id] = len(sessions)
+			}
+			sessions = append(sessions, s)
+			return nil
+		})
 		if err != nil {
-			return nil // skip unparseable files
+			return nil, err
 		}
-		sessions = append(sessions, s)
-		return nil
-	})
-	if err != nil {
-		return nil, err
 	}
 
 	return sessions, nil
@@ -261,6 +364,8 @@ func (c *Codex) parseRolloutFile(path string) (codexSession, error) {
 	s.dateKey = dateKey
 
 	var firstTimestamp, lastTimestamp string
+	var prevTotal codexTokenUsage
+	var currentModel string
 
 	for scanner.Scan() {
 		line := scanner.Bytes()
@@ -304,6 +409,7 @@ func (c *Codex) parseRolloutFile(path string) (codexSession, error) {
 			if err := json.Unmarshal(line, &tc); err == nil {
 				if tc.Payload.Model != "" {
 					s.modelName = tc.Payload.Model
+					currentModel = tc.Payload.Model
 				}
 				if tc.Payload.CWD != "" {
 					s.project = tc.Payload.CWD
@@ -320,8 +426,18 @@ func (c *Codex) parseRolloutFile(path string) (codexSession, error) {
 				if evt.Payload.Type == "token_count" && evt.Payload.Info != nil {
 					var info codexTokenInfo
 					if err := json.Unmarshal(evt.Payload.Info, &info); err == nil {
-						if info.TotalTokenUsage.TotalTokens > 0 {
-							s.tokens = info // cumulative; last one wins
+						// Repeated events with an unchanged cumulative total carry no new usage.
+						if info.TotalTokenUsage.TotalTokens > 0 && info.TotalTokenUsage != prevTotal {
+							delta := info.TotalTokenUsage.sub(prevTotal)
+							if info.LastTokenUsage != nil && info.LastTokenUsage.TotalTokens > 0 {
+								delta = *info.LastTokenUsage
+							}
+							s.turns = append(s.turns, codexTurn{
+								timestamp: parseCodexTime(evt.Timestamp),
+								modelName: currentModel,
+								usage:     delta,
+							})
+							prevTotal = info.TotalTokenUsage
 						}
 					}
 				}
@@ -361,29 +477,57 @@ func (c *Codex) parseRolloutFile(path string) (codexSession, error) {
 	}
 
 	// Parse timestamps.
-	if firstTimestamp != "" {
-		if t, err := time.Parse(time.RFC3339Nano, firstTimestamp); err == nil {
-			s.startTime = t
-		} else if t, err := time.Parse(time.RFC3339, firstTimestamp); err == nil {
-			s.startTime = t
-		}
-	}
-	if lastTimestamp != "" {
-		if t, err := time.Parse(time.RFC3339Nano, lastTimestamp); err == nil {
-			s.endTime = t
-		} else if t, err := time.Parse(time.RFC3339, lastTimestamp); err == nil {
-			s.endTime = t
-		}
+	s.startTime = parseCodexTime(firstTimestamp)
+	s.endTime = parseCodexTime(lastTimestamp)
+
+	// Archived rollouts are stored flat, without a YYYY/MM/DD directory.
+	if s.dateKey == "unknown" && !s.startTime.IsZero() {
+		s.dateKey = s.startTime.Local().Format("2006-01-02")
 	}
 
-	// Default model if not found.
+	// Default model if not found. Turns recorded before the first
+	// turn_context inherit the session's model.
 	if s.modelName == "" {
 		s.modelName = "codex-unknown"
 	}
+	for i := range s.turns {
+		if s.turns[i].modelName == "" {
+			s.turns[i].modelName = s.modelName
+		}
+	}
 
 	return s, nil
 }
 
+// sub returns the per-field difference between two cumulative usage totals,
+// clamped at zero in case a counter was reset.
+func (u codexTokenUsage) sub(prev codexTokenUsage) codexTokenUsage {
+	clamp := func(n int) int {
+		if n < 0 {
+			return 0
+		}
+		return n
+	}
+	return codexTokenUsage{
+		InputTokens:           clamp(u.InputTokens - prev.InputTokens),
+		CachedInputTokens:     clamp(u.CachedInputTokens - prev.CachedInputTokens),
+		OutputTokens:          clamp(u.OutputTokens - prev.OutputTokens),
+		ReasoningOutputTokens: clamp(u.ReasoningOutputTokens - prev.ReasoningOutputTokens),
+		TotalTokens:           clamp(u.TotalTokens - prev.TotalTokens),
+	}
+}
+
+// parseCodexTime parses a rollout timestamp, returning the zero time if it is empty or invalid.
+func parseCodexTime(ts string) time.Time {
+	if t, err := time.Parse(time.RFC3339Nano, ts); err == nil {
+		return t
+	}
+	if t, err := time.Parse(time.RFC3339, ts); err == nil {
+		return t
+	}
+	return time.Time{}
+}
+
 // dateKeyFromPath extracts YYYY-MM-DD from a path like .../sessions/YYYY/MM/DD/rollout-...
 func dateKeyFromPath(path string) string {
 	dir := filepath.Dir(path)
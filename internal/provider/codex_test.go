@@ -0,0 +1,112 @@
+package provider
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTestCodex() *Codex {
+	dir := filepath.Join("..", "..", "testdata", "codex")
+	return NewCodex([]string{dir})
+}
+
+func TestCodexHistory(t *testing.T) {
+	data, err := newTestCodex().Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if len(data.Sessions) != 1 {
+		t.Fatalf("got %d sessions, want 1", len(data.Sessions))
+	}
+	s := data.Sessions[0]
+	if s.Title != "Add a history parser for codex" {
+		t.Errorf("Title = %q, want first prompt flattened to one line", s.Title)
+	}
+	if s.Prompts != 2 {
+		t.Errorf("Prompts = %d, want 2", s.Prompts)
+	}
+
+	if data.Prompts == nil {
+		t.Fatal("expected prompt stats")
+	}
+	if data.Prompts.Total != 3 || data.Prompts.Sessions != 2 {
+		t.Errorf("PromptStats = %d prompts / %d sessions, want 3 / 2", data.Prompts.Total, data.Prompts.Sessions)
+	}
+	if got := data.Prompts.LengthCounts[0].Count; got != 3 {
+		t.Errorf("<50 bucket = %d, want 3", got)
+	}
+
+	var prompts int
+	for _, d := range data.DailyUsage {
+		prompts += d.Prompts
+	}
+	if prompts != 3 {
+		t.Errorf("daily prompts = %d, want 3", prompts)
+	}
+}
+
+func TestCodexTurnDeltas(t *testing.T) {
+	data, err := newTestCodex().Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	s := data.Sessions[0]
+	if len(s.Turns) != 2 {
+		t.Fatalf("got %d turns, want 2", len(s.Turns))
+	}
+
+	// Second turn is the difference between the two cumulative totals.
+	second := s.Turns[1]
+	if second.InputTokens != 2000 || second.CacheRead != 1000 || second.OutputTokens != 600 {
+		t.Errorf("second turn = %+v, want input 2000, cached 1000, output 600", second)
+	}
+	if second.Model != "gpt-5-codex" {
+		t.Errorf("turn model = %q, want gpt-5-codex", second.Model)
+	}
+	if second.Timestamp.IsZero() {
+		t.Error("expected turn timestamp")
+	}
+
+	// Session total matches the final cumulative total (input + output + reasoning).
+	if s.Tokens != 3000+800+200 {
+		t.Errorf("session tokens = %d, want 4000", s.Tokens)
+	}
+}
+
+func TestCodexTurnLastTokenUsage(t *testing.T) {
+	dir := t.TempDir()
+	dayDir := filepath.Join(dir, "2026", "03", "01")
+	if err := os.MkdirAll(dayDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	rollout := `{"timestamp":"2026-03-01T09:00:00Z","type":"session_meta","payload":{"id":"s1","timestamp":"2026-03-01T09:00:00Z","cwd":"/tmp/p"}}
+{"timestamp":"2026-03-01T09:00:01Z","type":"turn_context","payload":{"model":"o3"}}
+{"timestamp":"2026-03-01T09:00:10Z","type":"event_msg","payload":{"type":"token_count","info":{"total_token_usage":{"input_tokens":100,"output_tokens":10,"total_tokens":110},"last_token_usage":{"input_tokens":100,"output_tokens":10,"total_tokens":110}}}}
+{"timestamp":"2026-03-01T09:00:11Z","type":"event_msg","payload":{"type":"token_count","info":{"total_token_usage":{"input_tokens":100,"output_tokens":10,"total_tokens":110},"last_token_usage":{"input_tokens":100,"output_tokens":10,"total_tokens":110}}}}
+{"timestamp":"2026-03-01T09:01:00Z","type":"turn_context","payload":{"model":"o4-mini"}}
+{"timestamp":"2026-03-01T09:01:10Z","type":"event_msg","payload":{"type":"token_count","info":{"total_token_usage":{"input_tokens":150,"output_tokens":30,"total_tokens":180},"last_token_usage":{"input_tokens":50,"output_tokens":20,"total_tokens":70}}}}
+`
+	if err := os.WriteFile(filepath.Join(dayDir, "rollout-s1.jsonl"), []byte(rollout), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := (&Codex{SessionsDirs: []string{dir}}).Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	turns := data.Sessions[0].Turns
+	if len(turns) != 2 {
+		t.Fatalf("got %d turns, want 2 (duplicate token_count must be ignored)", len(turns))
+	}
+	if turns[0].Model != "o3" || turns[1].Model != "o4-mini" {
+		t.Errorf("turn models = %q, %q; want o3, o4-mini", turns[0].Model, turns[1].Model)
+	}
+	if len(data.Models) != 2 {
+		t.Errorf("got %d model breakdowns, want 2", len(data.Models))
+	} else if data.Models[0].Cost < data.Models[1].Cost {
+		t.Errorf("models not ordered by cost: %+v", data.Models)
+	}
+}
@@ -0,0 +1,234 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// CopilotPremiumMultipliers are the premium request multipliers GitHub
+// applies to Copilot Chat models on paid plans, keyed by model prefix.
+// Models missing here count as one premium request.
+var CopilotPremiumMultipliers = map[string]float64{
+	"gpt-4.1":                   0,
+	"gpt-4o":                    0,
+	"gpt-5-mini":                0,
+	"gpt-5":                     1,
+	"o3":                        1,
+	"o3-mini":                   0.33,
+	"o4-mini":                   0.33,
+	"claude-3.5-sonnet":         1,
+	"claude-3.7-sonnet":         1,
+	"claude-3.7-sonnet-thought": 1.25,
+	"claude-sonnet-4":           1,
+	"claude-haiku-4.5":          0.33,
+	"claude-opus-4":             10,
+	"claude-opus-4.5":           3,
+	"gemini-2.0-flash":          0.25,
+	"gemini-2.5-pro":            1,
+	"gemini-3-pro":              1,
+	"grok-code-fast-1":          0.25,
+}
+
+// Copilot implements Provider for GitHub Copilot Chat in VS Code. Each
+// workspace keeps its chat sessions as JSON under
+// workspaceStorage/<id>/chatSessions, next to a workspace.json naming the
+// folder; chats opened without a folder live in
+// globalStorage/emptyWindowChatSessions.
+//
+// Copilot records neither tokens nor cost, so usage is measured in requests,
+// weighted against Quota's premium request multipliers.
+type Copilot struct {
+	UserDirs []string      // Editor user data directories (see EditorUserDirs)
+	Quota    *RequestQuota // Monthly premium request allowance
+}
+
+// NewCopilot returns a Copilot provider assuming Copilot Pro's 300 premium
+// requests a month, reset on the 1st (UTC).
+func NewCopilot(userDirs []string) *Copilot {
+	return &Copilot{
+		UserDirs: userDirs,
+		Quota: &RequestQuota{
+			Name:          "Pro",
+			Limit:         300,
+			CycleStartDay: 1,
+			Multipliers:   CopilotPremiumMultipliers,
+			Exhausted:     "premium overage",
+		},
+	}
+}
+
+func (c *Copilot) Name() string  { return "Copilot" }
+func (c *Copilot) Icon() string  { return "⊙" }
+func (c *Copilot) Color() string { return "#9399b2" } // Overlay2
+
+func (c *Copilot) Available() bool {
+	return len(c.sessionDirs()) > 0
+}
+
+// copilotChatSession is a chatSessions/<sessionId>.json file.
+type copilotChatSession struct {
+	SessionID       string               `json:"sessionId"`
+	CustomTitle     string               `json:"customTitle"`
+	CreationDate    int64                `json:"creationDate"`
+	LastMessageDate int64                `json:"lastMessageDate"`
+	Requests        []copilotChatRequest `json:"requests"`
+}
+
+// copilotChatRequest is one prompt and its response within a chat session.
+type copilotChatRequest struct {
+	RequestID string `json:"requestId"`
+	Timestamp int64  `json:"timestamp"`
+	ModelID   string `json:"modelId"` // e.g. "copilot/gpt-4.1"
+	Message   struct {
+		Text string `json:"text"`
+	} `json:"message"`
+	Result *struct {
+		Timings struct {
+			TotalElapsed int64 `json:"totalElapsed"`
+		} `json:"timings"`
+		ErrorDetails *struct {
+			Message string `json:"message"`
+		} `json:"errorDetails"`
+	} `json:"result"`
+}
+
+// sessionDirs maps each chat session directory to the workspace folder it
+// belongs to ("" for chats opened without a folder).
+func (c *Copilot) sessionDirs() map[string]string {
+	dirs := make(map[string]string)
+	for _, userDir := range c.UserDirs {
+		wsDirs, _ := filepath.Glob(filepath.Join(userDir, "workspaceStorage", "*", "chatSessions"))
+		for _, dir := range wsDirs {
+			var ws cursorWorkspaceFile
+			_ = readJSON(filepath.Join(filepath.Dir(dir), "workspace.json"), &ws)
+			folder := cursorURIPath(ws.Folder)
+			if folder == "" {
+				if file := cursorURIPath(ws.Workspace); file != "" {
+					folder = filepath.Dir(file)
+				}
+			}
+			dirs[dir] = folder
+		}
+		empty := filepath.Join(userDir, "globalStorage", "emptyWindowChatSessions")
+		if info, err := os.Stat(empty); err == nil && info.IsDir() {
+			dirs[empty] = ""
+		}
+	}
+	return dirs
+}
+
+func (c *Copilot) Load() (*ProviderData, error) {
+	data := &ProviderData{
+		ProviderName: c.Name(),
+		Icon:         c.Icon(),
+		Color:        c.Color(),
+		Metadata:     make(map[string]string),
+	}
+
+	dirs := c.sessionDirs()
+	if len(dirs) == 0 {
+		return nil, fmt.Errorf("no copilot chat sessions found")
+	}
+	paths := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		paths = append(paths, dir)
+	}
+	sort.Strings(paths)
+
+	totals := newUsageTotals()
+
+	seen := make(map[string]bool)
+	byProject := make(map[string]int)
+	var requests []requestEvent
+	var promptLengths []int
+	for _, dir := range paths {
+		project := dirs[dir]
+		files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+		for _, path := range files {
+			var sess copilotChatSession
+			if readJSON(path, &sess) != nil || len(sess.Requests) == 0 {
+				continue
+			}
+			if sess.SessionID == "" {
+				sess.SessionID = strings.TrimSuffix(filepath.Base(path), ".json")
+			}
+			if seen[sess.SessionID] {
+				continue
+			}
+			seen[sess.SessionID] = true
+
+			si := SessionInfo{
+				ID:      sess.SessionID,
+				Title:   sess.CustomTitle,
+				Project: project,
+			}
+			if sess.CreationDate > 0 {
+				si.StartTime = time.UnixMilli(sess.CreationDate)
+			}
+			if sess.LastMessageDate > 0 {
+				si.EndTime = time.UnixMilli(sess.LastMessageDate)
+			}
+			for _, req := range sess.Requests {
+				ts := time.UnixMilli(req.Timestamp)
+				m := "unknown"
+				if req.ModelID != "" {
+					m = bareModelName(req.ModelID)
+				}
+				requests = append(requests, requestEvent{timestamp: ts, model: m})
+
+				si.Messages += 2
+				si.UserMessages++
+				si.Model = m
+				if r := req.Result; r != nil && r.Timings.TotalElapsed > 0 {
+					si.Requests++
+					si.Latency += time.Duration(r.Timings.TotalElapsed) * time.Millisecond
+					if r.ErrorDetails != nil {
+						si.Errors++
+					}
+				}
+				if si.StartTime.IsZero() || ts.Before(si.StartTime) {
+					si.StartTime = ts
+				}
+				if ts.After(si.EndTime) {
+					si.EndTime = ts
+				}
+				if si.Title == "" {
+					si.Title = promptTitle(req.Message.Text)
+				}
+				promptLengths = append(promptLengths, utf8.RuneCountInString(req.Message.Text))
+
+				mb := totals.model(m)
+				mb.Requests++
+
+				du := totals.day(ts)
+				du.Messages += 2
+				du.Prompts++
+			}
+			si.Prompts = si.UserMessages
+			byProject[project] += len(sess.Requests)
+			totals.day(si.StartTime).Sessions++
+
+			data.Sessions = append(data.Sessions, si)
+			totals.span(si.StartTime, si.EndTime)
+		}
+	}
+	if len(promptLengths) > 0 {
+		data.Prompts = newPromptStats(promptLengths, len(data.Sessions))
+	}
+	if len(byProject) > 0 {
+		data.Dimensions = append(data.Dimensions, projectDimension("Requests by project", byProject, "(no folder)"))
+	}
+	if c.Quota != nil && c.Quota.Limit > 0 {
+		// GitHub resets premium requests at midnight UTC.
+		data.Quota = c.Quota.status(requests, time.Now().UTC())
+	}
+
+	totals.fill(data)
+
+	return data, nil
+}
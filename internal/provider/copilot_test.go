@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCopilotChatSessions(t *testing.T) {
+	c := NewCopilot([]string{"../../testdata/vscode/User"})
+	if !c.Available() {
+		t.Fatal("expected copilot to be available")
+	}
+	data, err := c.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Sessions) != 3 {
+		t.Fatalf("expected 3 sessions, got %d", len(data.Sessions))
+	}
+
+	sessions := make(map[string]SessionInfo)
+	for _, s := range data.Sessions {
+		sessions[s.Project] = s
+	}
+	webapp := sessions["/home/dev/src/webapp"]
+	if webapp.Title != "Why does the login form submit twice?" || webapp.UserMessages != 3 {
+		t.Errorf("unexpected webapp session %+v", webapp)
+	}
+	if webapp.Requests != 3 || webapp.Errors != 1 || webapp.Latency != 19*time.Second {
+		t.Errorf("expected 3 timed requests with 1 error over 19s, got %d/%d/%s", webapp.Requests, webapp.Errors, webapp.Latency)
+	}
+	if s := sessions["/home/dev/src/infra"]; s.Title != "Terraform state locking" {
+		t.Errorf("expected custom title, got %q", s.Title)
+	}
+	if _, ok := sessions[""]; !ok {
+		t.Error("expected the empty-window chat as a session without a project")
+	}
+
+	requests := make(map[string]int)
+	for _, m := range data.Models {
+		requests[m.Model] = m.Requests
+	}
+	if requests["gpt-4.1"] != 2 || requests["claude-opus-4.1"] != 1 || requests["o4-mini"] != 1 {
+		t.Errorf("unexpected per-model requests %v", requests)
+	}
+	if len(data.Dimensions) != 1 || len(data.Dimensions[0].Items) != 3 || data.Dimensions[0].Items[0].Label != "webapp" {
+		t.Errorf("unexpected project dimension %+v", data.Dimensions)
+	}
+	if data.Quota == nil || data.Quota.Limit != 300 {
+		t.Errorf("expected the default Pro quota, got %+v", data.Quota)
+	}
+}
+
+func TestCopilotPremiumMultipliers(t *testing.T) {
+	q := RequestQuota{Multipliers: CopilotPremiumMultipliers}
+	for m, want := range map[string]float64{
+		"gpt-4.1":         0,
+		"gpt-5-mini":      0,
+		"gpt-5.1-codex":   1,
+		"claude-opus-4.1": 10,
+		"claude-opus-4.5": 3,
+		"o4-mini":         0.33,
+		"unknown":         1,
+	} {
+		if got := q.weight(m); got != want {
+			t.Errorf("weight(%q) = %v, want %v", m, got, want)
+		}
+	}
+}
@@ -0,0 +1,328 @@
+package provider
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/isaacaudet/aitop/internal/model"
+)
+
+// Crush implements Provider for Charm's Crush. Each project keeps its own
+// .crush/crush.db SQLite database; projects are found under the workspace
+// roots and in the projects.json Crush keeps in its global data dir.
+type Crush struct {
+	DataDirs       []string // Global data dirs (~/.local/share/crush)
+	WorkspaceRoots []string // Searched for git repos with a .crush/crush.db
+}
+
+func NewCrush(dataDirs, workspaceRoots []string) *Crush {
+	return &Crush{DataDirs: dataDirs, WorkspaceRoots: workspaceRoots}
+}
+
+func (c *Crush) Name() string  { return "Crush" }
+func (c *Crush) Icon() string  { return "❖" }
+func (c *Crush) Color() string { return "#f5e0dc" } // Rosewater
+
+func (c *Crush) Available() bool {
+	return len(c.databases()) > 0
+}
+
+// crushProjects is the projects.json file in Crush's global data dir.
+type crushProjects struct {
+	Projects []struct {
+		Path    string `json:"path"`
+		DataDir string `json:"data_dir"`
+	} `json:"projects"`
+}
+
+// crushSession is a row of the sessions table.
+type crushSession struct {
+	id               string
+	parentID         string
+	title            string
+	messageCount     int
+	promptTokens     int
+	completionTokens int
+	cost             float64
+	createdAt        time.Time
+	updatedAt        time.Time
+}
+
+// databases maps each crush.db found to the project directory it belongs to.
+func (c *Crush) databases() map[string]string {
+	dbs := make(map[string]string)
+	add := func(dataDir, project string) {
+		path := filepath.Join(dataDir, "crush.db")
+		if _, ok := dbs[path]; ok {
+			return
+		}
+		if _, err := os.Stat(path); err == nil {
+			dbs[path] = project
+		}
+	}
+
+	for _, dir := range c.DataDirs {
+		var projects crushProjects
+		if readJSON(filepath.Join(dir, "projects.json"), &projects) == nil {
+			for _, p := range projects.Projects {
+				dataDir := p.DataDir
+				if dataDir == "" {
+					dataDir = ".crush"
+				}
+				if !filepath.IsAbs(dataDir) {
+					dataDir = filepath.Join(p.Path, dataDir)
+				}
+				add(dataDir, p.Path)
+			}
+		}
+		add(dir, "")
+	}
+	for _, root := range c.WorkspaceRoots {
+		for _, repo := range findGitRepos(root, 3) {
+			add(filepath.Join(repo, ".crush"), repo)
+		}
+	}
+	return dbs
+}
+
+func (c *Crush) Load() (*ProviderData, error) {
+	data := &ProviderData{
+		ProviderName: c.Name(),
+		Icon:         c.Icon(),
+		Color:        c.Color(),
+		Metadata:     make(map[string]string),
+	}
+
+	dbs := c.databases()
+	if len(dbs) == 0 {
+		return nil, fmt.Errorf("no crush databases found")
+	}
+	paths := make([]string, 0, len(dbs))
+	for path := range dbs {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	totals := newUsageTotals()
+	var lastErr error
+	loaded := 0
+	for _, path := range paths {
+		if err := c.loadDB(path, dbs[path], data, totals); err != nil {
+			lastErr = err
+			continue
+		}
+		loaded++
+	}
+	if loaded == 0 {
+		return nil, lastErr
+	}
+
+	totals.fill(data)
+
+	return data, nil
+}
+
+// loadDB reads one project's sessions. Sub-agent sessions are folded into
+// the top-level session they ran under: Crush already adds a sub-agent's cost
+// to its parent session, so only their tokens are added.
+func (c *Crush) loadDB(path, project string, data *ProviderData, totals *usageTotals) error {
+	db, err := sql.Open("sqlite3", path+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf("opening crush db: %w", err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`
+		SELECT id, COALESCE(parent_session_id, ''), COALESCE(title, ''), message_count,
+		       prompt_tokens, completion_tokens, cost, created_at, updated_at
+		FROM sessions
+		ORDER BY created_at
+	`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var sessions []crushSession
+	for rows.Next() {
+		var s crushSession
+		var created, updated int64
+		if err := rows.Scan(&s.id, &s.parentID, &s.title, &s.messageCount,
+			&s.promptTokens, &s.completionTokens, &s.cost, &created, &updated); err != nil {
+			continue
+		}
+		s.createdAt = crushTime(created)
+		s.updatedAt = crushTime(updated)
+		sessions = append(sessions, s)
+	}
+
+	models := crushSessionModels(db)
+	modelOf := func(id string) string {
+		if len(models[id]) == 0 {
+			return ""
+		}
+		return models[id][0].model
+	}
+	userMessages := crushUserMessages(db)
+
+	parents := make(map[string]string, len(sessions))
+	for _, s := range sessions {
+		parents[s.id] = s.parentID
+	}
+	// root returns the top-level session a sub-agent ran under, or "" for a
+	// top-level session. Sub-agents can start sub-agents of their own.
+	root := func(s crushSession) string {
+		id, ok := rootSession(s.id, func(id string) string {
+			if _, ok := parents[parents[id]]; !ok {
+				return ""
+			}
+			return parents[id]
+		})
+		if !ok {
+			return ""
+		}
+		return id
+	}
+
+	index := make(map[string]int)
+	var infos []SessionInfo
+	for _, s := range sessions {
+		if root(s) != "" {
+			continue
+		}
+
+		m := modelOf(s.id)
+		if m == "" {
+			m = "unknown"
+		}
+		cost := s.cost
+		if cost == 0 {
+			cost = model.CalculateCost(m, model.TokenUsage{
+				InputTokens:  s.promptTokens,
+				OutputTokens: s.completionTokens,
+			})
+		}
+		c.addUsage(totals, m, s, cost, models[s.id])
+
+		du := totals.day(s.createdAt)
+		du.Sessions++
+		du.Messages += s.messageCount
+
+		index[s.id] = len(infos)
+		infos = append(infos, SessionInfo{
+			ID:           s.id,
+			Title:        s.title,
+			Project:      project,
+			StartTime:    s.createdAt,
+			EndTime:      s.updatedAt,
+			Messages:     s.messageCount,
+			UserMessages: userMessages[s.id],
+			Tokens:       s.promptTokens + s.completionTokens,
+			Cost:         cost,
+			Model:        m,
+		})
+
+		data.TotalCost += cost
+		totals.span(s.createdAt, s.updatedAt)
+	}
+
+	for _, s := range sessions {
+		parent := root(s)
+		if parent == "" {
+			continue
+		}
+		i, ok := index[parent]
+		if !ok {
+			continue
+		}
+		infos[i].Tokens += s.promptTokens + s.completionTokens
+		infos[i].Messages += s.messageCount
+		m := modelOf(s.id)
+		if m == "" {
+			m = infos[i].Model
+		}
+		c.addUsage(totals, m, s, 0, models[s.id])
+	}
+	data.Sessions = append(data.Sessions, infos...)
+	return nil
+}
+
+// addUsage attributes a session's tokens and cost to its model and to the day
+// it was last active. Crush only records usage per session, so requests are
+// counted from the assistant messages each model wrote.
+func (c *Crush) addUsage(totals *usageTotals, m string, s crushSession, cost float64, counts []crushModelCount) {
+	mb := totals.model(m)
+	mb.InputTokens += s.promptTokens
+	mb.OutputTokens += s.completionTokens
+	mb.Cost += cost
+	for _, mc := range counts {
+		totals.model(mc.model).Requests += mc.n
+	}
+
+	du := totals.day(s.updatedAt)
+	du.Tokens += s.promptTokens + s.completionTokens
+	du.Cost += cost
+}
+
+// crushModelCount is how many assistant messages a model wrote in a session.
+type crushModelCount struct {
+	model string
+	n     int
+}
+
+// crushSessionModels returns the models that wrote each session's assistant
+// messages, most messages first.
+func crushSessionModels(db *sql.DB) map[string][]crushModelCount {
+	out := make(map[string][]crushModelCount)
+	rows, err := db.Query(`
+		SELECT session_id, model, count(*) AS n
+		FROM messages
+		WHERE role = 'assistant' AND COALESCE(model, '') != ''
+		GROUP BY session_id, model
+		ORDER BY session_id, n DESC, model
+	`)
+	if err != nil {
+		return out
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id, m string
+		var n int
+		if rows.Scan(&id, &m, &n) != nil {
+			continue
+		}
+		out[id] = append(out[id], crushModelCount{m, n})
+	}
+	return out
+}
+
+// crushUserMessages counts the user messages in each session.
+func crushUserMessages(db *sql.DB) map[string]int {
+	out := make(map[string]int)
+	rows, err := db.Query(`SELECT session_id, count(*) FROM messages WHERE role = 'user' GROUP BY session_id`)
+	if err != nil {
+		return out
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		var n int
+		if rows.Scan(&id, &n) == nil {
+			out[id] = n
+		}
+	}
+	return out
+}
+
+// crushTime converts a Crush timestamp. Crush stores Unix seconds; values
+// large enough to be milliseconds are treated as such.
+func crushTime(v int64) time.Time {
+	if v > 1e12 {
+		return time.UnixMilli(v)
+	}
+	return time.Unix(v, 0)
+}
@@ -0,0 +1,93 @@
+package provider
+
+import (
+	"database/sql"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCrushSessions(t *testing.T) {
+	root := t.TempDir()
+	repo := filepath.Join(root, "tui-app")
+	if err := os.MkdirAll(filepath.Join(repo, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(repo, ".crush"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := sql.Open("sqlite3", filepath.Join(repo, ".crush", "crush.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmts := []string{
+		`CREATE TABLE sessions (id TEXT PRIMARY KEY, parent_session_id TEXT, title TEXT NOT NULL, message_count INTEGER NOT NULL DEFAULT 0,
+			prompt_tokens INTEGER NOT NULL DEFAULT 0, completion_tokens INTEGER NOT NULL DEFAULT 0, cost REAL NOT NULL DEFAULT 0.0,
+			updated_at INTEGER NOT NULL, created_at INTEGER NOT NULL, summary_message_id TEXT)`,
+		`CREATE TABLE messages (id TEXT PRIMARY KEY, session_id TEXT NOT NULL, role TEXT NOT NULL, parts TEXT NOT NULL DEFAULT '[]',
+			model TEXT, created_at INTEGER NOT NULL, updated_at INTEGER NOT NULL, finished_at INTEGER, provider TEXT)`,
+		`INSERT INTO sessions VALUES ('s1', NULL, 'Render a progress bar', 4, 12000, 3000, 0.081, 1770466200, 1770465600, NULL)`,
+		`INSERT INTO sessions VALUES ('s1-agent', 's1', 'Search for bar styles', 2, 5000, 500, 0.004, 1770466100, 1770466000, NULL)`,
+		`INSERT INTO sessions VALUES ('s1-agent-sub', 's1-agent', 'Read bar.go', 1, 1000, 100, 0.001, 1770466080, 1770466060, NULL)`,
+		`INSERT INTO sessions VALUES ('s2', NULL, 'Explain lipgloss', 2, 2000, 1000, 0, 1770552300, 1770552000, NULL)`,
+		`INSERT INTO messages VALUES ('m1', 's1', 'user', '[]', NULL, 1770465600, 1770465600, NULL, NULL)`,
+		`INSERT INTO messages VALUES ('m2', 's1', 'assistant', '[]', 'claude-sonnet-4-5', 1770465610, 1770465610, NULL, 'anthropic')`,
+		`INSERT INTO messages VALUES ('m3', 's1', 'user', '[]', NULL, 1770466000, 1770466000, NULL, NULL)`,
+		`INSERT INTO messages VALUES ('m4', 's1', 'assistant', '[]', 'claude-sonnet-4-5', 1770466010, 1770466010, NULL, 'anthropic')`,
+		`INSERT INTO messages VALUES ('m5', 's1-agent', 'assistant', '[]', 'claude-haiku-4-5', 1770466050, 1770466050, NULL, 'anthropic')`,
+		`INSERT INTO messages VALUES ('m8', 's1-agent-sub', 'assistant', '[]', 'claude-haiku-4-5', 1770466070, 1770466070, NULL, 'anthropic')`,
+		`INSERT INTO messages VALUES ('m6', 's2', 'user', '[]', NULL, 1770552000, 1770552000, NULL, NULL)`,
+		`INSERT INTO messages VALUES ('m7', 's2', 'assistant', '[]', 'gpt-4.1', 1770552010, 1770552010, NULL, 'openai')`,
+	}
+	for _, s := range stmts {
+		if _, err := db.Exec(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+
+	c := NewCrush(nil, []string{root})
+	if !c.Available() {
+		t.Fatal("expected Crush to be available")
+	}
+	data, err := c.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Sub-agent sessions, including the sub-agent's own sub-agent, fold into
+	// the top-level session.
+	if len(data.Sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(data.Sessions))
+	}
+	s := data.Sessions[0]
+	if s.Project != repo || s.Title != "Render a progress bar" || s.Model != "claude-sonnet-4-5" {
+		t.Errorf("unexpected session %+v", s)
+	}
+	if s.Tokens != 15000+5500+1100 || s.UserMessages != 2 {
+		t.Errorf("expected parent and sub-agent tokens with 2 prompts, got %d and %d", s.Tokens, s.UserMessages)
+	}
+	// Crush already counts the sub-agent's cost in the parent.
+	if math.Abs(data.TotalCost-0.081-data.Sessions[1].Cost) > 1e-9 {
+		t.Errorf("unexpected total cost $%.4f", data.TotalCost)
+	}
+	if data.Sessions[1].Cost <= 0 {
+		t.Error("expected the session without a recorded cost to be priced")
+	}
+
+	models := make(map[string]int)
+	requests := make(map[string]int)
+	for _, m := range data.Models {
+		models[m.Model] = m.InputTokens
+		requests[m.Model] = m.Requests
+	}
+	if models["claude-haiku-4-5"] != 6000 || models["claude-sonnet-4-5"] != 12000 {
+		t.Errorf("unexpected model breakdown %v", models)
+	}
+	// One request per assistant message, not per session.
+	if requests["claude-sonnet-4-5"] != 2 || requests["claude-haiku-4-5"] != 2 || requests["gpt-4.1"] != 1 {
+		t.Errorf("unexpected request counts %v", requests)
+	}
+}
@@ -10,16 +10,38 @@ import (
 	_ "github.com/mattn/go-sqlite3"
 )
 
-// Cursor implements Provider for Cursor IDE.
+// Cursor implements Provider for Cursor IDE. It combines two local sources:
+// the AI code tracking DB (code generations by file) and the editor's global
+// state DB (composer conversations with models and token counts).
 type Cursor struct {
-	DBPath string
+	DBPath      string        // ~/.cursor/ai-tracking/ai-code-tracking.db
+	StateDBPath string        // <user config>/Cursor/User/globalStorage/state.vscdb
+	Quota       *RequestQuota // Plan request allowance; optional
+
+	// WorkspaceStorageDir holds one directory per opened workspace, used to
+	// attribute conversations and generations to project folders.
+	WorkspaceStorageDir string // <user config>/Cursor/User/workspaceStorage
 }
 
 func NewCursor() *Cursor {
 	home, _ := os.UserHomeDir()
 	return &Cursor{
-		DBPath: filepath.Join(home, ".cursor", "ai-tracking", "ai-code-tracking.db"),
+		DBPath:      filepath.Join(home, ".cursor", "ai-tracking", "ai-code-tracking.db"),
+		StateDBPath: filepath.Join(cursorUserDir(), "globalStorage", "state.vscdb"),
+
+		WorkspaceStorageDir: filepath.Join(cursorUserDir(), "workspaceStorage"),
+	}
+}
+
+// cursorUserDir returns Cursor's VS Code-style user data directory:
+// ~/Library/Application Support/Cursor/User on macOS, ~/.config/Cursor/User
+// on Linux and %APPDATA%\Cursor\User on Windows.
+func cursorUserDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
 	}
+	return filepath.Join(dir, "Cursor", "User")
 }
 
 func (c *Cursor) Name() string  { return "Cursor" }
@@ -27,29 +49,56 @@ func (c *Cursor) Icon() string  { return "⌘" }
 func (c *Cursor) Color() string { return "#f9e2af" } // Yellow
 
 func (c *Cursor) Available() bool {
-	_, err := os.Stat(c.DBPath)
+	if _, err := os.Stat(c.DBPath); err == nil {
+		return true
+	}
+	_, err := os.Stat(c.StateDBPath)
 	return err == nil
 }
 
 func (c *Cursor) Load() (*ProviderData, error) {
-	db, err := sql.Open("sqlite3", c.DBPath+"?mode=ro")
-	if err != nil {
-		return nil, fmt.Errorf("opening cursor db: %w", err)
-	}
-	defer db.Close()
-
 	data := &ProviderData{
 		ProviderName: c.Name(),
 		Icon:         c.Icon(),
 		Color:        c.Color(),
 		Metadata:     make(map[string]string),
 	}
+	totals := newUsageTotals()
+	projects := c.loadProjects()
+
+	trackingErr := c.loadTracking(data, totals, projects)
+	requests, stateErr := c.loadState(data, totals, projects)
+	if trackingErr != nil && stateErr != nil {
+		return nil, trackingErr
+	}
+	if stateErr != nil {
+		data.Metadata["note"] = "Cursor composer history (state.vscdb) not found; token and cost data unavailable."
+	} else if c.Quota != nil && c.Quota.Limit > 0 {
+		data.Quota = c.Quota.status(requests, time.Now())
+	}
+
+	totals.fill(data)
+
+	return data, nil
+}
+
+// loadTracking reads code generations and conversation summaries from the AI
+// code tracking DB.
+func (c *Cursor) loadTracking(data *ProviderData, totals *usageTotals, projects *cursorProjects) error {
+	if _, err := os.Stat(c.DBPath); err != nil {
+		return err
+	}
+	db, err := sql.Open("sqlite3", c.DBPath+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf("opening cursor db: %w", err)
+	}
+	defer db.Close()
 
 	// Total code generations.
 	var totalGens int
 	err = db.QueryRow("SELECT count(*) FROM ai_code_hashes").Scan(&totalGens)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	data.Generations = totalGens
 
@@ -61,7 +110,7 @@ func (c *Cursor) Load() (*ProviderData, error) {
 		ORDER BY day
 	`)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
 
@@ -71,13 +120,11 @@ func (c *Cursor) Load() (*ProviderData, error) {
 		if err := rows.Scan(&day, &cnt); err != nil {
 			continue
 		}
-		data.DailyUsage = append(data.DailyUsage, DailyUsage{
-			Date:        day,
-			Generations: cnt,
-		})
+		du := totals.date(day)
+		du.Generations += cnt
 	}
 
-	// Generations by file extension (as "model" breakdown).
+	// Generations by file extension, kept apart from the per-model breakdown.
 	extRows, err := db.Query(`
 		SELECT COALESCE(fileExtension, 'unknown') as ext, count(*) as cnt
 		FROM ai_code_hashes
@@ -85,47 +132,75 @@ func (c *Cursor) Load() (*ProviderData, error) {
 		ORDER BY cnt DESC
 	`)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer extRows.Close()
 
+	byExt := Dimension{Name: "Generations by file type"}
 	for extRows.Next() {
 		var ext string
 		var cnt int
 		if err := extRows.Scan(&ext, &cnt); err != nil {
 			continue
 		}
-		data.Models = append(data.Models, ModelBreakdown{
-			Model:       ext,
-			Generations: cnt,
-		})
+		byExt.Items = append(byExt.Items, DimensionItem{Label: ext, Count: cnt})
+	}
+	if len(byExt.Items) > 0 {
+		data.Dimensions = append(data.Dimensions, byExt)
+	}
+
+	// Generations by project, from the file each generation was written to.
+	// Older tracking DBs don't record file names.
+	if hasColumn(db, "ai_code_hashes", "fileName") {
+		byProject := make(map[string]int)
+		fileRows, err := db.Query(`
+			SELECT COALESCE(fileName, ''), COALESCE(conversationId, ''), count(*)
+			FROM ai_code_hashes
+			GROUP BY fileName, conversationId
+		`)
+		if err == nil {
+			for fileRows.Next() {
+				var file, conv string
+				var cnt int
+				if err := fileRows.Scan(&file, &conv, &cnt); err != nil {
+					continue
+				}
+				project := projects.forFile(file)
+				projects.addGenerations(conv, project, cnt)
+				if project == "" {
+					project = "(unknown)"
+				}
+				byProject[project] += cnt
+			}
+			fileRows.Close()
+		}
+		if len(byProject) > 0 {
+			data.Dimensions = append(data.Dimensions, projectDimension("Generations by project", byProject, "(unknown)"))
+		}
 	}
 
 	// Conversation summaries as sessions.
 	sessRows, err := db.Query(`
-		SELECT conversationId, COALESCE(title, ''), COALESCE(model, ''),
-		       COALESCE(mode, ''), updatedAt
+		SELECT conversationId, COALESCE(title, ''), COALESCE(model, ''), updatedAt
 		FROM conversation_summaries
 		ORDER BY updatedAt DESC
 	`)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer sessRows.Close()
 
 	for sessRows.Next() {
-		var id, title, model, mode string
+		var id, title, model string
 		var updatedAt int64
-		if err := sessRows.Scan(&id, &title, &model, &mode, &updatedAt); err != nil {
+		if err := sessRows.Scan(&id, &title, &model, &updatedAt); err != nil {
 			continue
 		}
 		t := time.UnixMilli(updatedAt)
-		project := title
-		if project == "" {
-			project = mode
-		}
+		project := projects.forConversation(id)
 		data.Sessions = append(data.Sessions, SessionInfo{
 			ID:        id,
+			Title:     title,
 			Project:   project,
 			StartTime: t,
 			EndTime:   t,
@@ -136,11 +211,8 @@ func (c *Cursor) Load() (*ProviderData, error) {
 	// Date range.
 	var minTs, maxTs sql.NullInt64
 	db.QueryRow("SELECT min(createdAt), max(createdAt) FROM ai_code_hashes").Scan(&minTs, &maxTs)
-	if minTs.Valid {
-		data.FirstSeen = time.UnixMilli(minTs.Int64)
-	}
-	if maxTs.Valid {
-		data.LastSeen = time.UnixMilli(maxTs.Int64)
+	if minTs.Valid && maxTs.Valid {
+		totals.span(time.UnixMilli(minTs.Int64), time.UnixMilli(maxTs.Int64))
 	}
 
 	// Source breakdown metadata.
@@ -156,9 +228,21 @@ func (c *Cursor) Load() (*ProviderData, error) {
 		}
 	}
 
-	// Note: Cursor doesn't expose token counts or costs locally.
-	// Cost estimation is not possible without API access.
-	data.Metadata["note"] = "Cursor tracks code generations, not token usage. Cost requires Cursor billing API."
+	return nil
+}
 
-	return data, nil
+// hasColumn reports whether table has the named column.
+func hasColumn(db *sql.DB, table, column string) bool {
+	rows, err := db.Query("SELECT name FROM pragma_table_info(?)", table)
+	if err != nil {
+		return false
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if rows.Scan(&name) == nil && name == column {
+			return true
+		}
+	}
+	return false
 }
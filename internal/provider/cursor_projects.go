@@ -0,0 +1,199 @@
+package provider
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// cursorProjects attributes Cursor generations and conversations to project
+// directories. Workspace folders come from workspaceStorage/*/workspace.json;
+// each workspace's own state.vscdb lists the composers opened in it.
+type cursorProjects struct {
+	folders   []string          // Workspace folders, longest first
+	composers map[string]string // composerId -> workspace folder
+	gitRoots  map[string]string // Directory -> enclosing git repo ("" if none)
+
+	// Generations per project for each tracked conversation, used when a
+	// conversation's workspace isn't recorded.
+	conversations map[string]map[string]int
+}
+
+// cursorWorkspaceFile is workspaceStorage/<id>/workspace.json.
+type cursorWorkspaceFile struct {
+	Folder    string `json:"folder"`
+	Workspace string `json:"workspace"` // Path to a .code-workspace file
+}
+
+// loadProjects reads every workspace under WorkspaceStorageDir. A missing
+// directory yields an empty resolver that falls back to git repo detection.
+func (c *Cursor) loadProjects() *cursorProjects {
+	p := &cursorProjects{
+		composers:     make(map[string]string),
+		gitRoots:      make(map[string]string),
+		conversations: make(map[string]map[string]int),
+	}
+	if c.WorkspaceStorageDir == "" {
+		return p
+	}
+	entries, err := os.ReadDir(c.WorkspaceStorageDir)
+	if err != nil {
+		return p
+	}
+
+	seen := make(map[string]bool)
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		wsDir := filepath.Join(c.WorkspaceStorageDir, e.Name())
+		raw, err := os.ReadFile(filepath.Join(wsDir, "workspace.json"))
+		if err != nil {
+			continue
+		}
+		var ws cursorWorkspaceFile
+		if json.Unmarshal(raw, &ws) != nil {
+			continue
+		}
+		folder := cursorURIPath(ws.Folder)
+		if folder == "" {
+			if file := cursorURIPath(ws.Workspace); file != "" {
+				folder = filepath.Dir(file)
+			}
+		}
+		if folder == "" {
+			continue
+		}
+		if !seen[folder] {
+			seen[folder] = true
+			p.folders = append(p.folders, folder)
+		}
+		for _, id := range workspaceComposers(filepath.Join(wsDir, "state.vscdb")) {
+			p.composers[id] = folder
+		}
+	}
+	sort.Slice(p.folders, func(i, j int) bool { return len(p.folders[i]) > len(p.folders[j]) })
+	return p
+}
+
+// cursorURIPath converts a workspace URI such as file:///Users/me/proj (or a
+// vscode-remote:// URI) to a filesystem path.
+func cursorURIPath(uri string) string {
+	if uri == "" {
+		return ""
+	}
+	u, err := url.Parse(uri)
+	if err != nil || u.Path == "" {
+		return ""
+	}
+	return filepath.Clean(filepath.FromSlash(u.Path))
+}
+
+// workspaceComposers lists the composer ids stored in a workspace's state.vscdb.
+func workspaceComposers(path string) []string {
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+	db, err := sql.Open("sqlite3", path+"?mode=ro")
+	if err != nil {
+		return nil
+	}
+	defer db.Close()
+
+	var value []byte
+	if err := db.QueryRow(`SELECT value FROM ItemTable WHERE key = 'composer.composerData'`).Scan(&value); err != nil {
+		return nil
+	}
+	var data struct {
+		AllComposers []struct {
+			ComposerID string `json:"composerId"`
+		} `json:"allComposers"`
+	}
+	if json.Unmarshal(value, &data) != nil {
+		return nil
+	}
+	ids := make([]string, 0, len(data.AllComposers))
+	for _, comp := range data.AllComposers {
+		if comp.ComposerID != "" {
+			ids = append(ids, comp.ComposerID)
+		}
+	}
+	return ids
+}
+
+// forFile returns the project containing path: the deepest workspace folder,
+// else the enclosing git repository, else "".
+func (p *cursorProjects) forFile(path string) string {
+	if path == "" {
+		return ""
+	}
+	path = filepath.Clean(path)
+	for _, folder := range p.folders {
+		if path == folder || strings.HasPrefix(path, folder+string(filepath.Separator)) {
+			return folder
+		}
+	}
+	if !filepath.IsAbs(path) {
+		return ""
+	}
+	return p.gitRoot(filepath.Dir(path))
+}
+
+// gitRoot walks up from dir looking for a .git entry, caching every
+// directory visited along the way.
+func (p *cursorProjects) gitRoot(dir string) string {
+	var visited []string
+	root := ""
+	for {
+		if r, ok := p.gitRoots[dir]; ok {
+			root = r
+			break
+		}
+		visited = append(visited, dir)
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			root = dir
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	for _, d := range visited {
+		p.gitRoots[d] = root
+	}
+	return root
+}
+
+// addGenerations records that a conversation produced n generations in project.
+func (p *cursorProjects) addGenerations(conversationID, project string, n int) {
+	if conversationID == "" || project == "" {
+		return
+	}
+	m, ok := p.conversations[conversationID]
+	if !ok {
+		m = make(map[string]int)
+		p.conversations[conversationID] = m
+	}
+	m[project] += n
+}
+
+// forConversation returns the project of a composer conversation: its
+// workspace when recorded, else the project most of its generations landed in.
+func (p *cursorProjects) forConversation(id string) string {
+	if folder, ok := p.composers[id]; ok {
+		return folder
+	}
+	best, bestN := "", 0
+	for project, n := range p.conversations[id] {
+		if n > bestN || (n == bestN && project < best) {
+			best, bestN = project, n
+		}
+	}
+	return best
+}
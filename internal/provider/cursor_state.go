@@ -0,0 +1,283 @@
+package provider
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/isaacaudet/aitop/internal/model"
+)
+
+// cursorComposer is a composerData:<id> record from state.vscdb's cursorDiskKV
+// table. Older Cursor versions inline the bubbles in Conversation; newer ones
+// store each bubble under its own bubbleId:<composerId>:<bubbleId> key.
+type cursorComposer struct {
+	ComposerID    string         `json:"composerId"`
+	Name          string         `json:"name"`
+	CreatedAt     cursorTime     `json:"createdAt"`
+	LastUpdatedAt cursorTime     `json:"lastUpdatedAt"`
+	UnifiedMode   string         `json:"unifiedMode"`
+	Conversation  []cursorBubble `json:"conversation"`
+	ModelConfig   struct {
+		ModelName string `json:"modelName"`
+	} `json:"modelConfig"`
+}
+
+// cursorBubble is a single message in a composer conversation.
+type cursorBubble struct {
+	Type       int        `json:"type"` // 1 = user, 2 = AI
+	CreatedAt  cursorTime `json:"createdAt"`
+	TokenCount struct {
+		InputTokens  int `json:"inputTokens"`
+		OutputTokens int `json:"outputTokens"`
+	} `json:"tokenCount"`
+	ModelInfo struct {
+		ModelName string `json:"modelName"`
+	} `json:"modelInfo"`
+}
+
+const (
+	cursorBubbleUser = 1
+	cursorBubbleAI   = 2
+)
+
+// cursorTime accepts both the epoch-millisecond numbers and ISO 8601 strings
+// Cursor has used for timestamps across versions.
+type cursorTime struct{ time.Time }
+
+func (t *cursorTime) UnmarshalJSON(b []byte) error {
+	if ms, err := strconv.ParseInt(string(b), 10, 64); err == nil {
+		t.Time = time.UnixMilli(ms)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil // unknown format; leave zero
+	}
+	if ts, err := time.Parse(time.RFC3339, s); err == nil {
+		t.Time = ts
+	}
+	return nil
+}
+
+// loadComposers reads every composer conversation and its bubbles from state.vscdb.
+func loadComposers(path string) ([]cursorComposer, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite3", path+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("opening cursor state db: %w", err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`SELECT key, value FROM cursorDiskKV WHERE key LIKE 'composerData:%'`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byID := make(map[string]*cursorComposer)
+	var composers []*cursorComposer
+	for rows.Next() {
+		var key string
+		var value []byte
+		if err := rows.Scan(&key, &value); err != nil || len(value) == 0 {
+			continue
+		}
+		var comp cursorComposer
+		if err := json.Unmarshal(value, &comp); err != nil {
+			continue
+		}
+		if comp.ComposerID == "" {
+			comp.ComposerID = strings.TrimPrefix(key, "composerData:")
+		}
+		byID[comp.ComposerID] = &comp
+		composers = append(composers, &comp)
+	}
+
+	bubbleRows, err := db.Query(`SELECT key, value FROM cursorDiskKV WHERE key LIKE 'bubbleId:%'`)
+	if err == nil {
+		defer bubbleRows.Close()
+		for bubbleRows.Next() {
+			var key string
+			var value []byte
+			if err := bubbleRows.Scan(&key, &value); err != nil || len(value) == 0 {
+				continue
+			}
+			// Key format: bubbleId:<composerId>:<bubbleId>
+			parts := strings.SplitN(key, ":", 3)
+			if len(parts) != 3 {
+				continue
+			}
+			comp, ok := byID[parts[1]]
+			if !ok {
+				continue
+			}
+			var b cursorBubble
+			if err := json.Unmarshal(value, &b); err != nil {
+				continue
+			}
+			comp.Conversation = append(comp.Conversation, b)
+		}
+	}
+
+	out := make([]cursorComposer, 0, len(composers))
+	for _, comp := range composers {
+		sort.SliceStable(comp.Conversation, func(i, j int) bool {
+			return comp.Conversation[i].CreatedAt.Before(comp.Conversation[j].CreatedAt.Time)
+		})
+		out = append(out, *comp)
+	}
+	return out, nil
+}
+
+// loadState adds composer conversations, per-model token usage and costs from
+// state.vscdb, attributing each conversation to its workspace's project.
+// Sessions already known from the tracking DB are enriched in place. It returns every request made, for quota tracking.
+func (c *Cursor) loadState(data *ProviderData, totals *usageTotals, projects *cursorProjects) ([]requestEvent, error) {
+	composers, err := loadComposers(c.StateDBPath)
+	if err != nil {
+		return nil, err
+	}
+	var requests []requestEvent
+
+	known := make(map[string]int)
+	for i, s := range data.Sessions {
+		known[s.ID] = i
+	}
+	for _, comp := range composers {
+		start := comp.CreatedAt.Time
+		end := comp.LastUpdatedAt.Time
+		if end.IsZero() {
+			end = start
+		}
+
+		si := SessionInfo{
+			ID:        comp.ComposerID,
+			Title:     comp.Name,
+			Project:   projects.forConversation(comp.ComposerID),
+			StartTime: start,
+			EndTime:   end,
+			Model:     cursorModelName(comp.ModelConfig.ModelName),
+		}
+
+		// Each user message is one request, billed to the model that answers it.
+		var pending *time.Time
+		for _, b := range comp.Conversation {
+			si.Messages++
+			if b.Type == cursorBubbleUser {
+				si.UserMessages++
+				ts := b.CreatedAt.Time
+				if ts.IsZero() {
+					ts = start
+				}
+				if pending != nil {
+					requests = append(requests, cursorRequest(totals, *pending, si.Model))
+				}
+				pending = &ts
+				continue
+			}
+			if b.Type != cursorBubbleAI {
+				continue
+			}
+
+			in, out := b.TokenCount.InputTokens, b.TokenCount.OutputTokens
+			m := cursorModelName(b.ModelInfo.ModelName)
+			if m == "" {
+				m = si.Model
+			}
+			if m == "" {
+				m = "default"
+			}
+			if pending != nil {
+				requests = append(requests, cursorRequest(totals, *pending, m))
+				pending = nil
+			}
+			usage := model.TokenUsage{InputTokens: in, OutputTokens: out}
+			cost := model.CalculateCost(m, usage)
+
+			mb := totals.model(m)
+			mb.InputTokens += in
+			mb.OutputTokens += out
+			mb.Cost += cost
+			mb.Generations++
+
+			si.Tokens += in + out
+			si.Cost += cost
+			si.Model = m
+			ts := b.CreatedAt.Time
+			si.Turns = append(si.Turns, TurnUsage{
+				Timestamp:    ts,
+				Model:        m,
+				InputTokens:  in,
+				OutputTokens: out,
+				Cost:         cost,
+			})
+
+			if ts.IsZero() {
+				ts = start
+			}
+			if !ts.IsZero() {
+				du := totals.day(ts)
+				du.Tokens += in + out
+				du.Cost += cost
+				du.Messages++
+			}
+		}
+		if pending != nil {
+			requests = append(requests, cursorRequest(totals, *pending, si.Model))
+		}
+
+		if !start.IsZero() {
+			totals.day(start).Sessions++
+		}
+		totals.span(start, end)
+		data.TotalCost += si.Cost
+
+		if i, ok := known[si.ID]; ok {
+			prev := data.Sessions[i]
+			if si.Title == "" {
+				si.Title = prev.Title
+			}
+			if si.Model == "" {
+				si.Model = prev.Model
+			}
+			data.Sessions[i] = si
+			continue
+		}
+		data.Sessions = append(data.Sessions, si)
+	}
+
+	return requests, nil
+}
+
+// cursorRequest records a request against m in the model breakdown.
+func cursorRequest(totals *usageTotals, ts time.Time, m string) requestEvent {
+	if m == "" {
+		m = "default"
+	}
+	mb := totals.model(m)
+	mb.Requests++
+	return requestEvent{timestamp: ts, model: m}
+}
+
+// cursorClaudeRe matches Cursor's version-first Claude names such as
+// "claude-4.5-sonnet-thinking".
+var cursorClaudeRe = regexp.MustCompile(`^claude-(\d+(?:[.-]\d+)?)-(opus|sonnet|haiku)(.*)$`)
+
+// cursorModelName rewrites Cursor's model ids into the names the pricing table
+// understands, e.g. "claude-4.5-sonnet" becomes "claude-sonnet-4-5".
+func cursorModelName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if m := cursorClaudeRe.FindStringSubmatch(name); m != nil {
+		return "claude-" + m[2] + "-" + strings.ReplaceAll(m[1], ".", "-") + m[3]
+	}
+	return name
+}
@@ -0,0 +1,280 @@
+package provider
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// CustomSpec declares a provider read from JSON or JSONL usage records, for
+// tools aitop has no built-in support for. Each record that passes the
+// filters is one model response.
+type CustomSpec struct {
+	Name         string
+	Icon         string
+	Color        string
+	Files        []string // Glob patterns; ** matches any number of directories
+	Format       string   // "jsonl" (default) or "json"
+	Records      string   // Path to the record array in a JSON file; empty for the top level
+	DefaultModel string   // Used for records without a model
+	Fields       CustomFields
+	Filters      []string // Predicates such as `type == "assistant"`, all of which must hold
+}
+
+// CustomFields holds the JSON paths of a record's fields. Only Timestamp and
+// at least one token field are needed; records without a session are grouped
+// by file, and those without a cost are priced by model.
+type CustomFields struct {
+	Timestamp  string
+	Session    string
+	Project    string
+	Model      string
+	Input      string
+	Output     string
+	CacheRead  string
+	CacheWrite string
+	Cost       string
+}
+
+// Custom implements Provider for a CustomSpec.
+type Custom struct {
+	Spec CustomSpec
+}
+
+func NewCustom(spec CustomSpec) *Custom {
+	if spec.Icon == "" {
+		spec.Icon = "◌"
+	}
+	if spec.Color == "" {
+		spec.Color = "#7f849c" // Overlay1
+	}
+	return &Custom{Spec: spec}
+}
+
+func (c *Custom) Name() string  { return c.Spec.Name }
+func (c *Custom) Icon() string  { return c.Spec.Icon }
+func (c *Custom) Color() string { return c.Spec.Color }
+
+func (c *Custom) Available() bool {
+	return len(c.files()) > 0
+}
+
+// customPaths are the compiled field paths of a spec.
+type customPaths struct {
+	timestamp, session, project, model         jsonPath
+	input, output, cacheRead, cacheWrite, cost jsonPath
+	records                                    jsonPath
+	filters                                    []recordFilter
+}
+
+func (c *Custom) compile() (*customPaths, error) {
+	var p customPaths
+	for _, f := range []struct {
+		dst  *jsonPath
+		path string
+		name string
+	}{
+		{&p.timestamp, c.Spec.Fields.Timestamp, "timestamp"},
+		{&p.session, c.Spec.Fields.Session, "session"},
+		{&p.project, c.Spec.Fields.Project, "project"},
+		{&p.model, c.Spec.Fields.Model, "model"},
+		{&p.input, c.Spec.Fields.Input, "input_tokens"},
+		{&p.output, c.Spec.Fields.Output, "output_tokens"},
+		{&p.cacheRead, c.Spec.Fields.CacheRead, "cache_read_tokens"},
+		{&p.cacheWrite, c.Spec.Fields.CacheWrite, "cache_write_tokens"},
+		{&p.cost, c.Spec.Fields.Cost, "cost"},
+		{&p.records, c.Spec.Records, "records"},
+	} {
+		path, err := parseJSONPath(f.path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", c.Spec.Name, f.name, err)
+		}
+		*f.dst = path
+	}
+	if p.timestamp == nil {
+		return nil, fmt.Errorf("%s: no timestamp field configured", c.Spec.Name)
+	}
+	if p.input == nil && p.output == nil && p.cacheRead == nil && p.cacheWrite == nil && p.cost == nil {
+		return nil, fmt.Errorf("%s: no token or cost fields configured", c.Spec.Name)
+	}
+	for _, s := range c.Spec.Filters {
+		f, err := parseRecordFilter(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s: filter %q: %w", c.Spec.Name, s, err)
+		}
+		p.filters = append(p.filters, f)
+	}
+	return &p, nil
+}
+
+func (c *Custom) Load() (*ProviderData, error) {
+	data := &ProviderData{
+		ProviderName: c.Name(),
+		Icon:         c.Icon(),
+		Color:        c.Color(),
+		Metadata:     make(map[string]string),
+	}
+
+	paths, err := c.compile()
+	if err != nil {
+		return nil, err
+	}
+	files := c.files()
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files match %s", strings.Join(c.Spec.Files, ", "))
+	}
+
+	agg := newEventAggregator()
+	var lastErr error
+	for _, path := range files {
+		records, err := c.readRecords(path, paths.records)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+	records:
+		for _, rec := range records {
+			for _, f := range paths.filters {
+				if !f.match(rec) {
+					continue records
+				}
+			}
+			ev := usageEvent{
+				Timestamp: paths.timestamp.time(rec),
+				Session:   paths.session.str(rec),
+				Project:   paths.project.str(rec),
+				Model:     paths.model.str(rec),
+			}
+			if ev.Timestamp.IsZero() {
+				continue
+			}
+			if ev.Session == "" {
+				ev.Session = path
+			}
+			if ev.Model == "" {
+				ev.Model = c.Spec.DefaultModel
+			}
+			if n, ok := paths.input.num(rec); ok {
+				ev.Usage.InputTokens = int(n)
+			}
+			if n, ok := paths.output.num(rec); ok {
+				ev.Usage.OutputTokens = int(n)
+			}
+			if n, ok := paths.cacheRead.num(rec); ok {
+				ev.Usage.CacheRead = int(n)
+			}
+			if n, ok := paths.cacheWrite.num(rec); ok {
+				ev.Usage.CacheWrite = int(n)
+			}
+			ev.Cost, ev.HasCost = paths.cost.num(rec)
+			agg.add(ev)
+		}
+	}
+	if len(agg.sessions) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+	agg.fill(data)
+
+	return data, nil
+}
+
+// readRecords parses a file into records: one per line for JSONL, or the
+// elements of the array at recordsPath for JSON.
+func (c *Custom) readRecords(path string, recordsPath jsonPath) ([]any, error) {
+	if strings.EqualFold(c.Spec.Format, "json") {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var doc any
+		if err := json.Unmarshal(raw, &doc); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+		v, _ := recordsPath.lookup(doc)
+		records, ok := v.([]any)
+		if !ok {
+			return nil, fmt.Errorf("%s: no record array found", path)
+		}
+		return records, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []any
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 1024*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var rec any
+		if json.Unmarshal(scanner.Bytes(), &rec) == nil {
+			records = append(records, rec)
+		}
+	}
+	return records, scanner.Err()
+}
+
+// files expands the spec's glob patterns.
+func (c *Custom) files() []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, pattern := range c.Spec.Files {
+		for _, path := range globRecursive(pattern) {
+			if !seen[path] {
+				seen[path] = true
+				out = append(out, path)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// globRecursive is filepath.Glob with support for ** as a path segment
+// matching zero or more directories. Wildcards before the ** are expanded
+// first and each matching directory is walked.
+func globRecursive(pattern string) []string {
+	root, rest, ok := strings.Cut(filepath.ToSlash(pattern), "/**")
+	if !ok {
+		matches, _ := filepath.Glob(pattern)
+		return matches
+	}
+	rest = strings.TrimPrefix(rest, "/")
+	if rest == "" {
+		rest = "*"
+	}
+	roots, _ := filepath.Glob(filepath.FromSlash(root))
+	var out []string
+	for _, root := range roots {
+		if info, err := os.Stat(root); err != nil || !info.IsDir() {
+			continue
+		}
+		_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return nil
+			}
+			// Try the rest of the pattern against every trailing run of
+			// path segments.
+			segs := strings.Split(filepath.ToSlash(rel), "/")
+			for i := range segs {
+				if ok, _ := filepath.Match(rest, strings.Join(segs[i:], "/")); ok {
+					out = append(out, path)
+					break
+				}
+			}
+			return nil
+		})
+	}
+	return out
+}
@@ -0,0 +1,207 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// jsonPath is a parsed field path such as message.usage.input_tokens or
+// events[0].ts. A leading "$." is allowed and ignored.
+type jsonPath []any // string keys and int indexes
+
+func parseJSONPath(s string) (jsonPath, error) {
+	s = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(s), "$"), ".")
+	if s == "" {
+		return nil, nil
+	}
+	var p jsonPath
+	for _, part := range strings.Split(s, ".") {
+		key, rest, _ := strings.Cut(part, "[")
+		if key != "" {
+			p = append(p, key)
+		} else if rest == "" {
+			return nil, fmt.Errorf("empty segment in path %q", s)
+		}
+		for rest != "" {
+			idx, after, ok := strings.Cut(rest, "]")
+			n, err := strconv.Atoi(idx)
+			if !ok || err != nil || (after != "" && after[0] != '[') {
+				return nil, fmt.Errorf("bad index in path %q", s)
+			}
+			p = append(p, n)
+			rest = strings.TrimPrefix(after, "[")
+		}
+	}
+	return p, nil
+}
+
+// lookup returns the value at the path, or false if it doesn't exist.
+func (p jsonPath) lookup(v any) (any, bool) {
+	for _, seg := range p {
+		switch seg := seg.(type) {
+		case string:
+			obj, ok := v.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			if v, ok = obj[seg]; !ok {
+				return nil, false
+			}
+		case int:
+			arr, ok := v.([]any)
+			if !ok || seg >= len(arr) {
+				return nil, false
+			}
+			v = arr[seg]
+		}
+	}
+	return v, true
+}
+
+func (p jsonPath) str(v any) string {
+	switch x, _ := p.lookup(v); x := x.(type) {
+	case string:
+		return x
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(x)
+	}
+	return ""
+}
+
+func (p jsonPath) num(v any) (float64, bool) {
+	if p == nil {
+		return 0, false
+	}
+	x, _ := p.lookup(v)
+	return jsonNumber(x)
+}
+
+// time reads a timestamp stored as an RFC 3339 string or as Unix seconds,
+// milliseconds or microseconds.
+func (p jsonPath) time(v any) time.Time {
+	x, _ := p.lookup(v)
+	if s, ok := x.(string); ok {
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02 15:04:05"} {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t
+			}
+		}
+	}
+	n, ok := jsonNumber(x)
+	if !ok || n <= 0 {
+		return time.Time{}
+	}
+	switch {
+	case n >= 1e15:
+		return time.UnixMicro(int64(n))
+	case n >= 1e12:
+		return time.UnixMilli(int64(n))
+	}
+	sec, frac := math.Modf(n)
+	return time.Unix(int64(sec), int64(frac*1e9))
+}
+
+// jsonNumber reads a number, accepting numeric strings.
+func jsonNumber(x any) (float64, bool) {
+	switch x := x.(type) {
+	case float64:
+		return x, true
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(x), 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// recordFilter is a predicate on a record: `path op literal`, where the
+// literal is JSON (a bare word counts as a string), or a lone path that must
+// be present and truthy.
+type recordFilter struct {
+	path  jsonPath
+	op    string
+	value any
+}
+
+var filterOps = []string{"==", "!=", ">=", "<=", ">", "<"}
+
+func parseRecordFilter(s string) (recordFilter, error) {
+	for _, op := range filterOps {
+		lhs, rhs, ok := strings.Cut(s, op)
+		if !ok {
+			continue
+		}
+		path, err := parseJSONPath(lhs)
+		if err != nil {
+			return recordFilter{}, err
+		}
+		if path == nil {
+			return recordFilter{}, fmt.Errorf("filter %q has no field", s)
+		}
+		rhs = strings.TrimSpace(rhs)
+		var value any
+		if err := json.Unmarshal([]byte(rhs), &value); err != nil {
+			value = rhs
+		}
+		return recordFilter{path: path, op: op, value: value}, nil
+	}
+	path, err := parseJSONPath(s)
+	if err != nil {
+		return recordFilter{}, err
+	}
+	if path == nil {
+		return recordFilter{}, fmt.Errorf("empty filter")
+	}
+	return recordFilter{path: path}, nil
+}
+
+func (f recordFilter) match(rec any) bool {
+	x, ok := f.path.lookup(rec)
+	if f.op == "" {
+		return ok && x != nil && x != false && x != "" && x != 0.0
+	}
+	if f.op == "==" || f.op == "!=" {
+		eq := jsonEqual(x, f.value)
+		return eq == (f.op == "==")
+	}
+	if a, ok := jsonNumber(x); ok {
+		if b, ok := jsonNumber(f.value); ok {
+			return compareOrdered(a, b, f.op)
+		}
+	}
+	a, aok := x.(string)
+	b, bok := f.value.(string)
+	return aok && bok && compareOrdered(a, b, f.op)
+}
+
+func jsonEqual(a, b any) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if x, ok := a.(float64); ok {
+		y, ok := jsonNumber(b)
+		return ok && x == y
+	}
+	if y, ok := b.(float64); ok {
+		x, ok := jsonNumber(a)
+		return ok && x == y
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+func compareOrdered[T float64 | string](a, b T, op string) bool {
+	switch op {
+	case ">":
+		return a > b
+	case "<":
+		return a < b
+	case ">=":
+		return a >= b
+	}
+	return a <= b
+}
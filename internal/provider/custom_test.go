@@ -0,0 +1,144 @@
+package provider
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCustomJSONL(t *testing.T) {
+	c := NewCustom(CustomSpec{
+		Name:  "Acme",
+		Files: []string{"../../testdata/custom/acme/**/*.jsonl"},
+		Fields: CustomFields{
+			Timestamp: "ts",
+			Session:   "session_id",
+			Project:   "cwd",
+			Model:     "message.model",
+			Input:     "message.usage.input_tokens",
+			Output:    "message.usage.output_tokens",
+			CacheRead: "message.usage.cache_read_input_tokens",
+			Cost:      "cost_usd",
+		},
+		Filters: []string{`type == "assistant"`, `synthetic != true`},
+	})
+	if !c.Available() {
+		t.Fatal("expected the acme logs to be found")
+	}
+	if c.Icon() == "" || c.Color() == "" {
+		t.Error("expected a default icon and color")
+	}
+	data, err := c.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(data.Sessions))
+	}
+
+	api := data.Sessions[0]
+	if api.ID != "s-100" || api.Project != "/home/dev/src/api" || api.Model != "claude-sonnet-4-5" {
+		t.Errorf("unexpected session %+v", api)
+	}
+	if api.Messages != 2 || api.Tokens != 13950 || len(api.Turns) != 2 {
+		t.Errorf("expected 2 responses with 13950 tokens, got %d %d", api.Messages, api.Tokens)
+	}
+	if api.Cost <= 0 {
+		t.Error("expected responses without a cost to be priced by model")
+	}
+	if api.EndTime.Sub(api.StartTime) != 148*time.Second {
+		t.Errorf("unexpected duration %s", api.EndTime.Sub(api.StartTime))
+	}
+
+	web := data.Sessions[1]
+	if web.Cost != 0.25 || web.Tokens != 2700 {
+		t.Errorf("expected the recorded cost and numeric-string tokens, got %f %d", web.Cost, web.Tokens)
+	}
+	if !web.StartTime.Equal(time.UnixMilli(1772442000000)) {
+		t.Errorf("expected a millisecond timestamp, got %s", web.StartTime)
+	}
+	if len(data.Models) != 2 {
+		t.Errorf("expected 2 models, got %+v", data.Models)
+	}
+}
+
+func TestCustomJSONArray(t *testing.T) {
+	c := NewCustom(CustomSpec{
+		Name:         "Widget",
+		Files:        []string{"../../testdata/custom/widget.json"},
+		Format:       "json",
+		Records:      "events",
+		DefaultModel: "gpt-4.1",
+		Fields: CustomFields{
+			Timestamp: "at",
+			Session:   "conversation",
+			Model:     "llm.name",
+			Input:     "tokens.prompt",
+			Output:    "tokens.completion",
+		},
+		Filters: []string{"tokens"},
+	})
+	data, err := c.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Sessions) != 1 || data.Sessions[0].Messages != 2 || data.Sessions[0].Tokens != 10300 {
+		t.Fatalf("unexpected sessions %+v", data.Sessions)
+	}
+	if len(data.Models) != 1 || data.Models[0].Model != "gpt-4.1" || data.Models[0].Requests != 2 {
+		t.Errorf("unexpected models %+v", data.Models)
+	}
+}
+
+func TestCustomSpecErrors(t *testing.T) {
+	for name, spec := range map[string]CustomSpec{
+		"no timestamp": {Name: "x", Files: []string{"../../testdata/custom/widget.json"}, Fields: CustomFields{Input: "a"}},
+		"no tokens":    {Name: "x", Files: []string{"../../testdata/custom/widget.json"}, Fields: CustomFields{Timestamp: "at"}},
+		"bad path":     {Name: "x", Files: []string{"../../testdata/custom/widget.json"}, Fields: CustomFields{Timestamp: "at[x]", Input: "a"}},
+		"bad filter":   {Name: "x", Files: []string{"../../testdata/custom/widget.json"}, Fields: CustomFields{Timestamp: "at", Input: "a"}, Filters: []string{" == 1"}},
+	} {
+		if _, err := NewCustom(spec).Load(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestRecordFilter(t *testing.T) {
+	rec := map[string]any{
+		"type":  "assistant",
+		"usage": map[string]any{"output_tokens": 120.0},
+		"tags":  []any{"a", "b"},
+		"flag":  false,
+	}
+	for expr, want := range map[string]bool{
+		`type == "assistant"`:        true,
+		`type == assistant`:          true,
+		`type != "user"`:             true,
+		`usage.output_tokens > 100`:  true,
+		`usage.output_tokens <= 100`: false,
+		`tags[1] == "b"`:             true,
+		`tags[5] == "b"`:             false,
+		`missing == null`:            true,
+		`flag`:                       false,
+		`usage`:                      true,
+		`$.type == "assistant"`:      true,
+	} {
+		f, err := parseRecordFilter(expr)
+		if err != nil {
+			t.Fatalf("%s: %v", expr, err)
+		}
+		if got := f.match(rec); got != want {
+			t.Errorf("%s = %v, want %v", expr, got, want)
+		}
+	}
+}
+
+func TestGlobRecursiveWildcardRoot(t *testing.T) {
+	// Wildcards before ** are expanded rather than walked literally.
+	got := globRecursive("../../testdata/custom/*/projects/**/*.jsonl")
+	if len(got) != 2 {
+		t.Errorf("expected both acme logs, got %v", got)
+	}
+	if got := globRecursive("../../testdata/custom/*.json/**"); len(got) != 0 {
+		t.Errorf("expected files matching the root to be skipped, got %v", got)
+	}
+}
@@ -4,45 +4,89 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
 	"time"
 
 	"github.com/isaacaudet/aitop/internal/model"
 )
 
-// Gemini implements Provider for Google Gemini CLI.
+// GeminiFlavor identifies a Gemini CLI-compatible tool. Forks such as Qwen
+// Code keep Gemini CLI's tmp/<hash>/chats/session-*.json layout under their
+// own config dir, so one provider implementation covers the whole family.
+type GeminiFlavor struct {
+	Name         string
+	Icon         string
+	Color        string
+	DefaultModel string // Used for responses that don't record a model
+}
+
+// Built-in Gemini-family flavors.
+var (
+	GeminiCLIFlavor = GeminiFlavor{Name: "Gemini", Icon: "✦", Color: "#74c7ec", DefaultModel: "gemini-2.5-pro"}      // Sapphire
+	QwenCodeFlavor  = GeminiFlavor{Name: "Qwen Code", Icon: "◇", Color: "#cba6f7", DefaultModel: "qwen3-coder-plus"} // Mauve
+)
+
+// Gemini implements Provider for Google Gemini CLI and its forks. It reads the
+// chat sessions under tmp/ of one or more config dirs (~/.gemini by default).
 type Gemini struct {
-	ConfigDir string
+	Flavor         GeminiFlavor
+	ConfigDirs     []string
+	TelemetryPaths []string         // Telemetry outfiles in addition to those in settings.json
+	Projects       *ProjectResolver // Resolves projectHash to a directory; optional
+}
+
+func NewGemini(configDirs []string) *Gemini {
+	return NewGeminiFork(GeminiCLIFlavor, configDirs)
+}
+
+func NewQwen(configDirs []string) *Gemini {
+	return NewGeminiFork(QwenCodeFlavor, configDirs)
 }
 
-func NewGemini() *Gemini {
-	home, _ := os.UserHomeDir()
-	return &Gemini{
-		ConfigDir: filepath.Join(home, ".gemini"),
+// NewGeminiFork returns a provider for a Gemini CLI fork, filling in Gemini's
+// icon, color and default model where the flavor leaves them empty.
+func NewGeminiFork(flavor GeminiFlavor, configDirs []string) *Gemini {
+	if flavor.Icon == "" {
+		flavor.Icon = GeminiCLIFlavor.Icon
+	}
+	if flavor.Color == "" {
+		flavor.Color = GeminiCLIFlavor.Color
 	}
+	if flavor.DefaultModel == "" {
+		flavor.DefaultModel = GeminiCLIFlavor.DefaultModel
+	}
+	return &Gemini{Flavor: flavor, ConfigDirs: configDirs}
 }
 
-func (g *Gemini) Name() string  { return "Gemini" }
-func (g *Gemini) Icon() string  { return "✦" }
-func (g *Gemini) Color() string { return "#74c7ec" } // Sapphire
+func (g *Gemini) Name() string  { return g.Flavor.Name }
+func (g *Gemini) Icon() string  { return g.Flavor.Icon }
+func (g *Gemini) Color() string { return g.Flavor.Color }
 
 func (g *Gemini) Available() bool {
-	// Check for tmp directory with chat sessions first.
-	tmpDir := filepath.Join(g.ConfigDir, "tmp")
-	if entries, err := os.ReadDir(tmpDir); err == nil {
-		for _, e := range entries {
-			if e.IsDir() {
-				chatsDir := filepath.Join(tmpDir, e.Name(), "chats")
-				if _, err := os.Stat(chatsDir); err == nil {
-					return true
+	for _, dir := range g.ConfigDirs {
+		// Check for tmp directory with chat sessions first.
+		tmpDir := filepath.Join(dir, "tmp")
+		if entries, err := os.ReadDir(tmpDir); err == nil {
+			for _, e := range entries {
+				if e.IsDir() {
+					chatsDir := filepath.Join(tmpDir, e.Name(), "chats")
+					if _, err := os.Stat(chatsDir); err == nil {
+						return true
+					}
 				}
 			}
 		}
+		// Fallback: settings.json indicates Gemini is installed.
+		if _, err := os.Stat(filepath.Join(dir, "settings.json")); err == nil {
+			return true
+		}
+	}
+	for _, path := range g.TelemetryPaths {
+		if _, err := os.Stat(path); err == nil {
+			return true
+		}
 	}
-	// Fallback: settings.json indicates Gemini is installed.
-	_, err := os.Stat(filepath.Join(g.ConfigDir, "settings.json"))
-	return err == nil
+	return false
 }
 
 // geminiSession represents the JSON structure of a Gemini session file.
@@ -81,13 +125,17 @@ func (g *Gemini) Load() (*ProviderData, error) {
 		Metadata:     make(map[string]string),
 	}
 
+	// Chat files may have been cleaned up while telemetry survives, so only
+	// fail when neither source has anything.
 	sessions, err := g.loadSessions()
-	if err != nil {
+	calls := g.loadTelemetry()
+	if err != nil && len(calls) == 0 {
 		return nil, err
 	}
 
-	modelAgg := make(map[string]*ModelBreakdown)
-	dailyAgg := make(map[string]*DailyUsage)
+	projects := g.resolveProjects(sessions)
+
+	totals := newUsageTotals()
 
 	for _, sess := range sessions {
 		startTime, _ := time.Parse(time.RFC3339, sess.StartTime)
@@ -107,9 +155,8 @@ func (g *Gemini) Load() (*ProviderData, error) {
 				userMsgCount++
 				continue
 			}
-			if msg.Type != "gemini" {
-				continue
-			}
+			// Model replies are typed after the tool ("gemini", "qwen", ...);
+			// info and error messages carry no token counts.
 			if msg.Tokens == nil {
 				continue
 			}
@@ -124,7 +171,7 @@ func (g *Gemini) Load() (*ProviderData, error) {
 
 			m := msg.Model
 			if m == "" {
-				m = "gemini-2.5-pro"
+				m = g.Flavor.DefaultModel
 			}
 			sessionModel = m
 
@@ -134,16 +181,11 @@ func (g *Gemini) Load() (*ProviderData, error) {
 			existing.CacheRead += cached
 			sessionModelTokens[m] = existing
 
-			// Aggregate into model breakdown.
-			mb, ok := modelAgg[m]
-			if !ok {
-				mb = &ModelBreakdown{Model: m}
-				modelAgg[m] = mb
-			}
+			mb := totals.model(m)
 			mb.InputTokens += input
 			mb.OutputTokens += output
 			mb.CacheRead += cached
-			mb.Generations++
+			mb.Requests++
 		}
 
 		// Calculate session cost across all models used.
@@ -155,7 +197,7 @@ func (g *Gemini) Load() (*ProviderData, error) {
 
 		si := SessionInfo{
 			ID:           sess.SessionID,
-			Project:      sess.ProjectHash,
+			Project:      projects[sess.ProjectHash],
 			StartTime:    startTime,
 			EndTime:      endTime,
 			Messages:     msgCount,
@@ -167,24 +209,11 @@ func (g *Gemini) Load() (*ProviderData, error) {
 		data.Sessions = append(data.Sessions, si)
 		data.TotalCost += sessionCost
 
-		// Track first/last seen.
-		if !startTime.IsZero() {
-			if data.FirstSeen.IsZero() || startTime.Before(data.FirstSeen) {
-				data.FirstSeen = startTime
-			}
-		}
-		if !endTime.IsZero() && endTime.After(data.LastSeen) {
-			data.LastSeen = endTime
-		}
+		totals.span(startTime, endTime)
 
 		// Aggregate daily usage from session start date.
 		if !startTime.IsZero() {
-			dateKey := startTime.Format("2006-01-02")
-			du, ok := dailyAgg[dateKey]
-			if !ok {
-				du = &DailyUsage{Date: dateKey}
-				dailyAgg[dateKey] = du
-			}
+			du := totals.day(startTime)
 			du.Cost += sessionCost
 			du.Tokens += totalTokens
 			du.Messages += msgCount
@@ -192,59 +221,118 @@ func (g *Gemini) Load() (*ProviderData, error) {
 		}
 	}
 
-	// Finalize model breakdowns with cost.
-	for m, mb := range modelAgg {
+	if len(calls) > 0 {
+		g.mergeTelemetry(data, calls, totals)
+	}
+
+	// Price each model's totals.
+	for m, mb := range totals.models {
 		mb.Cost = model.CalculateCost(m, model.TokenUsage{
 			InputTokens:  mb.InputTokens,
 			OutputTokens: mb.OutputTokens,
 			CacheRead:    mb.CacheRead,
 		})
-		data.Models = append(data.Models, *mb)
 	}
-	sort.Slice(data.Models, func(i, j int) bool {
-		return data.Models[i].Cost > data.Models[j].Cost
-	})
+	totals.fill(data)
 
-	// Finalize daily usage sorted by date.
-	for _, du := range dailyAgg {
-		data.DailyUsage = append(data.DailyUsage, *du)
+	return data, nil
+}
+
+// resolveProjects maps each session's projectHash to a project directory,
+// falling back to a short hash label when no candidate directory matches.
+func (g *Gemini) resolveProjects(sessions []geminiSession) map[string]string {
+	var hashes []string
+	seen := make(map[string]bool)
+	for _, sess := range sessions {
+		if sess.ProjectHash != "" && !seen[sess.ProjectHash] {
+			seen[sess.ProjectHash] = true
+			hashes = append(hashes, sess.ProjectHash)
+		}
 	}
-	sort.Slice(data.DailyUsage, func(i, j int) bool {
-		return data.DailyUsage[i].Date < data.DailyUsage[j].Date
-	})
 
-	return data, nil
+	resolved := make(map[string]string)
+	if g.Projects != nil && len(hashes) > 0 {
+		resolved = g.Projects.Resolve(hashes, g.metadataDirs())
+	}
+	for _, h := range hashes {
+		if _, ok := resolved[h]; !ok {
+			resolved[h] = shortHashLabel(h)
+		}
+	}
+	return resolved
 }
 
-// loadSessions walks the Gemini tmp directories and parses all session JSON files.
-func (g *Gemini) loadSessions() ([]geminiSession, error) {
-	tmpDir := filepath.Join(g.ConfigDir, "tmp")
-	entries, err := os.ReadDir(tmpDir)
-	if err != nil {
-		return nil, err
+// metadataDirs returns project directories recorded by Gemini CLI itself:
+// trusted folders and the .project_root marker newer versions write into
+// each tmp/<hash> directory.
+func (g *Gemini) metadataDirs() []string {
+	var dirs []string
+	for _, dir := range g.ConfigDirs {
+		if raw, err := os.ReadFile(filepath.Join(dir, "trustedFolders.json")); err == nil {
+			var trusted map[string]json.RawMessage
+			if json.Unmarshal(raw, &trusted) == nil {
+				for path := range trusted {
+					dirs = append(dirs, path)
+				}
+			}
+		}
+		roots, _ := filepath.Glob(filepath.Join(dir, "tmp", "*", ".project_root"))
+		for _, f := range roots {
+			if raw, err := os.ReadFile(f); err == nil {
+				dirs = append(dirs, strings.TrimSpace(string(raw)))
+			}
+		}
 	}
+	return dirs
+}
 
+// loadSessions walks the Gemini tmp directories of every config dir and parses
+// all session JSON files, keeping one copy of each session id.
+func (g *Gemini) loadSessions() ([]geminiSession, error) {
 	var sessions []geminiSession
-	for _, e := range entries {
-		if !e.IsDir() {
-			continue
-		}
-		chatsDir := filepath.Join(tmpDir, e.Name(), "chats")
-		chatFiles, err := os.ReadDir(chatsDir)
+	seen := make(map[string]bool)
+	var lastErr error
+	found := false
+
+	for _, dir := range g.ConfigDirs {
+		tmpDir := filepath.Join(dir, "tmp")
+		entries, err := os.ReadDir(tmpDir)
 		if err != nil {
+			lastErr = err
 			continue
 		}
-		for _, cf := range chatFiles {
-			if cf.IsDir() || !strings.HasPrefix(cf.Name(), "session-") || !strings.HasSuffix(cf.Name(), ".json") {
+		found = true
+
+		for _, e := range entries {
+			if !e.IsDir() {
 				continue
 			}
-			sess, err := g.parseSession(filepath.Join(chatsDir, cf.Name()))
+			chatsDir := filepath.Join(tmpDir, e.Name(), "chats")
+			chatFiles, err := os.ReadDir(chatsDir)
 			if err != nil {
 				continue
 			}
-			sessions = append(sessions, sess)
+			for _, cf := range chatFiles {
+				if cf.IsDir() || !strings.HasPrefix(cf.Name(), "session-") || !strings.HasSuffix(cf.Name(), ".json") {
+					continue
+				}
+				sess, err := g.parseSession(filepath.Join(chatsDir, cf.Name()))
+				if err != nil {
+					continue
+				}
+				if sess.SessionID != "" {
+					if seen[sess.SessionID] {
+						continue
+					}
+					seen[sess.SessionID] = true
+				}
+				sessions = append(sessions, sess)
+			}
 		}
 	}
+	if !found {
+		return nil, lastErr
+	}
 	return sessions, nil
 }
 
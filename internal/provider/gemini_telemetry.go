@@ -0,0 +1,245 @@
+package provider
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/isaacaudet/aitop/internal/model"
+)
+
+// geminiTelemetryRecord is an OpenTelemetry log record as written by Gemini
+// CLI's local file exporter (telemetry.outfile). Metric and span records share
+// the file but carry no event.name attribute and are ignored. Forks rename the
+// event prefix (gemini_cli.*), so events are matched by suffix.
+type geminiTelemetryRecord struct {
+	Attributes map[string]interface{} `json:"attributes"`
+}
+
+// geminiSettings is the subset of settings.json that configures telemetry.
+type geminiSettings struct {
+	Telemetry struct {
+		Enabled bool   `json:"enabled"`
+		Target  string `json:"target"`
+		Outfile string `json:"outfile"`
+	} `json:"telemetry"`
+}
+
+// geminiAPICall is a single API request reconstructed from telemetry.
+type geminiAPICall struct {
+	sessionID string
+	timestamp time.Time
+	model     string
+	duration  time.Duration
+	failed    bool
+	input     int
+	output    int
+	cached    int
+}
+
+// telemetryPaths returns the configured telemetry files plus any outfile
+// named in a config dir's settings.json. Relative outfiles are resolved
+// against the directory containing the config dir (normally $HOME).
+func (g *Gemini) telemetryPaths() []string {
+	paths := append([]string(nil), g.TelemetryPaths...)
+	for _, dir := range g.ConfigDirs {
+		raw, err := os.ReadFile(filepath.Join(dir, "settings.json"))
+		if err != nil {
+			continue
+		}
+		var settings geminiSettings
+		if json.Unmarshal(raw, &settings) != nil || settings.Telemetry.Outfile == "" {
+			continue
+		}
+		out := settings.Telemetry.Outfile
+		if !filepath.IsAbs(out) {
+			out = filepath.Join(filepath.Dir(dir), out)
+		}
+		paths = append(paths, out)
+	}
+	return paths
+}
+
+// loadTelemetry parses every telemetry file into API calls, skipping
+// unreadable files.
+func (g *Gemini) loadTelemetry() []geminiAPICall {
+	var calls []geminiAPICall
+	seen := make(map[string]bool)
+	for _, path := range g.telemetryPaths() {
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		parsed, err := parseGeminiTelemetry(path)
+		if err != nil {
+			continue
+		}
+		calls = append(calls, parsed...)
+	}
+	return calls
+}
+
+// parseGeminiTelemetry reads a telemetry outfile. The exporter writes a stream
+// of (often pretty-printed) JSON objects rather than one object per line.
+func parseGeminiTelemetry(path string) ([]geminiAPICall, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var calls []geminiAPICall
+	dec := json.NewDecoder(f)
+	for {
+		var rec geminiTelemetryRecord
+		if err := dec.Decode(&rec); err != nil {
+			break // EOF or a truncated trailing record
+		}
+		attrs := rec.Attributes
+		event := attrString(attrs, "event.name")
+		isError := strings.HasSuffix(event, ".api_error")
+		if !isError && !strings.HasSuffix(event, ".api_response") {
+			continue
+		}
+		if attrString(attrs, "session.id") == "" {
+			continue // Can't be attributed to a session
+		}
+
+		call := geminiAPICall{
+			sessionID: attrString(attrs, "session.id"),
+			model:     attrString(attrs, "model"),
+			duration:  time.Duration(attrInt(attrs, "duration_ms")) * time.Millisecond,
+			failed:    isError || attrString(attrs, "error") != "",
+			input:     attrInt(attrs, "input_token_count"),
+			output:    attrInt(attrs, "output_token_count"),
+			cached:    attrInt(attrs, "cached_content_token_count"),
+		}
+		if code := attrInt(attrs, "status_code"); code >= 400 {
+			call.failed = true
+		}
+		if ts, err := time.Parse(time.RFC3339, attrString(attrs, "event.timestamp")); err == nil {
+			call.timestamp = ts
+		}
+		calls = append(calls, call)
+	}
+	return calls, nil
+}
+
+func attrString(attrs map[string]interface{}, key string) string {
+	if s, ok := attrs[key].(string); ok {
+		return s
+	}
+	return ""
+}
+
+func attrInt(attrs map[string]interface{}, key string) int {
+	switch v := attrs[key].(type) {
+	case float64:
+		return int(v)
+	case string:
+		var n int
+		if err := json.Unmarshal([]byte(v), &n); err == nil {
+			return n
+		}
+	}
+	return 0
+}
+
+// mergeTelemetry folds API calls into data. Calls for sessions that still
+// have chat files only add request timing and error counts, since their
+// tokens are already counted; calls for sessions whose chat files were cleaned
+// up become sessions of their own.
+func (g *Gemini) mergeTelemetry(data *ProviderData, calls []geminiAPICall, totals *usageTotals) {
+	bySession := make(map[string][]geminiAPICall)
+	var order []string
+	for _, c := range calls {
+		if _, ok := bySession[c.sessionID]; !ok {
+			order = append(order, c.sessionID)
+		}
+		bySession[c.sessionID] = append(bySession[c.sessionID], c)
+	}
+
+	known := make(map[string]int)
+	for i, s := range data.Sessions {
+		known[s.ID] = i
+	}
+
+	for _, id := range order {
+		sessCalls := bySession[id]
+		if i, ok := known[id]; ok {
+			si := &data.Sessions[i]
+			for _, c := range sessCalls {
+				si.Requests++
+				si.Latency += c.duration
+				if c.failed {
+					si.Errors++
+				}
+			}
+			continue
+		}
+
+		si := SessionInfo{ID: id}
+		for _, c := range sessCalls {
+			si.Requests++
+			si.Latency += c.duration
+			// Failed calls still date the session.
+			if !c.timestamp.IsZero() {
+				if si.StartTime.IsZero() || c.timestamp.Before(si.StartTime) {
+					si.StartTime = c.timestamp
+				}
+				if c.timestamp.After(si.EndTime) {
+					si.EndTime = c.timestamp
+				}
+			}
+			if c.failed {
+				si.Errors++
+				continue
+			}
+			si.Messages++
+
+			m := c.model
+			if m == "" {
+				m = g.Flavor.DefaultModel
+			}
+			si.Model = m
+			usage := model.TokenUsage{
+				InputTokens:  c.input,
+				OutputTokens: c.output,
+				CacheRead:    c.cached,
+			}
+			cost := model.CalculateCost(m, usage)
+			tokens := c.input + c.output + c.cached
+			si.Tokens += tokens
+			si.Cost += cost
+			si.Turns = append(si.Turns, TurnUsage{
+				Timestamp:    c.timestamp,
+				Model:        m,
+				InputTokens:  c.input,
+				OutputTokens: c.output,
+				CacheRead:    c.cached,
+				Cost:         cost,
+			})
+
+			mb := totals.model(m)
+			mb.InputTokens += c.input
+			mb.OutputTokens += c.output
+			mb.CacheRead += c.cached
+			mb.Requests++
+
+			if !c.timestamp.IsZero() {
+				du := totals.day(c.timestamp)
+				du.Cost += cost
+				du.Tokens += tokens
+				du.Messages++
+			}
+		}
+		if !si.StartTime.IsZero() {
+			totals.day(si.StartTime).Sessions++
+		}
+		totals.span(si.StartTime, si.EndTime)
+		data.TotalCost += si.Cost
+		data.Sessions = append(data.Sessions, si)
+	}
+}
@@ -0,0 +1,145 @@
+package provider
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestGemini(t *testing.T) *Gemini {
+	g := NewGemini([]string{filepath.Join("..", "..", "testdata", "gemini")})
+	g.Projects = &ProjectResolver{CachePath: filepath.Join(t.TempDir(), "projects.json")}
+	return g
+}
+
+func TestGeminiProjectResolution(t *testing.T) {
+	g := newTestGemini(t)
+	data, err := g.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	projects := make(map[string]string)
+	for _, s := range data.Sessions {
+		projects[s.ID] = s.Project
+	}
+	if got := projects["aaaa1111-0000-4000-8000-000000000001"]; got != "/Users/dev/aitop" {
+		t.Errorf("resolved project = %q, want /Users/dev/aitop from trustedFolders.json", got)
+	}
+	if got := projects["bbbb2222-0000-4000-8000-000000000002"]; got != "#61ad6318" {
+		t.Errorf("unresolved project = %q, want short hash label", got)
+	}
+
+	// The mapping is cached, so a resolver without candidates still resolves it.
+	cached := &ProjectResolver{CachePath: g.Projects.CachePath}
+	hash := sha256Hex("/Users/dev/aitop")
+	if got := cached.Resolve([]string{hash}, nil)[hash]; got != "/Users/dev/aitop" {
+		t.Errorf("cached Resolve = %q, want /Users/dev/aitop", got)
+	}
+}
+
+func TestProjectResolverCachesMisses(t *testing.T) {
+	root := t.TempDir()
+	repo := filepath.Join(root, "deleted")
+	if err := os.MkdirAll(filepath.Join(repo, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	hash := sha256Hex(repo)
+	cachePath := filepath.Join(t.TempDir(), "projects.json")
+
+	// The project isn't under any workspace root yet, so the miss is cached.
+	if got := (&ProjectResolver{CachePath: cachePath}).Resolve([]string{hash}, nil); got[hash] != "" {
+		t.Fatalf("unexpectedly resolved %q", got[hash])
+	}
+	r := &ProjectResolver{CachePath: cachePath, WorkspaceRoots: []string{root}}
+	if got := r.Resolve([]string{hash}, nil)[hash]; got != "" {
+		t.Errorf("expected a recent miss not to be searched again, got %q", got)
+	}
+
+	// A day later it is searched for again.
+	cache := r.loadCache()
+	cache.Misses[hash] = time.Now().Add(-missRetry)
+	r.saveCache(cache)
+	if got := r.Resolve([]string{hash}, nil)[hash]; got != repo {
+		t.Errorf("expected a stale miss to be retried, got %q", got)
+	}
+	if _, ok := r.loadCache().Misses[hash]; ok {
+		t.Error("expected the miss to be cleared once resolved")
+	}
+}
+
+func TestGeminiTelemetryMerge(t *testing.T) {
+	g := newTestGemini(t)
+	g.TelemetryPaths = []string{filepath.Join("..", "..", "testdata", "gemini_telemetry.log")}
+	data, err := g.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	byID := make(map[string]SessionInfo)
+	for _, s := range data.Sessions {
+		byID[s.ID] = s
+	}
+
+	chat := byID["aaaa1111-0000-4000-8000-000000000001"]
+	if chat.Requests != 2 || chat.Errors != 1 {
+		t.Errorf("chat session requests/errors = %d/%d, want 2/1", chat.Requests, chat.Errors)
+	}
+	if chat.Tokens != 12000+800+4000+14000+400+10000 {
+		t.Errorf("chat session tokens = %d; telemetry must not double count", chat.Tokens)
+	}
+
+	// A session whose chat file was cleaned up is rebuilt from telemetry.
+	orphan, ok := byID["cccc3333-0000-4000-8000-000000000003"]
+	if !ok {
+		t.Fatal("expected session reconstructed from telemetry")
+	}
+	if orphan.Tokens != 2100 || orphan.Model != "gemini-2.5-flash" || orphan.Cost <= 0 {
+		t.Errorf("telemetry session = %+v", orphan)
+	}
+	// Telemetry doesn't say where a session ran.
+	if orphan.Project != "" {
+		t.Errorf("expected no project for a telemetry session, got %q", orphan.Project)
+	}
+	// A session with only failed calls is still dated, and calls without a
+	// session id are dropped.
+	failed := byID["dddd4444-0000-4000-8000-000000000004"]
+	if failed.Errors != 1 || failed.StartTime.IsZero() {
+		t.Errorf("failed-only session = %+v", failed)
+	}
+	if _, ok := byID[""]; ok {
+		t.Error("expected calls without a session id to be skipped")
+	}
+	if len(data.Sessions) != 4 {
+		t.Errorf("got %d sessions, want 4", len(data.Sessions))
+	}
+}
+
+func TestQwenFlavor(t *testing.T) {
+	dir := t.TempDir()
+	chats := filepath.Join(dir, "tmp", "abc123", "chats")
+	if err := os.MkdirAll(chats, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	session := `{"sessionId":"q1","projectHash":"abc123","startTime":"2026-02-07T10:00:00Z","lastUpdated":"2026-02-07T10:01:00Z",
+"messages":[{"type":"user","content":"hi"},{"type":"qwen","content":"hello","tokens":{"input":1000000,"output":0,"cached":0}}]}`
+	if err := os.WriteFile(filepath.Join(chats, "session-q1.json"), []byte(session), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	q := NewQwen([]string{dir})
+	if q.Name() != "Qwen Code" {
+		t.Errorf("Name = %q, want Qwen Code", q.Name())
+	}
+	data, err := q.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(data.Models) != 1 || data.Models[0].Model != "qwen3-coder-plus" {
+		t.Fatalf("models = %+v, want the flavor's default model", data.Models)
+	}
+	if data.TotalCost != 1.0 {
+		t.Errorf("TotalCost = %v, want 1.0", data.TotalCost)
+	}
+}
@@ -0,0 +1,263 @@
+package provider
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/isaacaudet/aitop/internal/model"
+)
+
+// Goose implements Provider for Block's Goose agent. Each session is a JSONL
+// file under <data dir>/sessions: a metadata line with the working directory,
+// description and accumulated token counts, followed by one line per message.
+//
+// Goose records neither the model nor a cost in its sessions, so usage is
+// priced with DefaultModel (GOOSE_MODEL); set [pricing] for models aitop
+// doesn't know about.
+type Goose struct {
+	DataDirs     []string
+	DefaultModel string
+}
+
+func NewGoose(dataDirs []string) *Goose {
+	return &Goose{DataDirs: dataDirs, DefaultModel: gooseConfiguredModel()}
+}
+
+func (g *Goose) Name() string  { return "Goose" }
+func (g *Goose) Icon() string  { return "ᘝ" }
+func (g *Goose) Color() string { return "#89dceb" } // Sky
+
+func (g *Goose) Available() bool {
+	for _, dir := range g.DataDirs {
+		if files, _ := filepath.Glob(filepath.Join(dir, "sessions", "*.jsonl")); len(files) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// gooseMetadata is the first line of a session file. Older Goose versions
+// only record the last request's tokens; newer ones add accumulated totals.
+type gooseMetadata struct {
+	WorkingDir              string `json:"working_dir"`
+	Description             string `json:"description"`
+	MessageCount            int    `json:"message_count"`
+	InputTokens             *int   `json:"input_tokens"`
+	OutputTokens            *int   `json:"output_tokens"`
+	AccumulatedInputTokens  *int   `json:"accumulated_input_tokens"`
+	AccumulatedOutputTokens *int   `json:"accumulated_output_tokens"`
+}
+
+// gooseMessage is a message line of a session file.
+type gooseMessage struct {
+	Role    string `json:"role"`
+	Created int64  `json:"created"` // Unix seconds
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+}
+
+func (g *Goose) Load() (*ProviderData, error) {
+	data := &ProviderData{
+		ProviderName: g.Name(),
+		Icon:         g.Icon(),
+		Color:        g.Color(),
+		Metadata:     make(map[string]string),
+	}
+
+	m := g.DefaultModel
+	if m == "" {
+		m = "unknown"
+	}
+	totals := newUsageTotals()
+
+	seen := make(map[string]bool)
+	found := false
+	var promptLengths []int
+	for _, dir := range g.DataDirs {
+		files, _ := filepath.Glob(filepath.Join(dir, "sessions", "*.jsonl"))
+		if len(files) == 0 {
+			continue
+		}
+		found = true
+		for _, path := range files {
+			id := strings.TrimSuffix(filepath.Base(path), ".jsonl")
+			if seen[id] {
+				continue
+			}
+			sess, err := parseGooseSession(path)
+			if err != nil {
+				continue
+			}
+			seen[id] = true
+			si := sess.info
+			si.ID = id
+			si.Model = m
+			si.Cost = model.CalculateCost(m, model.TokenUsage{InputTokens: sess.input, OutputTokens: sess.output})
+
+			mb := totals.model(m)
+			mb.InputTokens += sess.input
+			mb.OutputTokens += sess.output
+			mb.Cost += si.Cost
+			mb.Requests += sess.requests
+
+			// Goose only keeps session totals, so usage lands on the day
+			// the session was last active.
+			du := totals.day(si.EndTime)
+			du.Cost += si.Cost
+			du.Tokens += si.Tokens
+			du = totals.day(si.StartTime)
+			du.Sessions++
+			du.Messages += si.Messages
+			du.Prompts += len(sess.prompts)
+			for _, p := range sess.prompts {
+				promptLengths = append(promptLengths, utf8.RuneCountInString(p))
+			}
+
+			data.TotalCost += si.Cost
+			data.Sessions = append(data.Sessions, si)
+			totals.span(si.StartTime, si.EndTime)
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("no goose sessions found")
+	}
+	if len(promptLengths) > 0 {
+		data.Prompts = newPromptStats(promptLengths, len(data.Sessions))
+	}
+	totals.fill(data)
+
+	return data, nil
+}
+
+// gooseSession is what parseGooseSession reads from a session file.
+type gooseSession struct {
+	info     SessionInfo
+	input    int
+	output   int
+	requests int      // assistant messages
+	prompts  []string // what the user typed
+}
+
+// parseGooseSession reads a session file.
+func parseGooseSession(path string) (gooseSession, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return gooseSession{}, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+	if !scanner.Scan() {
+		return gooseSession{}, fmt.Errorf("empty goose session %s", path)
+	}
+	var meta gooseMetadata
+	if err := json.Unmarshal(scanner.Bytes(), &meta); err != nil {
+		return gooseSession{}, fmt.Errorf("parsing goose session metadata: %w", err)
+	}
+
+	si := SessionInfo{
+		Title:   meta.Description,
+		Project: meta.WorkingDir,
+	}
+	var requests int
+	var prompts []string
+	for scanner.Scan() {
+		var msg gooseMessage
+		if json.Unmarshal(scanner.Bytes(), &msg) != nil {
+			continue
+		}
+		si.Messages++
+		if msg.Created > 0 {
+			ts := time.Unix(msg.Created, 0)
+			if si.StartTime.IsZero() {
+				si.StartTime = ts
+			}
+			si.EndTime = ts
+		}
+		if msg.Role == "assistant" {
+			requests++
+		}
+		if msg.Role != "user" {
+			continue
+		}
+		// Tool results come back as user messages without text.
+		var texts []string
+		for _, c := range msg.Content {
+			if c.Type == "text" && strings.TrimSpace(c.Text) != "" {
+				texts = append(texts, strings.TrimSpace(c.Text))
+			}
+		}
+		if len(texts) > 0 {
+			si.UserMessages++
+			prompts = append(prompts, strings.Join(texts, "\n"))
+		}
+	}
+	if si.Messages == 0 {
+		si.Messages = meta.MessageCount
+	}
+	if si.StartTime.IsZero() {
+		info, err := os.Stat(path)
+		if err != nil {
+			return gooseSession{}, err
+		}
+		si.StartTime = info.ModTime()
+		si.EndTime = si.StartTime
+	}
+	if si.Title == "" && len(prompts) > 0 {
+		si.Title = promptTitle(prompts[0])
+	}
+	si.Prompts = len(prompts)
+
+	input := gooseTokens(meta.AccumulatedInputTokens, meta.InputTokens)
+	output := gooseTokens(meta.AccumulatedOutputTokens, meta.OutputTokens)
+	si.Tokens = input + output
+	return gooseSession{si, input, output, requests, prompts}, nil
+}
+
+// gooseTokens prefers the accumulated count when Goose recorded one.
+func gooseTokens(accumulated, last *int) int {
+	if accumulated != nil {
+		return *accumulated
+	}
+	if last != nil {
+		return *last
+	}
+	return 0
+}
+
+// gooseConfiguredModel returns the model Goose is configured to use:
+// $GOOSE_MODEL, else GOOSE_MODEL in goose/config.yaml under $XDG_CONFIG_HOME
+// or ~/.config.
+func gooseConfiguredModel() string {
+	if m := os.Getenv("GOOSE_MODEL"); m != "" {
+		return m
+	}
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+	if configDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		configDir = filepath.Join(home, ".config")
+	}
+	raw, err := os.ReadFile(filepath.Join(configDir, "goose", "config.yaml"))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(raw), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if ok && strings.TrimSpace(key) == "GOOSE_MODEL" {
+			return strings.Trim(strings.TrimSpace(value), `"'`)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,53 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/isaacaudet/aitop/internal/model"
+)
+
+func TestGooseSessions(t *testing.T) {
+	prev := model.SetCustomPricing(map[string]model.ModelPricing{
+		"qwen3-coder": {InputPerMTok: 1, OutputPerMTok: 4},
+	})
+	t.Cleanup(func() { model.SetCustomPricing(prev) })
+
+	g := &Goose{DataDirs: []string{"../../testdata/goose"}, DefaultModel: "qwen3-coder:30b"}
+	if !g.Available() {
+		t.Fatal("expected goose to be available")
+	}
+	data, err := g.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(data.Sessions))
+	}
+
+	s := data.Sessions[0]
+	if s.Title != "Fix flaky login test" || s.Project != "/home/dev/src/webapp" {
+		t.Errorf("unexpected title/project %q %q", s.Title, s.Project)
+	}
+	if s.Messages != 4 || s.UserMessages != 1 {
+		t.Errorf("expected 4 messages with 1 prompt, got %d/%d", s.Messages, s.UserMessages)
+	}
+	if s.Tokens != 21500 {
+		t.Errorf("expected accumulated tokens 21500, got %d", s.Tokens)
+	}
+	// 20k input at $1/M + 1.5k output at $4/M.
+	if want := 0.026; s.Cost < want-1e-9 || s.Cost > want+1e-9 {
+		t.Errorf("expected custom-priced cost %.4f, got %.4f", want, s.Cost)
+	}
+
+	// Without accumulated counts the last request's tokens are used, and the
+	// title falls back to the first prompt.
+	s = data.Sessions[1]
+	if s.Tokens != 1200 || s.Title != "Write a terraform module for an S3 bucket with versioning" {
+		t.Errorf("unexpected second session %+v", s)
+	}
+	if len(data.Models) != 1 || data.Models[0].Model != "qwen3-coder:30b" {
+		t.Errorf("unexpected models %+v", data.Models)
+	} else if data.Models[0].Requests != 3 {
+		t.Errorf("expected a request per assistant message, got %d", data.Models[0].Requests)
+	}
+}
@@ -0,0 +1,261 @@
+package provider
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+	"unicode/utf8"
+
+	"github.com/isaacaudet/aitop/internal/model"
+)
+
+// LLMCLI implements Provider for Simon Willison's llm CLI, which logs every
+// prompt and response to logs.db in its user directory.
+type LLMCLI struct {
+	UserDirs []string // Directories containing logs.db ($LLM_USER_PATH)
+}
+
+func NewLLMCLI(userDirs []string) *LLMCLI {
+	return &LLMCLI{UserDirs: userDirs}
+}
+
+func (l *LLMCLI) Name() string  { return "llm" }
+func (l *LLMCLI) Icon() string  { return "λ" }
+func (l *LLMCLI) Color() string { return "#eba0ac" } // Maroon
+
+func (l *LLMCLI) Available() bool {
+	for _, dir := range l.UserDirs {
+		if _, err := os.Stat(filepath.Join(dir, "logs.db")); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// llmResponse is a row of the responses table.
+type llmResponse struct {
+	id             string
+	model          string
+	prompt         string
+	conversationID string
+	conversation   string // Conversation name
+	timestamp      time.Time
+	duration       time.Duration
+	input          int
+	output         int
+	cacheRead      int
+	cacheWrite     int
+}
+
+// llmTokenDetails holds the provider-specific token counts llm keeps in
+// token_details. Anthropic models report cache usage separately from input.
+type llmTokenDetails struct {
+	CacheRead  int `json:"cache_read_input_tokens"`
+	CacheWrite int `json:"cache_creation_input_tokens"`
+}
+
+func (l *LLMCLI) Load() (*ProviderData, error) {
+	data := &ProviderData{
+		ProviderName: l.Name(),
+		Icon:         l.Icon(),
+		Color:        l.Color(),
+		Metadata:     make(map[string]string),
+	}
+
+	var responses []llmResponse
+	var lastErr error
+	loaded := 0
+	for _, dir := range l.UserDirs {
+		path := filepath.Join(dir, "logs.db")
+		if _, err := os.Stat(path); err != nil {
+			lastErr = err
+			continue
+		}
+		rs, err := loadLLMResponses(path)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		loaded++
+		responses = append(responses, rs...)
+	}
+	if loaded == 0 {
+		if lastErr == nil {
+			lastErr = fmt.Errorf("no llm logs.db found")
+		}
+		return nil, lastErr
+	}
+
+	// Responses outside a conversation become single-response sessions.
+	type group struct {
+		id        string
+		name      string
+		responses []llmResponse
+	}
+	groups := make(map[string]*group)
+	var order []string
+	seen := make(map[string]bool)
+	for _, r := range responses {
+		if seen[r.id] {
+			continue
+		}
+		seen[r.id] = true
+		key := r.conversationID
+		if key == "" {
+			key = r.id
+		}
+		g, ok := groups[key]
+		if !ok {
+			g = &group{id: key, name: r.conversation}
+			groups[key] = g
+			order = append(order, key)
+		}
+		g.responses = append(g.responses, r)
+	}
+
+	totals := newUsageTotals()
+
+	var promptLengths []int
+	for _, key := range order {
+		g := groups[key]
+		sort.SliceStable(g.responses, func(i, j int) bool {
+			return g.responses[i].timestamp.Before(g.responses[j].timestamp)
+		})
+		first, last := g.responses[0], g.responses[len(g.responses)-1]
+
+		si := SessionInfo{
+			ID:        g.id,
+			Title:     g.name,
+			StartTime: first.timestamp,
+			EndTime:   last.timestamp.Add(last.duration),
+		}
+		for _, r := range g.responses {
+			m := bareModelName(r.model)
+			usage := model.TokenUsage{
+				InputTokens:  r.input,
+				OutputTokens: r.output,
+				CacheRead:    r.cacheRead,
+				CacheWrite:   r.cacheWrite,
+			}
+			cost := model.CalculateCost(m, usage)
+			tokens := r.input + r.output + r.cacheRead + r.cacheWrite
+
+			si.Messages += 2
+			si.UserMessages++
+			si.Requests++
+			si.Latency += r.duration
+			si.Tokens += tokens
+			si.Cost += cost
+			si.Model = m
+			si.Turns = append(si.Turns, TurnUsage{
+				Timestamp:    r.timestamp,
+				Model:        m,
+				InputTokens:  r.input,
+				OutputTokens: r.output,
+				CacheRead:    r.cacheRead,
+				CacheWrite:   r.cacheWrite,
+				Cost:         cost,
+			})
+
+			mb := totals.model(m)
+			mb.InputTokens += r.input
+			mb.OutputTokens += r.output
+			mb.CacheRead += r.cacheRead
+			mb.CacheWrite += r.cacheWrite
+			mb.Cost += cost
+			mb.Requests++
+
+			du := totals.day(r.timestamp)
+			du.Cost += cost
+			du.Tokens += tokens
+			du.Messages += 2
+			du.Prompts++
+			promptLengths = append(promptLengths, utf8.RuneCountInString(r.prompt))
+		}
+		si.Prompts = si.UserMessages
+		if si.Title == "" {
+			si.Title = promptTitle(first.prompt)
+		}
+		totals.day(si.StartTime).Sessions++
+
+		data.TotalCost += si.Cost
+		data.Sessions = append(data.Sessions, si)
+		totals.span(si.StartTime, si.EndTime)
+	}
+	if len(promptLengths) > 0 {
+		data.Prompts = newPromptStats(promptLengths, len(data.Sessions))
+	}
+
+	totals.fill(data)
+
+	return data, nil
+}
+
+// loadLLMResponses reads every logged response from logs.db. Token columns
+// were added in llm 0.19; older databases yield responses without usage.
+func loadLLMResponses(path string) ([]llmResponse, error) {
+	db, err := sql.Open("sqlite3", path+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("opening llm logs db: %w", err)
+	}
+	defer db.Close()
+
+	tokenCols := "0, 0, ''"
+	if hasColumn(db, "responses", "input_tokens") {
+		tokenCols = "COALESCE(r.input_tokens, 0), COALESCE(r.output_tokens, 0), COALESCE(r.token_details, '')"
+	}
+	rows, err := db.Query(`
+		SELECT r.id, COALESCE(r.model, ''), COALESCE(r.prompt, ''), COALESCE(r.conversation_id, ''),
+		       COALESCE(c.name, ''), COALESCE(r.datetime_utc, ''), COALESCE(r.duration_ms, 0), ` + tokenCols + `
+		FROM responses r
+		LEFT JOIN conversations c ON c.id = r.conversation_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []llmResponse
+	for rows.Next() {
+		var r llmResponse
+		var ts, details string
+		var durationMs int64
+		if err := rows.Scan(&r.id, &r.model, &r.prompt, &r.conversationID, &r.conversation,
+			&ts, &durationMs, &r.input, &r.output, &details); err != nil {
+			continue
+		}
+		r.timestamp = parseLLMTime(ts)
+		r.duration = time.Duration(durationMs) * time.Millisecond
+		if details != "" {
+			var td llmTokenDetails
+			if json.Unmarshal([]byte(details), &td) == nil {
+				r.cacheRead = td.CacheRead
+				r.cacheWrite = td.CacheWrite
+			}
+		}
+		out = append(out, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading llm responses: %w", err)
+	}
+	return out, nil
+}
+
+// parseLLMTime parses llm's datetime_utc, an ISO 8601 timestamp in UTC. Most
+// versions write it without a zone (e.g. "2025-02-07T10:00:00.123456"); some
+// add one, like "+00:00".
+func parseLLMTime(s string) time.Time {
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05.999999999", "2006-01-02 15:04:05.999999999"} {
+		if t, err := time.ParseInLocation(layout, s, time.UTC); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,81 @@
+package provider
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLLMCLIConversations(t *testing.T) {
+	dir := t.TempDir()
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "logs.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmts := []string{
+		`CREATE TABLE conversations (id TEXT PRIMARY KEY, name TEXT, model TEXT)`,
+		`CREATE TABLE responses (id TEXT PRIMARY KEY, model TEXT, prompt TEXT, system TEXT, prompt_json TEXT, options_json TEXT,
+			response TEXT, response_json TEXT, conversation_id TEXT REFERENCES conversations(id), duration_ms INTEGER,
+			datetime_utc TEXT, input_tokens INTEGER, output_tokens INTEGER, token_details TEXT)`,
+		`INSERT INTO conversations VALUES ('c1', 'Summarize churn data', 'gpt-4.1')`,
+		`INSERT INTO responses VALUES ('r1', 'gpt-4.1', 'Summarize churn data', NULL, NULL, NULL, '...', NULL, 'c1', 1500, '2026-02-07T10:00:00.123456', 10000, 500, NULL)`,
+		`INSERT INTO responses VALUES ('r2', 'gpt-4.1', 'Now by region', NULL, NULL, NULL, '...', NULL, 'c1', 2500, '2026-02-07T10:05:00.000000', 12000, 800, NULL)`,
+		`INSERT INTO responses VALUES ('r3', 'anthropic/claude-sonnet-4-5', 'Write a regex for emails', NULL, NULL, NULL, '...', NULL, NULL, 900, '2026-02-08T09:00:00', 100, 50, '{"cache_read_input_tokens": 2000}')`,
+	}
+	for _, s := range stmts {
+		if _, err := db.Exec(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+
+	l := NewLLMCLI([]string{dir})
+	if !l.Available() {
+		t.Fatal("expected llm to be available")
+	}
+	data, err := l.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(data.Sessions))
+	}
+
+	conv := data.Sessions[0]
+	if conv.ID != "c1" || conv.Title != "Summarize churn data" || conv.UserMessages != 2 {
+		t.Errorf("unexpected conversation %+v", conv)
+	}
+	if conv.Requests != 2 || conv.Latency != 4*time.Second {
+		t.Errorf("expected 2 requests taking 4s, got %d and %s", conv.Requests, conv.Latency)
+	}
+	if !conv.StartTime.Equal(time.Date(2026, 2, 7, 10, 0, 0, 123456000, time.UTC)) {
+		t.Errorf("unexpected start time %s", conv.StartTime)
+	}
+	if conv.Cost <= 0 {
+		t.Error("expected gpt-4.1 responses to be priced")
+	}
+
+	single := data.Sessions[1]
+	if single.Title != "Write a regex for emails" || single.Model != "claude-sonnet-4-5" {
+		t.Errorf("unexpected single-response session %+v", single)
+	}
+	if single.Turns[0].CacheRead != 2000 || single.Tokens != 2150 {
+		t.Errorf("expected cache reads from token_details, got %+v", single.Turns[0])
+	}
+}
+
+func TestParseLLMTime(t *testing.T) {
+	want := time.Date(2025, 2, 7, 10, 0, 0, 123456000, time.UTC)
+	for _, s := range []string{
+		"2025-02-07T10:00:00.123456",
+		"2025-02-07 10:00:00.123456",
+		"2025-02-07T10:00:00.123456Z",
+		"2025-02-07T10:00:00.123456+00:00",
+		"2025-02-07T11:00:00.123456+01:00",
+	} {
+		if got := parseLLMTime(s); !got.Equal(want) {
+			t.Errorf("parseLLMTime(%q) = %v, want %v", s, got, want)
+		}
+	}
+}
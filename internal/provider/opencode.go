@@ -0,0 +1,340 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/isaacaudet/aitop/internal/model"
+)
+
+// OpenCode implements Provider for sst/opencode. It walks the JSON storage
+// tree under each data dir (~/.local/share/opencode by default):
+// storage/session/<project>/<session>.json, storage/message/<session>/*.json
+// and storage/part/<message>/*.json.
+type OpenCode struct {
+	DataDirs []string
+}
+
+func NewOpenCode(dataDirs []string) *OpenCode {
+	return &OpenCode{DataDirs: dataDirs}
+}
+
+func (o *OpenCode) Name() string  { return "OpenCode" }
+func (o *OpenCode) Icon() string  { return "⌬" }
+func (o *OpenCode) Color() string { return "#fab387" } // Peach
+
+func (o *OpenCode) Available() bool {
+	for _, dir := range o.DataDirs {
+		if _, err := os.Stat(filepath.Join(dir, "storage", "session")); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// openCodeSession is storage/session/<projectID>/<sessionID>.json.
+type openCodeSession struct {
+	ID        string `json:"id"`
+	ProjectID string `json:"projectID"`
+	ParentID  string `json:"parentID"`
+	Directory string `json:"directory"`
+	Title     string `json:"title"`
+	Time      struct {
+		Created int64 `json:"created"`
+		Updated int64 `json:"updated"`
+	} `json:"time"`
+}
+
+// openCodeMessage is storage/message/<sessionID>/<messageID>.json.
+type openCodeMessage struct {
+	ID         string  `json:"id"`
+	Role       string  `json:"role"`
+	ModelID    string  `json:"modelID"`
+	ProviderID string  `json:"providerID"`
+	Cost       float64 `json:"cost"`
+	Time       struct {
+		Created   int64 `json:"created"`
+		Completed int64 `json:"completed"`
+	} `json:"time"`
+	Tokens struct {
+		Input     int `json:"input"`
+		Output    int `json:"output"`
+		Reasoning int `json:"reasoning"`
+		Cache     struct {
+			Read  int `json:"read"`
+			Write int `json:"write"`
+		} `json:"cache"`
+	} `json:"tokens"`
+}
+
+// openCodePart is storage/part/<messageID>/<partID>.json.
+type openCodePart struct {
+	Type      string `json:"type"`
+	Text      string `json:"text"`
+	Synthetic bool   `json:"synthetic"`
+}
+
+// openCodeProject is storage/project/<projectID>.json.
+type openCodeProject struct {
+	Worktree string `json:"worktree"`
+}
+
+func (o *OpenCode) Load() (*ProviderData, error) {
+	data := &ProviderData{
+		ProviderName: o.Name(),
+		Icon:         o.Icon(),
+		Color:        o.Color(),
+		Metadata:     make(map[string]string),
+	}
+
+	totals := newUsageTotals()
+
+	// Sessions are read up front so sub-agent sessions, which opencode
+	// stores beside their parent, can be folded into it whatever the order.
+	type storedSession struct {
+		openCodeSession
+		storage string
+		project string
+	}
+	var sessions []storedSession
+	byID := make(map[string]int)
+	found := false
+	for _, dir := range o.DataDirs {
+		storage := filepath.Join(dir, "storage")
+		sessionFiles, err := filepath.Glob(filepath.Join(storage, "session", "*", "*.json"))
+		if err != nil || len(sessionFiles) == 0 {
+			continue
+		}
+		found = true
+		worktrees := make(map[string]string)
+
+		for _, path := range sessionFiles {
+			var sess openCodeSession
+			if readJSON(path, &sess) != nil || sess.ID == "" {
+				continue
+			}
+			if _, ok := byID[sess.ID]; ok {
+				continue
+			}
+
+			project := sess.Directory
+			if project == "" && sess.ProjectID != "" {
+				wt, ok := worktrees[sess.ProjectID]
+				if !ok {
+					var p openCodeProject
+					_ = readJSON(filepath.Join(storage, "project", sess.ProjectID+".json"), &p)
+					wt = p.Worktree
+					worktrees[sess.ProjectID] = wt
+				}
+				project = wt
+			}
+			byID[sess.ID] = len(sessions)
+			sessions = append(sessions, storedSession{sess, storage, project})
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("no opencode sessions found")
+	}
+
+	// root returns the top-level session a sub-agent ran under, or "" for a
+	// top-level session. Sessions whose parent links form a cycle have no
+	// top-level session, so they're treated as one.
+	root := func(sess openCodeSession) string {
+		id, ok := rootSession(sess.ID, func(id string) string {
+			i, ok := byID[id]
+			if !ok {
+				return ""
+			}
+			if _, ok := byID[sessions[i].ParentID]; !ok {
+				return ""
+			}
+			return sessions[i].ParentID
+		})
+		if !ok {
+			return ""
+		}
+		return id
+	}
+
+	index := make(map[string]int)
+	var promptLengths []int
+	for _, sess := range sessions {
+		if root(sess.openCodeSession) != "" {
+			continue
+		}
+		si := SessionInfo{
+			ID:        sess.ID,
+			Title:     sess.Title,
+			Project:   sess.project,
+			StartTime: time.UnixMilli(sess.Time.Created),
+			EndTime:   time.UnixMilli(sess.Time.Updated),
+		}
+		if sess.Time.Updated == 0 {
+			si.EndTime = si.StartTime
+		}
+		prompts := o.addMessages(&si, sess.storage, sess.ID, totals, false)
+		if si.Title == "" && len(prompts) > 0 {
+			si.Title = promptTitle(prompts[0])
+		}
+		si.Prompts = len(prompts)
+		for _, p := range prompts {
+			promptLengths = append(promptLengths, utf8.RuneCountInString(p))
+		}
+		index[sess.ID] = len(data.Sessions)
+		data.Sessions = append(data.Sessions, si)
+	}
+
+	// Sub-agent sessions add their calls to the session that started them.
+	// opencode doesn't count a sub-agent's cost towards its parent, so
+	// nothing is counted twice.
+	for _, sess := range sessions {
+		parent := root(sess.openCodeSession)
+		if parent == "" {
+			continue
+		}
+		idx, ok := index[parent]
+		if !ok {
+			continue
+		}
+		si := &data.Sessions[idx]
+		o.addMessages(si, sess.storage, sess.ID, totals, true)
+		if end := time.UnixMilli(max(sess.Time.Updated, sess.Time.Created)); end.After(si.EndTime) {
+			si.EndTime = end
+		}
+		sort.SliceStable(si.Turns, func(i, j int) bool { return si.Turns[i].Timestamp.Before(si.Turns[j].Timestamp) })
+	}
+
+	for _, si := range data.Sessions {
+		du := totals.day(si.StartTime)
+		du.Sessions++
+		du.Messages += si.Messages
+		du.Prompts += si.Prompts
+		data.TotalCost += si.Cost
+		totals.span(si.StartTime, si.EndTime)
+	}
+	if len(promptLengths) > 0 {
+		data.Prompts = newPromptStats(promptLengths, len(data.Sessions))
+	}
+
+	totals.fill(data)
+
+	return data, nil
+}
+
+// addMessages adds a session's messages to si and returns the prompts typed
+// into it. A sub-agent's user messages are written by the parent agent, so
+// they count as messages but not as prompts.
+func (o *OpenCode) addMessages(si *SessionInfo, storage, sessionID string, totals *usageTotals, subagent bool) []string {
+	var prompts []string
+	for _, msg := range loadOpenCodeMessages(filepath.Join(storage, "message", sessionID)) {
+		si.Messages++
+		ts := time.UnixMilli(msg.Time.Created)
+		if msg.Role == "user" {
+			if subagent {
+				continue
+			}
+			si.UserMessages++
+			if text := openCodePromptText(filepath.Join(storage, "part", msg.ID)); text != "" {
+				prompts = append(prompts, text)
+			}
+			continue
+		}
+		if msg.Role != "assistant" {
+			continue
+		}
+
+		m := msg.ModelID
+		if m == "" {
+			m = "unknown"
+		}
+		input := msg.Tokens.Input
+		output := msg.Tokens.Output + msg.Tokens.Reasoning
+		cacheRead := msg.Tokens.Cache.Read
+		cacheWrite := msg.Tokens.Cache.Write
+		cost := msg.Cost
+		if cost == 0 {
+			cost = model.CalculateCost(m, model.TokenUsage{
+				InputTokens:  input,
+				OutputTokens: output,
+				CacheRead:    cacheRead,
+				CacheWrite:   cacheWrite,
+			})
+		}
+		tokens := input + output + cacheRead + cacheWrite
+
+		if !subagent {
+			si.Model = m
+		}
+		si.Tokens += tokens
+		si.Cost += cost
+		si.Turns = append(si.Turns, TurnUsage{
+			Timestamp:    ts,
+			Model:        m,
+			InputTokens:  input,
+			OutputTokens: output,
+			CacheRead:    cacheRead,
+			CacheWrite:   cacheWrite,
+			Cost:         cost,
+		})
+
+		mb := totals.model(m)
+		mb.InputTokens += input
+		mb.OutputTokens += output
+		mb.CacheRead += cacheRead
+		mb.CacheWrite += cacheWrite
+		mb.Cost += cost
+		mb.Requests++
+
+		du := totals.day(ts)
+		du.Cost += cost
+		du.Tokens += tokens
+	}
+	return prompts
+}
+
+// loadOpenCodeMessages reads a session's messages ordered by creation time.
+func loadOpenCodeMessages(dir string) []openCodeMessage {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	var msgs []openCodeMessage
+	for _, f := range files {
+		var msg openCodeMessage
+		if readJSON(f, &msg) == nil {
+			msgs = append(msgs, msg)
+		}
+	}
+	sort.SliceStable(msgs, func(i, j int) bool { return msgs[i].Time.Created < msgs[j].Time.Created })
+	return msgs
+}
+
+// openCodePromptText joins the text parts the user typed into a message,
+// skipping synthetic parts opencode adds (such as attached file contents).
+func openCodePromptText(dir string) string {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	sort.Strings(files)
+	var texts []string
+	for _, f := range files {
+		var part openCodePart
+		if readJSON(f, &part) != nil || part.Type != "text" || part.Synthetic {
+			continue
+		}
+		if t := strings.TrimSpace(part.Text); t != "" {
+			texts = append(texts, t)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// readJSON unmarshals the JSON file at path into v.
+func readJSON(path string, v interface{}) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
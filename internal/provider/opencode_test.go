@@ -0,0 +1,81 @@
+package provider
+
+import (
+	"math"
+	"testing"
+)
+
+func TestOpenCodeSessions(t *testing.T) {
+	o := NewOpenCode([]string{"../../testdata/opencode"})
+	if !o.Available() {
+		t.Fatal("expected OpenCode to be available")
+	}
+	data, err := o.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(data.Sessions))
+	}
+
+	byID := make(map[string]SessionInfo)
+	for _, s := range data.Sessions {
+		byID[s.ID] = s
+	}
+
+	s := byID["ses_001"]
+	if s.Title != "Add checkout flow" || s.Project != "/Users/dev/shop" {
+		t.Errorf("unexpected title/project %q / %q", s.Title, s.Project)
+	}
+	// The sub-agent session is folded in: its two messages and one call
+	// count towards the parent, but its task prompt isn't the user's.
+	if s.Messages != 6 || s.UserMessages != 2 || len(s.Turns) != 3 {
+		t.Errorf("unexpected message counts %d/%d/%d", s.Messages, s.UserMessages, len(s.Turns))
+	}
+	// Reasoning tokens count as output; the recorded cost is used as is.
+	if s.Turns[0].OutputTokens != 1000 || s.Turns[0].CacheWrite != 3000 {
+		t.Errorf("unexpected first turn %+v", s.Turns[0])
+	}
+	if sub := s.Turns[1]; sub.Model != "claude-haiku-4-5" || sub.InputTokens != 2000 {
+		t.Errorf("expected the sub-agent's call in time order, got %+v", sub)
+	}
+	if s.Model != "claude-sonnet-4-5" {
+		t.Errorf("expected the parent's model, got %q", s.Model)
+	}
+	if math.Abs(s.Cost-0.0646) > 1e-9 {
+		t.Errorf("expected recorded cost $0.0646, got $%.4f", s.Cost)
+	}
+	if s.Prompts != 2 {
+		t.Errorf("expected 2 prompts, got %d", s.Prompts)
+	}
+
+	// Untitled session: project from the project's worktree, title from the
+	// first prompt, cost computed since none was recorded.
+	s2 := byID["ses_002"]
+	if s2.Project != "/Users/dev/shop" || s2.Title != "Why is the build slow?" {
+		t.Errorf("unexpected project/title %q / %q", s2.Project, s2.Title)
+	}
+	if s2.Model != "gpt-4.1" || s2.Cost <= 0 {
+		t.Errorf("expected a priced gpt-4.1 session, got %s at $%.4f", s2.Model, s2.Cost)
+	}
+
+	// Synthetic parts are not part of the prompt.
+	if data.Prompts == nil || data.Prompts.Total != 3 || data.Prompts.MaxLength != len("Add a checkout flow with Stripe") {
+		t.Errorf("unexpected prompt stats %+v", data.Prompts)
+	}
+}
+
+func TestRootSessionCycle(t *testing.T) {
+	parents := map[string]string{"child": "mid", "mid": "top", "a": "b", "b": "a"}
+	parent := func(id string) string { return parents[id] }
+
+	if root, ok := rootSession("child", parent); !ok || root != "top" {
+		t.Errorf("expected child to roll up to top, got %q (ok=%v)", root, ok)
+	}
+	if root, ok := rootSession("top", parent); !ok || root != "" {
+		t.Errorf("expected top to be top-level, got %q (ok=%v)", root, ok)
+	}
+	if _, ok := rootSession("a", parent); ok {
+		t.Error("expected the a/b cycle to be reported")
+	}
+}
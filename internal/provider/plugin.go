@@ -0,0 +1,433 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/isaacaudet/aitop/internal/model"
+)
+
+// DefaultPluginTimeout bounds how long a plugin may run.
+const DefaultPluginTimeout = 30 * time.Second
+
+// Plugin implements Provider by running an external command, such as
+// aitop-provider-foo, and reading the usage it prints to stdout.
+//
+// The command is run as `<command> [args...] --since <RFC 3339 time>` and
+// writes either one JSON document or NDJSON records; see pluginOutput and
+// pluginRecord. Anything it writes to stderr is reported if it fails.
+type Plugin struct {
+	Command string
+	Args    []string
+	Since   time.Time     // Oldest data to report; zero asks for all of it
+	Timeout time.Duration // Defaults to DefaultPluginTimeout
+
+	name, icon, color string
+}
+
+// NewPlugin returns a plugin provider. An empty name is derived from the
+// command (aitop-provider-foo is called "foo"); an empty icon or color is
+// taken from the plugin's output, else a default.
+func NewPlugin(name, icon, color, command string, args []string) *Plugin {
+	if name == "" {
+		name = strings.TrimPrefix(filepath.Base(command), "aitop-provider-")
+	}
+	return &Plugin{Command: command, Args: args, Timeout: DefaultPluginTimeout, name: name, icon: icon, color: color}
+}
+
+func (p *Plugin) Name() string { return p.name }
+
+func (p *Plugin) Icon() string {
+	if p.icon == "" {
+		return "⧉"
+	}
+	return p.icon
+}
+
+func (p *Plugin) Color() string {
+	if p.color == "" {
+		return "#6c7086" // Overlay0
+	}
+	return p.color
+}
+
+func (p *Plugin) Available() bool {
+	_, err := exec.LookPath(p.Command)
+	return err == nil
+}
+
+// pluginOutput is the single-document form of a plugin's output. Every
+// section is optional. Sessions, models and daily usage are taken as given;
+// events are folded into them, so a plugin can report raw per-response usage
+// and leave the aggregation to aitop. A reported section replaces the one
+// derived from events, except sessions, which are merged by id.
+type pluginOutput struct {
+	Provider *pluginMeta       `json:"provider"`
+	Sessions []pluginSession   `json:"sessions"`
+	Models   []pluginModel     `json:"models"`
+	Daily    []pluginDaily     `json:"daily"`
+	Events   []pluginEvent     `json:"events"`
+	Metadata map[string]string `json:"metadata"`
+}
+
+// pluginRecord is one line of NDJSON output: an object whose "type" is
+// provider, session, model, daily or event, with that section's fields.
+type pluginRecord struct {
+	Type string `json:"type"`
+}
+
+type pluginMeta struct {
+	Icon  string `json:"icon"`
+	Color string `json:"color"`
+}
+
+type pluginSession struct {
+	ID           string    `json:"id"`
+	Title        string    `json:"title"`
+	Project      string    `json:"project"`
+	Model        string    `json:"model"`
+	Start        time.Time `json:"start"`
+	End          time.Time `json:"end"`
+	Messages     int       `json:"messages"`
+	UserMessages int       `json:"user_messages"`
+	Tokens       int       `json:"tokens"`
+	Cost         *float64  `json:"cost"`
+	Requests     int       `json:"requests"`
+	Errors       int       `json:"errors"`
+	LatencyMS    int64     `json:"latency_ms"`
+}
+
+type pluginModel struct {
+	Model        string   `json:"model"`
+	InputTokens  int      `json:"input_tokens"`
+	OutputTokens int      `json:"output_tokens"`
+	CacheRead    int      `json:"cache_read_tokens"`
+	CacheWrite   int      `json:"cache_write_tokens"`
+	Cost         *float64 `json:"cost"`
+	Requests     int      `json:"requests"`
+}
+
+type pluginDaily struct {
+	Date     string  `json:"date"` // YYYY-MM-DD
+	Cost     float64 `json:"cost"`
+	Tokens   int     `json:"tokens"`
+	Messages int     `json:"messages"`
+	Sessions int     `json:"sessions"`
+}
+
+type pluginEvent struct {
+	Timestamp  time.Time `json:"timestamp"`
+	Session    string    `json:"session"`
+	Project    string    `json:"project"`
+	Model      string    `json:"model"`
+	Input      int       `json:"input_tokens"`
+	Output     int       `json:"output_tokens"`
+	CacheRead  int       `json:"cache_read_tokens"`
+	CacheWrite int       `json:"cache_write_tokens"`
+	Cost       *float64  `json:"cost"`
+}
+
+func (p *Plugin) Load() (*ProviderData, error) {
+	out, err := p.run()
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := parsePluginOutput(out)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", p.name, err)
+	}
+	return p.providerData(parsed), nil
+}
+
+// run executes the plugin and returns its stdout.
+func (p *Plugin) run() ([]byte, error) {
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = DefaultPluginTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	since := p.Since
+	if since.IsZero() {
+		since = time.Unix(0, 0)
+	}
+	args := append(append([]string(nil), p.Args...), "--since", since.UTC().Format(time.RFC3339))
+	cmd := exec.CommandContext(ctx, p.Command, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("%s: timed out after %s", p.name, timeout)
+	}
+	if err != nil {
+		if msg := lastLine(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s: %w: %s", p.name, err, msg)
+		}
+		return nil, fmt.Errorf("%s: %w", p.name, err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// lastLine returns the last non-empty line of s, which is usually the error.
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+// parsePluginOutput reads a JSON document or a stream of NDJSON records.
+func parsePluginOutput(raw []byte) (*pluginOutput, error) {
+	out := &pluginOutput{}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	for {
+		var obj json.RawMessage
+		if err := dec.Decode(&obj); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("parsing output: %w", err)
+		}
+		var rec pluginRecord
+		if err := json.Unmarshal(obj, &rec); err != nil {
+			return nil, fmt.Errorf("parsing output: %w", err)
+		}
+		if err := out.add(rec.Type, obj); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// add merges one decoded value: a whole document when typ is empty, or a
+// single NDJSON record.
+func (o *pluginOutput) add(typ string, obj json.RawMessage) error {
+	var err error
+	switch typ {
+	case "":
+		var doc pluginOutput
+		if err = json.Unmarshal(obj, &doc); err == nil {
+			if doc.Provider != nil {
+				o.Provider = doc.Provider
+			}
+			o.Sessions = append(o.Sessions, doc.Sessions...)
+			o.Models = append(o.Models, doc.Models...)
+			o.Daily = append(o.Daily, doc.Daily...)
+			o.Events = append(o.Events, doc.Events...)
+			for k, v := range doc.Metadata {
+				if o.Metadata == nil {
+					o.Metadata = make(map[string]string)
+				}
+				o.Metadata[k] = v
+			}
+		}
+	case "provider":
+		o.Provider = &pluginMeta{}
+		err = json.Unmarshal(obj, o.Provider)
+	case "session":
+		var s pluginSession
+		if err = json.Unmarshal(obj, &s); err == nil {
+			o.Sessions = append(o.Sessions, s)
+		}
+	case "model":
+		var m pluginModel
+		if err = json.Unmarshal(obj, &m); err == nil {
+			o.Models = append(o.Models, m)
+		}
+	case "daily":
+		var d pluginDaily
+		if err = json.Unmarshal(obj, &d); err == nil {
+			o.Daily = append(o.Daily, d)
+		}
+	case "event":
+		var e pluginEvent
+		if err = json.Unmarshal(obj, &e); err == nil {
+			o.Events = append(o.Events, e)
+		}
+	default:
+		// Unknown record types are ignored so the protocol can grow.
+	}
+	if err != nil && typ == "" {
+		return fmt.Errorf("parsing output: %w", err)
+	} else if err != nil {
+		return fmt.Errorf("parsing %s record: %w", typ, err)
+	}
+	return nil
+}
+
+// providerData converts parsed output into ProviderData.
+func (p *Plugin) providerData(o *pluginOutput) *ProviderData {
+	data := &ProviderData{
+		ProviderName: p.Name(),
+		Icon:         p.Icon(),
+		Color:        p.Color(),
+		Metadata:     make(map[string]string),
+	}
+	if o.Provider != nil {
+		if p.icon == "" && o.Provider.Icon != "" {
+			data.Icon = o.Provider.Icon
+		}
+		if p.color == "" && o.Provider.Color != "" {
+			data.Color = o.Provider.Color
+		}
+	}
+	for k, v := range o.Metadata {
+		data.Metadata[k] = v
+	}
+
+	agg := newEventAggregator()
+	for _, e := range o.Events {
+		if e.Timestamp.IsZero() {
+			continue
+		}
+		ev := usageEvent{
+			Timestamp: e.Timestamp,
+			Session:   e.Session,
+			Project:   e.Project,
+			Model:     e.Model,
+			Usage: model.TokenUsage{
+				InputTokens:  e.Input,
+				OutputTokens: e.Output,
+				CacheRead:    e.CacheRead,
+				CacheWrite:   e.CacheWrite,
+			},
+		}
+		if e.Cost != nil {
+			ev.Cost, ev.HasCost = *e.Cost, true
+		}
+		agg.add(ev)
+	}
+	agg.fill(data)
+
+	if len(o.Sessions) > 0 {
+		byID := make(map[string]int)
+		for i, si := range data.Sessions {
+			byID[si.ID] = i
+		}
+		for _, s := range o.Sessions {
+			si := s.sessionInfo()
+			if i, ok := byID[s.ID]; ok && s.ID != "" {
+				// Keep the per-response turns the events provided.
+				si.Turns = data.Sessions[i].Turns
+				data.Sessions[i] = si
+				continue
+			}
+			data.Sessions = append(data.Sessions, si)
+		}
+	}
+	if len(o.Models) > 0 {
+		data.Models = data.Models[:0]
+		for _, m := range o.Models {
+			data.Models = append(data.Models, m.breakdown())
+		}
+		sort.Slice(data.Models, func(i, j int) bool {
+			return data.Models[i].Cost > data.Models[j].Cost
+		})
+	}
+	if len(o.Daily) > 0 {
+		data.DailyUsage = data.DailyUsage[:0]
+		for _, d := range o.Daily {
+			data.DailyUsage = append(data.DailyUsage, DailyUsage{
+				Date:     d.Date,
+				Cost:     d.Cost,
+				Tokens:   d.Tokens,
+				Messages: d.Messages,
+				Sessions: d.Sessions,
+			})
+		}
+		sort.Slice(data.DailyUsage, func(i, j int) bool {
+			return data.DailyUsage[i].Date < data.DailyUsage[j].Date
+		})
+	}
+
+	// The total follows the most complete section reported.
+	data.TotalCost = 0
+	switch {
+	case len(data.Models) > 0:
+		for _, m := range data.Models {
+			data.TotalCost += m.Cost
+		}
+	case len(data.DailyUsage) > 0:
+		for _, d := range data.DailyUsage {
+			data.TotalCost += d.Cost
+		}
+	default:
+		for _, si := range data.Sessions {
+			data.TotalCost += si.Cost
+		}
+	}
+
+	for _, si := range data.Sessions {
+		if !si.StartTime.IsZero() && (data.FirstSeen.IsZero() || si.StartTime.Before(data.FirstSeen)) {
+			data.FirstSeen = si.StartTime
+		}
+		if si.EndTime.After(data.LastSeen) {
+			data.LastSeen = si.EndTime
+		}
+	}
+	if data.FirstSeen.IsZero() && len(data.DailyUsage) > 0 {
+		// Daily totals only: the range is the days they cover.
+		data.FirstSeen, _ = time.ParseInLocation("2006-01-02", data.DailyUsage[0].Date, time.Local)
+		last, _ := time.ParseInLocation("2006-01-02", data.DailyUsage[len(data.DailyUsage)-1].Date, time.Local)
+		data.LastSeen = last.AddDate(0, 0, 1).Add(-time.Second)
+	}
+	return data
+}
+
+func (s pluginSession) sessionInfo() SessionInfo {
+	si := SessionInfo{
+		ID:           s.ID,
+		Title:        s.Title,
+		Project:      s.Project,
+		Model:        s.Model,
+		StartTime:    s.Start,
+		EndTime:      s.End,
+		Messages:     s.Messages,
+		UserMessages: s.UserMessages,
+		Prompts:      s.UserMessages,
+		Tokens:       s.Tokens,
+		Requests:     s.Requests,
+		Errors:       s.Errors,
+	}
+	if si.EndTime.IsZero() {
+		si.EndTime = si.StartTime
+	}
+	if s.Cost != nil {
+		si.Cost = *s.Cost
+	}
+	if s.LatencyMS > 0 {
+		si.Latency = time.Duration(s.LatencyMS) * time.Millisecond
+	}
+	return si
+}
+
+func (m pluginModel) breakdown() ModelBreakdown {
+	mb := ModelBreakdown{
+		Model:        m.Model,
+		InputTokens:  m.InputTokens,
+		OutputTokens: m.OutputTokens,
+		CacheRead:    m.CacheRead,
+		CacheWrite:   m.CacheWrite,
+		Requests:     m.Requests,
+	}
+	if m.Cost != nil {
+		mb.Cost = *m.Cost
+	} else {
+		mb.Cost = model.CalculateCost(m.Model, model.TokenUsage{
+			InputTokens:  m.InputTokens,
+			OutputTokens: m.OutputTokens,
+			CacheRead:    m.CacheRead,
+			CacheWrite:   m.CacheWrite,
+		})
+	}
+	return mb
+}
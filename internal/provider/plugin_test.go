@@ -0,0 +1,139 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestPluginHelperProcess is not a real test: the plugin tests run the test
+// binary itself as the plugin, which then behaves according to
+// AITOP_PLUGIN_MODE.
+func TestPluginHelperProcess(t *testing.T) {
+	mode := os.Getenv("AITOP_PLUGIN_MODE")
+	if mode == "" {
+		return
+	}
+	args := os.Args
+	if len(args) < 2 || args[len(args)-2] != "--since" {
+		fmt.Fprintln(os.Stderr, "missing --since")
+		os.Exit(2)
+	}
+	if _, err := time.Parse(time.RFC3339, args[len(args)-1]); err != nil {
+		fmt.Fprintln(os.Stderr, "bad --since:", err)
+		os.Exit(2)
+	}
+	switch mode {
+	case "fail":
+		fmt.Fprintln(os.Stderr, "connecting to usage API")
+		fmt.Fprintln(os.Stderr, "error: token expired")
+		os.Exit(1)
+	case "hang":
+		time.Sleep(time.Minute)
+	default:
+		raw, err := os.ReadFile(mode)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Stdout.Write(raw)
+	}
+	os.Exit(0)
+}
+
+func helperPlugin(t *testing.T, mode string) *Plugin {
+	t.Setenv("AITOP_PLUGIN_MODE", mode)
+	return NewPlugin("", "", "", os.Args[0], []string{"-test.run=TestPluginHelperProcess", "--"})
+}
+
+func TestPluginDocument(t *testing.T) {
+	p := helperPlugin(t, "../../testdata/plugins/report.json")
+	if !p.Available() {
+		t.Fatal("expected the plugin to be available")
+	}
+	data, err := p.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.Icon != "⛭" || data.Color != "#f9e2af" {
+		t.Errorf("expected the plugin's icon and color, got %q %q", data.Icon, data.Color)
+	}
+	if len(data.Sessions) != 1 || data.Sessions[0].Latency != 9*time.Second || data.Sessions[0].Cost != 0.84 {
+		t.Fatalf("unexpected sessions %+v", data.Sessions)
+	}
+	if len(data.Models) != 1 || data.Models[0].Requests != 3 {
+		t.Errorf("unexpected models %+v", data.Models)
+	}
+	if data.TotalCost != 0.84 || len(data.DailyUsage) != 1 || data.Metadata["team"] != "platform" {
+		t.Errorf("unexpected totals %f %+v %v", data.TotalCost, data.DailyUsage, data.Metadata)
+	}
+}
+
+func TestPluginNDJSONEvents(t *testing.T) {
+	p := helperPlugin(t, "../../testdata/plugins/events.ndjson")
+	p.icon = "★"
+	data, err := p.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.Icon != "★" {
+		t.Errorf("expected the configured icon to win, got %q", data.Icon)
+	}
+	if len(data.Sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(data.Sessions))
+	}
+	billing := data.Sessions[0]
+	if billing.ID != "e-1" || billing.Project != "/srv/billing" || len(billing.Turns) != 2 || billing.Tokens != 3800 {
+		t.Errorf("unexpected event session %+v", billing)
+	}
+	// The reported session replaces the one built from its events but keeps
+	// their turns.
+	notes := data.Sessions[1]
+	if notes.Title != "Draft release notes" || len(notes.Turns) != 1 || notes.EndTime.Sub(notes.StartTime) != 5*time.Minute {
+		t.Errorf("unexpected merged session %+v", notes)
+	}
+	if len(data.Models) != 2 || len(data.DailyUsage) != 2 {
+		t.Errorf("expected models and days derived from events, got %d %d", len(data.Models), len(data.DailyUsage))
+	}
+	if data.TotalCost <= 0.002 {
+		t.Errorf("expected unpriced events to be priced by model, got %f", data.TotalCost)
+	}
+}
+
+func TestPluginErrors(t *testing.T) {
+	p := helperPlugin(t, "fail")
+	_, err := p.Load()
+	if err == nil || !strings.Contains(err.Error(), "token expired") {
+		t.Errorf("expected stderr in the error, got %v", err)
+	}
+
+	p = helperPlugin(t, "hang")
+	p.Timeout = 200 * time.Millisecond
+	start := time.Now()
+	if _, err := p.Load(); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected a timeout, got %v", err)
+	}
+	if time.Since(start) > 10*time.Second {
+		t.Error("plugin wasn't stopped at the timeout")
+	}
+
+	if NewPlugin("", "", "", "aitop-provider-does-not-exist", nil).Available() {
+		t.Error("expected a missing command to be unavailable")
+	}
+}
+
+func TestPluginName(t *testing.T) {
+	if got := NewPlugin("", "", "", "/usr/local/bin/aitop-provider-foo", nil).Name(); got != "foo" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestParsePluginOutputErrors(t *testing.T) {
+	for _, raw := range []string{`{"sessions": 5}`, `{"type": "event", "timestamp": "yesterday"}`, `{"models": [`} {
+		if _, err := parsePluginOutput([]byte(raw)); err == nil {
+			t.Errorf("%s: expected an error", raw)
+		}
+	}
+}
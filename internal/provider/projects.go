@@ -0,0 +1,273 @@
+package provider
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ProjectResolver maps hashed project identifiers back to directories by
+// hashing every project directory aitop knows about: working directories
+// recorded in Claude Code and Codex transcripts plus git repositories under
+// the configured workspace roots. Resolved hashes are cached on disk, as are
+// hashes that couldn't be resolved, so deleted projects aren't searched for
+// on every load.
+type ProjectResolver struct {
+	CachePath      string
+	ClaudeDirs     []string // Claude config dirs
+	CodexDirs      []string // Codex homes
+	WorkspaceRoots []string
+}
+
+// workspaceScanDepth limits how deep workspace roots are searched for git repos.
+const workspaceScanDepth = 3
+
+// missRetry is how long an unresolved hash is left before searching again.
+const missRetry = 24 * time.Hour
+
+// projectCache is the on-disk cache of resolved and unresolved hashes.
+type projectCache struct {
+	Resolved map[string]string    `json:"resolved"`
+	Misses   map[string]time.Time `json:"misses"` // When each hash was last searched for
+}
+
+// Resolve returns a hash → directory mapping for as many of hashes as can be
+// resolved. extra lists tool-specific candidate directories to try first.
+func (r *ProjectResolver) Resolve(hashes []string, extra []string) map[string]string {
+	cache := r.loadCache()
+	resolved := cache.Resolved
+
+	wanted := make(map[string]bool)
+	for _, h := range hashes {
+		if _, ok := resolved[h]; !ok {
+			wanted[h] = true
+		}
+	}
+	if len(wanted) == 0 {
+		return resolved
+	}
+
+	changed := false
+	try := func(dir string) {
+		if dir == "" {
+			return
+		}
+		if h := sha256Hex(dir); wanted[h] {
+			resolved[h] = dir
+			delete(wanted, h)
+			delete(cache.Misses, h)
+			changed = true
+		}
+	}
+
+	for _, dir := range extra {
+		try(dir)
+	}
+
+	// Only search everywhere if some hash wasn't already searched for
+	// recently.
+	now := time.Now()
+	stale := false
+	for h := range wanted {
+		if now.Sub(cache.Misses[h]) >= missRetry {
+			stale = true
+		}
+	}
+	if stale {
+		for _, dir := range r.candidates() {
+			try(dir)
+			if len(wanted) == 0 {
+				break
+			}
+		}
+		for h := range wanted {
+			cache.Misses[h] = now
+			changed = true
+		}
+	}
+
+	if changed {
+		r.saveCache(cache)
+	}
+	return resolved
+}
+
+// candidates lists known project directories, deduplicated.
+func (r *ProjectResolver) candidates() []string {
+	seen := make(map[string]bool)
+	var dirs []string
+	add := func(dir string) {
+		if dir != "" && !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+
+	for _, dir := range r.ClaudeDirs {
+		files, _ := filepath.Glob(filepath.Join(dir, "projects", "*", "*.jsonl"))
+		for _, f := range files {
+			add(firstJSONLField(f, func(line []byte) string {
+				var msg struct {
+					CWD string `json:"cwd"`
+				}
+				_ = json.Unmarshal(line, &msg)
+				return msg.CWD
+			}))
+		}
+	}
+
+	for _, home := range r.CodexDirs {
+		for _, sub := range []string{"sessions", "archived_sessions"} {
+			filepath.Walk(filepath.Join(home, sub), func(path string, info os.FileInfo, err error) error {
+				if err != nil || info.IsDir() || !strings.HasSuffix(path, ".jsonl") {
+					return nil
+				}
+				add(firstJSONLField(path, func(line []byte) string {
+					var meta codexSessionMeta
+					_ = json.Unmarshal(line, &meta)
+					return meta.Payload.CWD
+				}))
+				return nil
+			})
+		}
+	}
+
+	for _, root := range r.WorkspaceRoots {
+		for _, repo := range findGitRepos(root, workspaceScanDepth) {
+			add(repo)
+		}
+	}
+
+	return dirs
+}
+
+// firstJSONLField returns the first non-empty value extract yields for the
+// leading lines of a JSONL file.
+func firstJSONLField(path string, extract func([]byte) string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024) // 1MB max line
+	for i := 0; i < 10 && scanner.Scan(); i++ {
+		if v := extract(scanner.Bytes()); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// findGitRepos returns directories containing a .git entry, searching at most
+// depth levels below root. Hidden directories and node_modules are skipped.
+func findGitRepos(root string, depth int) []string {
+	if _, err := os.Stat(filepath.Join(root, ".git")); err == nil {
+		return []string{root}
+	}
+	if depth == 0 {
+		return nil
+	}
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil
+	}
+	var repos []string
+	for _, e := range entries {
+		name := e.Name()
+		if !e.IsDir() || strings.HasPrefix(name, ".") || name == "node_modules" {
+			continue
+		}
+		repos = append(repos, findGitRepos(filepath.Join(root, name), depth-1)...)
+	}
+	return repos
+}
+
+func (r *ProjectResolver) loadCache() projectCache {
+	var cache projectCache
+	if r.CachePath != "" {
+		if raw, err := os.ReadFile(r.CachePath); err == nil {
+			_ = json.Unmarshal(raw, &cache)
+		}
+	}
+	if cache.Resolved == nil {
+		cache.Resolved = make(map[string]string)
+	}
+	if cache.Misses == nil {
+		cache.Misses = make(map[string]time.Time)
+	}
+	return cache
+}
+
+func (r *ProjectResolver) saveCache(cache projectCache) {
+	if r.CachePath == "" {
+		return
+	}
+	raw, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(r.CachePath), 0o755); err != nil {
+		return
+	}
+	_ = os.WriteFile(r.CachePath, raw, 0o644)
+}
+
+// sha256Hex returns the hex SHA-256 of s, the scheme Gemini CLI uses to derive
+// projectHash from the project root directory.
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// ShortProject returns the last path component of a project directory, so
+// full working directories display like Claude's project names.
+func ShortProject(project string) string {
+	if base := filepath.Base(project); base != "." && base != string(filepath.Separator) {
+		return base
+	}
+	return project
+}
+
+// projectDimension builds a named count breakdown by project directory.
+// Projects are labelled by their short names, or by parent/name where two
+// share a short name. The empty project is labelled none.
+func projectDimension(name string, counts map[string]int, none string) Dimension {
+	byShort := make(map[string]int)
+	for project := range counts {
+		byShort[ShortProject(project)]++
+	}
+	d := Dimension{Name: name}
+	for project, n := range counts {
+		label := ShortProject(project)
+		switch {
+		case project == "":
+			label = none
+		case byShort[label] > 1:
+			label = filepath.Join(filepath.Base(filepath.Dir(project)), label)
+		}
+		d.Items = append(d.Items, DimensionItem{Label: label, Count: n})
+	}
+	sort.Slice(d.Items, func(i, j int) bool {
+		if d.Items[i].Count != d.Items[j].Count {
+			return d.Items[i].Count > d.Items[j].Count
+		}
+		return d.Items[i].Label < d.Items[j].Label
+	})
+	return d
+}
+
+// shortHashLabel is the fallback project label for an unresolved hash.
+func shortHashLabel(hash string) string {
+	if len(hash) > 8 {
+		hash = hash[:8]
+	}
+	return "#" + hash
+}
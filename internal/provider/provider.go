@@ -1,6 +1,9 @@
 package provider
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // Provider is the interface all AI tool trackers implement.
 type Provider interface {
@@ -21,6 +24,9 @@ type ProviderData struct {
 	Models       []ModelBreakdown
 	Sessions     []SessionInfo
 	Generations  int // Code generations (for tools like Cursor)
+	Prompts      *PromptStats // User prompt analytics, when the tool keeps a prompt history
+	Dimensions   []Dimension  // Extra count breakdowns, e.g. generations by file extension
+	Quota        *QuotaStatus // Request allowance for request-based plans
 	FirstSeen    time.Time
 	LastSeen     time.Time
 	Metadata     map[string]string // Provider-specific info
@@ -34,6 +40,7 @@ type DailyUsage struct {
 	Messages    int
 	Sessions    int
 	Generations int // For code generation tools
+	Prompts     int // User prompts submitted that day
 }
 
 // ModelBreakdown holds per-model stats.
@@ -44,12 +51,28 @@ type ModelBreakdown struct {
 	CacheRead    int
 	CacheWrite   int
 	Cost         float64
-	Generations  int
+	Generations  int  // Code generations, for tools like Cursor that count them
+	Requests     int  // Responses, or billable requests for request-based plans such as Cursor's
+	Estimated    bool // Token counts (and so cost) estimated from message sizes
+}
+
+// Dimension is a named breakdown of counts that isn't per model,
+// such as Cursor code generations by file extension.
+type Dimension struct {
+	Name  string
+	Items []DimensionItem
+}
+
+// DimensionItem is one labeled count within a Dimension.
+type DimensionItem struct {
+	Label string
+	Count int
 }
 
 // SessionInfo holds a single session's data.
 type SessionInfo struct {
 	ID           string
+	Title        string // Human-readable label, e.g. the first prompt
 	Project      string
 	StartTime    time.Time
 	EndTime      time.Time
@@ -58,6 +81,109 @@ type SessionInfo struct {
 	Tokens       int
 	Cost         float64
 	Model        string
+	Prompts      int
+	Turns        []TurnUsage   // Per-response usage, when the source records it
+	Requests     int           // API requests, when the tool logs them
+	Errors       int           // Failed API requests
+	Latency      time.Duration // Total API response time across Requests
+	Estimated    bool          // Tokens and cost estimated because the tool doesn't record them
+}
+
+// TurnUsage holds token usage for a single model response within a session.
+type TurnUsage struct {
+	Timestamp    time.Time // Zero if the tool doesn't record when the response came
+	Model        string
+	InputTokens  int
+	OutputTokens int
+	CacheRead    int
+	CacheWrite   int
+	Cost         float64
+}
+
+// PromptStats summarizes the user prompts recorded in a tool's prompt history.
+type PromptStats struct {
+	Total        int
+	Sessions     int
+	AvgLength    float64
+	MaxLength    int
+	LengthCounts []PromptLengthBucket
+}
+
+// PromptLengthBucket counts prompts whose character length falls in [Min, Max).
+// Max is zero for the open-ended last bucket.
+type PromptLengthBucket struct {
+	Label string
+	Min   int
+	Max   int
+	Count int
+}
+
+// promptLengthBounds defines the prompt length distribution buckets.
+var promptLengthBounds = []PromptLengthBucket{
+	{Label: "<50", Min: 0, Max: 50},
+	{Label: "50-199", Min: 50, Max: 200},
+	{Label: "200-999", Min: 200, Max: 1000},
+	{Label: "1K-5K", Min: 1000, Max: 5000},
+	{Label: "5K+", Min: 5000},
+}
+
+// newPromptStats builds prompt analytics from a list of prompt lengths.
+func newPromptStats(lengths []int, sessions int) *PromptStats {
+	ps := &PromptStats{
+		Total:        len(lengths),
+		Sessions:     sessions,
+		LengthCounts: append([]PromptLengthBucket(nil), promptLengthBounds...),
+	}
+	var sum int
+	for _, n := range lengths {
+		sum += n
+		if n > ps.MaxLength {
+			ps.MaxLength = n
+		}
+		for i := range ps.LengthCounts {
+			b := &ps.LengthCounts[i]
+			if n >= b.Min && (b.Max == 0 || n < b.Max) {
+				b.Count++
+				break
+			}
+		}
+	}
+	if len(lengths) > 0 {
+		ps.AvgLength = float64(sum) / float64(len(lengths))
+	}
+	return ps
+}
+
+// bareModelName strips provider prefixes such as "anthropic/" or
+// "openrouter/openai/" (as used by litellm and llm plugins) so the pricing
+// table can match the model.
+func bareModelName(name string) string {
+	if name == "" {
+		return "unknown"
+	}
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// rootSession follows parent links from id up to the top-level session a
+// sub-agent ran under. parent returns a session's parent, or "" when it has
+// none the tool recorded. The result is "" for a top-level session; ok is
+// false when the links loop back on themselves.
+func rootSession(id string, parent func(string) string) (root string, ok bool) {
+	seen := map[string]bool{id: true}
+	for {
+		p := parent(id)
+		if p == "" {
+			return root, true
+		}
+		if seen[p] {
+			return "", false
+		}
+		seen[p] = true
+		root, id = p, p
+	}
 }
 
 // AggregatedData holds combined data from all providers.
@@ -69,22 +195,40 @@ type AggregatedData struct {
 	TotalSessions int
 	FirstSeen    time.Time
 	LastSeen     time.Time
+	Errors       []LoadError // Available providers whose data failed to load
+}
+
+// LoadError records a provider that was available but failed to load.
+type LoadError struct {
+	Provider string
+	Err      error
 }
 
 // LoadAll loads data from all available providers.
 func LoadAll(providers []Provider) *AggregatedData {
-	agg := &AggregatedData{}
-	dailyMap := make(map[string]DailyUsage)
-
+	var loaded []*ProviderData
+	var errs []LoadError
 	for _, p := range providers {
 		if !p.Available() {
 			continue
 		}
 		data, err := p.Load()
 		if err != nil {
+			errs = append(errs, LoadError{Provider: p.Name(), Err: err})
 			continue
 		}
+		loaded = append(loaded, data)
+	}
+	return Aggregate(loaded, errs)
+}
+
+// Aggregate combines loaded provider data into totals and daily usage
+// merged across providers.
+func Aggregate(providers []*ProviderData, errs []LoadError) *AggregatedData {
+	agg := &AggregatedData{Errors: errs}
+	dailyMap := make(map[string]DailyUsage)
 
+	for _, data := range providers {
 		agg.Providers = append(agg.Providers, data)
 		agg.TotalCost += data.TotalCost
 
@@ -96,6 +240,7 @@ func LoadAll(providers []Provider) *AggregatedData {
 			existing.Messages += d.Messages
 			existing.Sessions += d.Sessions
 			existing.Generations += d.Generations
+			existing.Prompts += d.Prompts
 			dailyMap[d.Date] = existing
 		}
 
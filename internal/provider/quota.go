@@ -0,0 +1,180 @@
+package provider
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RequestQuota is a request-based plan allowance, such as Cursor Pro's 500
+// premium requests per billing cycle.
+type RequestQuota struct {
+	Name          string
+	Limit         int      // Requests included per cycle
+	CycleStartDay int      // Day of month the cycle resets; 1 when unset
+	Models        []string // Model prefixes that count against the quota; empty counts all
+
+	// Multipliers weights requests by model prefix, as Copilot's premium
+	// requests do; the longest matching prefix wins and unmatched models
+	// count as one request. A zero multiplier makes a model free.
+	Multipliers map[string]float64
+	// Exhausted describes what happens once the quota runs out, such as
+	// "slow mode"; "out of requests" when unset.
+	Exhausted string
+}
+
+// QuotaStatus is a request quota's usage within the current cycle.
+type QuotaStatus struct {
+	Name       string
+	Limit      int
+	Used       float64 // Requests used, weighted by any multipliers
+	Exhausted  string
+	CycleStart time.Time
+	ResetAt    time.Time
+	ByModel    []DimensionItem // Counted requests per model this cycle
+}
+
+// requestEvent is a single billable request made at a point in time.
+type requestEvent struct {
+	timestamp time.Time
+	model     string
+}
+
+// counts reports whether a request for model counts against the quota.
+func (q RequestQuota) counts(model string) bool {
+	if len(q.Models) == 0 {
+		return true
+	}
+	for _, prefix := range q.Models {
+		if strings.HasPrefix(model, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// weight returns how many requests a request for model counts as.
+func (q RequestQuota) weight(model string) float64 {
+	w, best := 1.0, -1
+	for prefix, m := range q.Multipliers {
+		if strings.HasPrefix(model, prefix) && len(prefix) > best {
+			w, best = m, len(prefix)
+		}
+	}
+	return w
+}
+
+// status tallies the requests made in the cycle containing now.
+func (q RequestQuota) status(requests []requestEvent, now time.Time) *QuotaStatus {
+	start, reset := quotaCycle(now, q.CycleStartDay)
+	qs := &QuotaStatus{Name: q.Name, Limit: q.Limit, Exhausted: q.Exhausted, CycleStart: start, ResetAt: reset}
+	if qs.Exhausted == "" {
+		qs.Exhausted = "out of requests"
+	}
+
+	byModel := make(map[string]int)
+	for _, r := range requests {
+		if r.timestamp.Before(start) || !r.timestamp.Before(reset) || !q.counts(r.model) {
+			continue
+		}
+		qs.Used += q.weight(r.model)
+		byModel[r.model]++
+	}
+	for m, n := range byModel {
+		qs.ByModel = append(qs.ByModel, DimensionItem{Label: m, Count: n})
+	}
+	sort.Slice(qs.ByModel, func(i, j int) bool {
+		if qs.ByModel[i].Count != qs.ByModel[j].Count {
+			return qs.ByModel[i].Count > qs.ByModel[j].Count
+		}
+		return qs.ByModel[i].Label < qs.ByModel[j].Label
+	})
+	return qs
+}
+
+// quotaCycle returns the start of the billing cycle containing now and the
+// time it resets. Start days past the end of a short month clamp to its last day.
+func quotaCycle(now time.Time, startDay int) (time.Time, time.Time) {
+	if startDay < 1 {
+		startDay = 1
+	}
+	cycleDay := func(year int, month time.Month) time.Time {
+		last := time.Date(year, month+1, 0, 0, 0, 0, 0, now.Location()).Day()
+		day := startDay
+		if day > last {
+			day = last
+		}
+		return time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+	}
+
+	start := cycleDay(now.Year(), now.Month())
+	if now.Before(start) {
+		prev := time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, now.Location())
+		start = cycleDay(prev.Year(), prev.Month())
+	}
+	next := time.Date(start.Year(), start.Month()+1, 1, 0, 0, 0, 0, now.Location())
+	return start, cycleDay(next.Year(), next.Month())
+}
+
+// Remaining returns the requests left this cycle, never below zero.
+func (qs *QuotaStatus) Remaining() float64 {
+	if qs.Used >= float64(qs.Limit) {
+		return 0
+	}
+	return float64(qs.Limit) - qs.Used
+}
+
+// Percent returns the share of the quota used, in percent.
+func (qs *QuotaStatus) Percent() float64 {
+	if qs.Limit <= 0 {
+		return 0
+	}
+	return qs.Used / float64(qs.Limit) * 100
+}
+
+// Usage formats the requests used against the limit, e.g. "212/300" or
+// "36.5/300" when multipliers leave a fraction.
+func (qs *QuotaStatus) Usage() string {
+	if qs.Used == float64(int(qs.Used)) {
+		return fmt.Sprintf("%d/%d", int(qs.Used), qs.Limit)
+	}
+	return fmt.Sprintf("%.1f/%d", qs.Used, qs.Limit)
+}
+
+// DailyAverage returns the requests used per day so far this cycle.
+func (qs *QuotaStatus) DailyAverage(now time.Time) float64 {
+	days := now.Sub(qs.CycleStart).Hours() / 24
+	if days < 1 {
+		days = 1
+	}
+	return qs.Used / days
+}
+
+// DailyBudget returns the requests per day that can still be spent without
+// running out before the cycle resets.
+func (qs *QuotaStatus) DailyBudget(now time.Time) float64 {
+	days := qs.ResetAt.Sub(now).Hours() / 24
+	if days < 1 {
+		days = 1
+	}
+	return qs.Remaining() / days
+}
+
+// ExhaustedAt projects when the quota runs out at the current daily average.
+// It returns false when the quota lasts until the reset at this pace.
+func (qs *QuotaStatus) ExhaustedAt(now time.Time) (time.Time, bool) {
+	if qs.Limit > 0 && qs.Used >= float64(qs.Limit) {
+		return now, true
+	}
+	avg := qs.DailyAverage(now)
+	if avg <= 0 {
+		return time.Time{}, false
+	}
+	days := qs.Remaining() / avg
+	at := now.Add(time.Duration(days * 24 * float64(time.Hour)))
+	if !at.Before(qs.ResetAt) {
+		return time.Time{}, false
+	}
+	return at, true
+}
@@ -0,0 +1,83 @@
+package provider
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuotaCycle(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		now        time.Time
+		startDay   int
+		start, end time.Time
+	}{
+		{day(2026, 2, 10), 0, day(2026, 2, 1), day(2026, 3, 1)},
+		{day(2026, 2, 10), 15, day(2026, 1, 15), day(2026, 2, 15)},
+		{day(2026, 2, 20), 15, day(2026, 2, 15), day(2026, 3, 15)},
+		{day(2026, 3, 5), 31, day(2026, 2, 28), day(2026, 3, 31)},
+		{day(2026, 12, 31), 31, day(2026, 12, 31), day(2027, 1, 31)},
+	}
+	for _, tt := range tests {
+		start, end := quotaCycle(tt.now, tt.startDay)
+		if !start.Equal(tt.start) || !end.Equal(tt.end) {
+			t.Errorf("quotaCycle(%s, %d) = %s..%s, want %s..%s",
+				tt.now.Format("2006-01-02"), tt.startDay,
+				start.Format("2006-01-02"), end.Format("2006-01-02"),
+				tt.start.Format("2006-01-02"), tt.end.Format("2006-01-02"))
+		}
+	}
+}
+
+func TestQuotaStatus(t *testing.T) {
+	now := time.Date(2026, 2, 11, 0, 0, 0, 0, time.UTC)
+	var requests []requestEvent
+	for i := 0; i < 100; i++ {
+		requests = append(requests, requestEvent{timestamp: now.AddDate(0, 0, -1), model: "claude-sonnet-4-5"})
+	}
+	requests = append(requests,
+		requestEvent{timestamp: now.AddDate(0, 0, -1), model: "default"},
+		requestEvent{timestamp: now.AddDate(0, -1, 0), model: "claude-sonnet-4-5"}, // previous cycle
+	)
+
+	q := RequestQuota{Name: "Pro", Limit: 200, CycleStartDay: 1, Models: []string{"claude-", "gpt-"}}
+	qs := q.status(requests, now)
+	if qs.Used != 100 {
+		t.Fatalf("expected 100 counted requests, got %.1f", qs.Used)
+	}
+	if len(qs.ByModel) != 1 || qs.ByModel[0].Label != "claude-sonnet-4-5" {
+		t.Errorf("unexpected per-model counts %+v", qs.ByModel)
+	}
+	if avg := qs.DailyAverage(now); avg != 10 {
+		t.Errorf("expected 10 requests/day, got %.2f", avg)
+	}
+	// 100 left at 10/day runs out on Feb 21, before the Mar 1 reset.
+	at, ok := qs.ExhaustedAt(now)
+	if !ok || at.Format("2006-01-02") != "2026-02-21" {
+		t.Errorf("expected exhaustion on 2026-02-21, got %s (%v)", at.Format("2006-01-02"), ok)
+	}
+}
+
+func TestQuotaMultipliers(t *testing.T) {
+	now := time.Date(2026, 2, 11, 12, 0, 0, 0, time.UTC)
+	requests := []requestEvent{
+		{timestamp: now, model: "gpt-4.1"},
+		{timestamp: now, model: "claude-sonnet-4"},
+		{timestamp: now, model: "claude-opus-4.1"},
+		{timestamp: now, model: "o4-mini"},
+		{timestamp: now, model: "some-new-model"},
+	}
+	q := RequestQuota{Limit: 300, Multipliers: map[string]float64{
+		"gpt-4.1": 0, "claude-sonnet-4": 1, "claude-opus-4": 10, "o4-mini": 0.5,
+	}}
+	qs := q.status(requests, now)
+	if qs.Used != 12.5 {
+		t.Errorf("expected 12.5 weighted requests, got %.2f", qs.Used)
+	}
+	if got := qs.Usage(); got != "12.5/300" {
+		t.Errorf("Usage() = %q, want 12.5/300", got)
+	}
+	if len(qs.ByModel) != 5 {
+		t.Errorf("expected raw counts for all 5 models, got %+v", qs.ByModel)
+	}
+}
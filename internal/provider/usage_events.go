@@ -0,0 +1,222 @@
+package provider
+
+import (
+	"sort"
+	"time"
+
+	"github.com/isaacaudet/aitop/internal/model"
+)
+
+// usageEvent is one model response from a source that logs flat usage
+// records rather than whole sessions.
+type usageEvent struct {
+	Timestamp time.Time
+	Session   string
+	Project   string
+	Model     string
+	Usage     model.TokenUsage
+	Cost      float64
+	HasCost   bool // Cost was recorded; otherwise it's priced by Model
+}
+
+// usageTotals accumulates what every provider's Load builds alongside its
+// sessions: per-model totals, daily usage and the dates usage spans.
+type usageTotals struct {
+	models      map[string]*ModelBreakdown
+	daily       map[string]*DailyUsage
+	first, last time.Time
+}
+
+func newUsageTotals() *usageTotals {
+	return &usageTotals{
+		models: make(map[string]*ModelBreakdown),
+		daily:  make(map[string]*DailyUsage),
+	}
+}
+
+// day returns the usage entry for t's local date, creating it if needed.
+func (u *usageTotals) day(t time.Time) *DailyUsage {
+	return u.date(t.Local().Format("2006-01-02"))
+}
+
+// date returns the usage entry for a YYYY-MM-DD date, creating it if needed.
+func (u *usageTotals) date(key string) *DailyUsage {
+	du, ok := u.daily[key]
+	if !ok {
+		du = &DailyUsage{Date: key}
+		u.daily[key] = du
+	}
+	return du
+}
+
+// model returns the breakdown for name, creating it if needed.
+func (u *usageTotals) model(name string) *ModelBreakdown {
+	mb, ok := u.models[name]
+	if !ok {
+		mb = &ModelBreakdown{Model: name}
+		u.models[name] = mb
+	}
+	return mb
+}
+
+// span extends the date range to cover start through end. Zero times are
+// ignored.
+func (u *usageTotals) span(start, end time.Time) {
+	for _, t := range []time.Time{start, end} {
+		if t.IsZero() {
+			continue
+		}
+		if u.first.IsZero() || t.Before(u.first) {
+			u.first = t
+		}
+		if t.After(u.last) {
+			u.last = t
+		}
+	}
+}
+
+// fill sets data's models, most expensive (then most used) first, its daily
+// usage in date order and the date range.
+func (u *usageTotals) fill(data *ProviderData) {
+	for _, mb := range u.models {
+		data.Models = append(data.Models, *mb)
+	}
+	sort.Slice(data.Models, func(i, j int) bool {
+		a, b := data.Models[i], data.Models[j]
+		if a.Cost != b.Cost {
+			return a.Cost > b.Cost
+		}
+		if a.Requests != b.Requests {
+			return a.Requests > b.Requests
+		}
+		return a.Model < b.Model
+	})
+	for _, du := range u.daily {
+		data.DailyUsage = append(data.DailyUsage, *du)
+	}
+	sort.Slice(data.DailyUsage, func(i, j int) bool {
+		return data.DailyUsage[i].Date < data.DailyUsage[j].Date
+	})
+	if !u.first.IsZero() && (data.FirstSeen.IsZero() || u.first.Before(data.FirstSeen)) {
+		data.FirstSeen = u.first
+	}
+	if u.last.After(data.LastSeen) {
+		data.LastSeen = u.last
+	}
+}
+
+// eventAggregator folds usage events into sessions, per-model totals and
+// daily usage.
+type eventAggregator struct {
+	*usageTotals
+	sessions map[string]*SessionInfo
+	order    []string
+}
+
+func newEventAggregator() *eventAggregator {
+	return &eventAggregator{
+		usageTotals: newUsageTotals(),
+		sessions:    make(map[string]*SessionInfo),
+	}
+}
+
+func (a *eventAggregator) add(ev usageEvent) {
+	if ev.Model == "" {
+		ev.Model = "unknown"
+	}
+	if !ev.HasCost {
+		ev.Cost = model.CalculateCost(ev.Model, ev.Usage)
+	}
+	u := ev.Usage
+	tokens := u.InputTokens + u.OutputTokens + u.CacheRead + u.CacheWrite
+
+	si := a.session(ev.Session, ev.Timestamp)
+	if ev.Project != "" {
+		si.Project = ev.Project
+	}
+	si.Messages++
+	si.Tokens += tokens
+	si.Cost += ev.Cost
+	si.Turns = append(si.Turns, TurnUsage{
+		Timestamp:    ev.Timestamp,
+		Model:        ev.Model,
+		InputTokens:  u.InputTokens,
+		OutputTokens: u.OutputTokens,
+		CacheRead:    u.CacheRead,
+		CacheWrite:   u.CacheWrite,
+		Cost:         ev.Cost,
+	})
+
+	mb := a.model(ev.Model)
+	mb.InputTokens += u.InputTokens
+	mb.OutputTokens += u.OutputTokens
+	mb.CacheRead += u.CacheRead
+	mb.CacheWrite += u.CacheWrite
+	mb.Cost += ev.Cost
+	mb.Requests++
+
+	du := a.day(ev.Timestamp)
+	du.Cost += ev.Cost
+	du.Tokens += tokens
+	du.Messages++
+}
+
+// session returns the session with the given id, creating it if needed, and
+// widens its time range to include t.
+func (a *eventAggregator) session(id string, t time.Time) *SessionInfo {
+	si, ok := a.sessions[id]
+	if !ok {
+		si = &SessionInfo{ID: id, StartTime: t, EndTime: t}
+		a.sessions[id] = si
+		a.order = append(a.order, id)
+		a.day(t).Sessions++
+	}
+	if t.Before(si.StartTime) {
+		si.StartTime = t
+	}
+	if t.After(si.EndTime) {
+		si.EndTime = t
+	}
+	return si
+}
+
+// sessionList returns the sessions in the order they were first seen, with
+// turns sorted and each session labelled with its main model.
+func (a *eventAggregator) sessionList() []SessionInfo {
+	out := make([]SessionInfo, 0, len(a.order))
+	for _, id := range a.order {
+		si := a.sessions[id]
+		sort.SliceStable(si.Turns, func(i, j int) bool {
+			return si.Turns[i].Timestamp.Before(si.Turns[j].Timestamp)
+		})
+		si.Model = dominantTurnModel(si.Turns)
+		out = append(out, *si)
+	}
+	return out
+}
+
+// fill sets data's sessions, models, daily usage, total cost and date range
+// from the aggregated events.
+func (a *eventAggregator) fill(data *ProviderData) {
+	data.Sessions = a.sessionList()
+	for _, si := range data.Sessions {
+		a.span(si.StartTime, si.EndTime)
+	}
+	a.usageTotals.fill(data)
+	for _, mb := range data.Models {
+		data.TotalCost += mb.Cost
+	}
+}
+
+// dominantTurnModel returns the model with the most output tokens.
+func dominantTurnModel(turns []TurnUsage) string {
+	output := make(map[string]int)
+	best := ""
+	for _, t := range turns {
+		output[t.Model] += t.OutputTokens
+		if best == "" || output[t.Model] > output[best] {
+			best = t.Model
+		}
+	}
+	return best
+}
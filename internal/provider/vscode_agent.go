@@ -0,0 +1,349 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/isaacaudet/aitop/internal/model"
+)
+
+// VSCodeAgentFlavor identifies a VS Code agent extension descended from Cline.
+// They all keep one directory per task under globalStorage/<extension id>/tasks.
+type VSCodeAgentFlavor struct {
+	Name        string
+	Icon        string
+	Color       string
+	ExtensionID string
+}
+
+// Built-in Cline-family flavors.
+var (
+	ClineFlavor    = VSCodeAgentFlavor{Name: "Cline", Icon: "◉", Color: "#89b4fa", ExtensionID: "saoudrizwan.claude-dev"}        // Blue
+	RooCodeFlavor  = VSCodeAgentFlavor{Name: "Roo Code", Icon: "◎", Color: "#f38ba8", ExtensionID: "rooveterinaryinc.roo-cline"} // Red
+	KiloCodeFlavor = VSCodeAgentFlavor{Name: "Kilo Code", Icon: "◍", Color: "#f5c2e7", ExtensionID: "kilocode.kilo-code"}        // Pink
+)
+
+// VSCodeAgent implements Provider for Cline and its forks. It reads task
+// histories from the extension's globalStorage in every editor that has it.
+type VSCodeAgent struct {
+	Flavor      VSCodeAgentFlavor
+	StorageDirs []string // globalStorage/<extension id> directories
+}
+
+// NewVSCodeAgent returns a provider for flavor reading from the given editor
+// user data directories (see EditorUserDirs).
+func NewVSCodeAgent(flavor VSCodeAgentFlavor, userDirs []string) *VSCodeAgent {
+	a := &VSCodeAgent{Flavor: flavor}
+	for _, dir := range userDirs {
+		a.StorageDirs = append(a.StorageDirs, filepath.Join(dir, "globalStorage", flavor.ExtensionID))
+	}
+	return a
+}
+
+// EditorUserDirs returns the user data directories of VS Code and the VS Code
+// forks agent extensions are commonly installed in.
+func EditorUserDirs() []string {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return nil
+	}
+	var dirs []string
+	for _, editor := range []string{"Code", "Code - Insiders", "VSCodium", "Cursor", "Windsurf"} {
+		dirs = append(dirs, filepath.Join(base, editor, "User"))
+	}
+	return dirs
+}
+
+func (a *VSCodeAgent) Name() string  { return a.Flavor.Name }
+func (a *VSCodeAgent) Icon() string  { return a.Flavor.Icon }
+func (a *VSCodeAgent) Color() string { return a.Flavor.Color }
+
+func (a *VSCodeAgent) Available() bool {
+	for _, dir := range a.StorageDirs {
+		if _, err := os.Stat(filepath.Join(dir, "tasks")); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// clineMessage is an entry of a task's ui_messages.json.
+type clineMessage struct {
+	Ts   int64  `json:"ts"`
+	Type string `json:"type"` // "say" or "ask"
+	Say  string `json:"say"`
+	Text string `json:"text"`
+}
+
+// clineAPIRequest is the JSON payload of an api_req_started message.
+type clineAPIRequest struct {
+	Request     string   `json:"request"`
+	TokensIn    int      `json:"tokensIn"`
+	TokensOut   int      `json:"tokensOut"`
+	CacheWrites int      `json:"cacheWrites"`
+	CacheReads  int      `json:"cacheReads"`
+	Cost        *float64 `json:"cost"`
+}
+
+// clineHistoryItem is a task entry in state/taskHistory.json or a task's
+// history_item.json. Cline records the working directory as
+// cwdOnTaskInitialization; Roo Code and Kilo Code call it workspace.
+type clineHistoryItem struct {
+	ID        string `json:"id"`
+	CWD       string `json:"cwdOnTaskInitialization"`
+	Workspace string `json:"workspace"`
+}
+
+// clineTaskMetadata is a task's task_metadata.json.
+type clineTaskMetadata struct {
+	ModelUsage []struct {
+		Ts      int64  `json:"ts"`
+		ModelID string `json:"model_id"`
+	} `json:"model_usage"`
+}
+
+// clineCWDRe extracts the working directory from the environment details
+// embedded in each API request.
+var clineCWDRe = regexp.MustCompile(`# Current Working Directory \(([^)]+)\)`)
+
+func (a *VSCodeAgent) Load() (*ProviderData, error) {
+	data := &ProviderData{
+		ProviderName: a.Name(),
+		Icon:         a.Icon(),
+		Color:        a.Color(),
+		Metadata:     make(map[string]string),
+	}
+
+	totals := newUsageTotals()
+
+	seen := make(map[string]bool)
+	found := false
+	var promptLengths []int
+	for _, dir := range a.StorageDirs {
+		entries, err := os.ReadDir(filepath.Join(dir, "tasks"))
+		if err != nil {
+			continue
+		}
+		found = true
+		workspaces := loadClineTaskHistory(filepath.Join(dir, "state", "taskHistory.json"))
+
+		for _, e := range entries {
+			if !e.IsDir() || seen[e.Name()] {
+				continue
+			}
+			taskDir := filepath.Join(dir, "tasks", e.Name())
+			si, prompts, ok := a.parseTask(taskDir, e.Name(), workspaces[e.Name()])
+			if !ok {
+				continue
+			}
+			seen[e.Name()] = true
+
+			for _, t := range si.Turns {
+				mb := totals.model(t.Model)
+				mb.InputTokens += t.InputTokens
+				mb.OutputTokens += t.OutputTokens
+				mb.CacheRead += t.CacheRead
+				mb.CacheWrite += t.CacheWrite
+				mb.Cost += t.Cost
+				mb.Requests++
+
+				du := totals.day(t.Timestamp)
+				du.Cost += t.Cost
+				du.Tokens += t.InputTokens + t.OutputTokens + t.CacheRead + t.CacheWrite
+			}
+			du := totals.day(si.StartTime)
+			du.Sessions++
+			du.Messages += si.Messages
+			du.Prompts += len(prompts)
+			for _, p := range prompts {
+				promptLengths = append(promptLengths, utf8.RuneCountInString(p))
+			}
+
+			data.TotalCost += si.Cost
+			data.Sessions = append(data.Sessions, si)
+			totals.span(si.StartTime, si.EndTime)
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("no %s task directories found", a.Name())
+	}
+	if len(promptLengths) > 0 {
+		data.Prompts = newPromptStats(promptLengths, len(data.Sessions))
+	}
+
+	totals.fill(data)
+
+	return data, nil
+}
+
+// parseTask turns a task directory into a session with one turn per API
+// request, returning the user's prompts alongside it.
+func (a *VSCodeAgent) parseTask(taskDir, id, workspace string) (SessionInfo, []string, bool) {
+	raw, err := os.ReadFile(filepath.Join(taskDir, "ui_messages.json"))
+	if err != nil {
+		return SessionInfo{}, nil, false
+	}
+	var messages []clineMessage
+	if json.Unmarshal(raw, &messages) != nil || len(messages) == 0 {
+		return SessionInfo{}, nil, false
+	}
+
+	if workspace == "" {
+		workspace = clineHistoryWorkspace(filepath.Join(taskDir, "history_item.json"))
+	}
+	models := loadClineModelUsage(filepath.Join(taskDir, "task_metadata.json"))
+
+	si := SessionInfo{
+		ID:        id,
+		Project:   workspace,
+		StartTime: time.UnixMilli(messages[0].Ts),
+		EndTime:   time.UnixMilli(messages[len(messages)-1].Ts),
+		Messages:  len(messages),
+	}
+	var prompts []string
+	for _, msg := range messages {
+		if msg.Type != "say" {
+			continue
+		}
+		switch msg.Say {
+		case "task", "user_feedback":
+			si.UserMessages++
+			prompts = append(prompts, msg.Text)
+			if si.Title == "" {
+				si.Title = promptTitle(msg.Text)
+			}
+		case "api_req_started":
+			var req clineAPIRequest
+			if json.Unmarshal([]byte(msg.Text), &req) != nil {
+				continue
+			}
+			if si.Project == "" {
+				if m := clineCWDRe.FindStringSubmatch(req.Request); m != nil {
+					si.Project = strings.TrimSpace(m[1])
+				}
+			}
+
+			m := models.at(msg.Ts)
+			usage := model.TokenUsage{
+				InputTokens:  req.TokensIn,
+				OutputTokens: req.TokensOut,
+				CacheRead:    req.CacheReads,
+				CacheWrite:   req.CacheWrites,
+			}
+			var cost float64
+			if req.Cost != nil {
+				cost = *req.Cost
+			} else {
+				cost = model.CalculateCost(m, usage)
+			}
+
+			si.Model = m
+			si.Tokens += req.TokensIn + req.TokensOut + req.CacheReads + req.CacheWrites
+			si.Cost += cost
+			si.Turns = append(si.Turns, TurnUsage{
+				Timestamp:    time.UnixMilli(msg.Ts),
+				Model:        m,
+				InputTokens:  req.TokensIn,
+				OutputTokens: req.TokensOut,
+				CacheRead:    req.CacheReads,
+				CacheWrite:   req.CacheWrites,
+				Cost:         cost,
+			})
+		}
+	}
+	si.Prompts = len(prompts)
+	return si, prompts, true
+}
+
+// clineModelSwitch records that a task started using model at ts.
+type clineModelSwitch struct {
+	ts    int64
+	model string
+}
+
+// clineModelUsage is the model switch history of a task, ordered by time.
+type clineModelUsage []clineModelSwitch
+
+// at returns the model in use at ts ("unknown" when none is recorded).
+func (u clineModelUsage) at(ts int64) string {
+	if len(u) == 0 {
+		return "unknown"
+	}
+	m := u[0].model
+	for _, e := range u {
+		if e.ts > ts {
+			break
+		}
+		m = e.model
+	}
+	return m
+}
+
+// loadClineModelUsage reads the models a task used from task_metadata.json,
+// which Cline writes but its forks may not.
+func loadClineModelUsage(path string) clineModelUsage {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var meta clineTaskMetadata
+	if json.Unmarshal(raw, &meta) != nil {
+		return nil
+	}
+	var usage clineModelUsage
+	for _, mu := range meta.ModelUsage {
+		if mu.ModelID == "" {
+			continue
+		}
+		usage = append(usage, clineModelSwitch{ts: mu.Ts, model: mu.ModelID})
+	}
+	sort.SliceStable(usage, func(i, j int) bool { return usage[i].ts < usage[j].ts })
+	return usage
+}
+
+// loadClineTaskHistory maps task ids to their working directories from the
+// extension's state/taskHistory.json.
+func loadClineTaskHistory(path string) map[string]string {
+	out := make(map[string]string)
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return out
+	}
+	var items []clineHistoryItem
+	if json.Unmarshal(raw, &items) != nil {
+		return out
+	}
+	for _, item := range items {
+		if ws := item.workspace(); ws != "" {
+			out[item.ID] = ws
+		}
+	}
+	return out
+}
+
+// clineHistoryWorkspace reads the working directory from a task's history_item.json.
+func clineHistoryWorkspace(path string) string {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	var item clineHistoryItem
+	if json.Unmarshal(raw, &item) != nil {
+		return ""
+	}
+	return item.workspace()
+}
+
+func (h clineHistoryItem) workspace() string {
+	if h.CWD != "" {
+		return h.CWD
+	}
+	return h.Workspace
+}
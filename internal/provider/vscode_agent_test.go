@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"math"
+	"testing"
+)
+
+const testEditorDir = "../../testdata/vscode/User"
+
+func TestClineTasks(t *testing.T) {
+	a := NewVSCodeAgent(ClineFlavor, []string{testEditorDir})
+	if !a.Available() {
+		t.Fatal("expected Cline to be available")
+	}
+	data, err := a.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Sessions) != 1 {
+		t.Fatalf("expected 1 session, got %d", len(data.Sessions))
+	}
+	s := data.Sessions[0]
+	if s.Project != "/Users/dev/api" || s.Title != "Add pagination to the users endpoint" {
+		t.Errorf("unexpected project/title %q / %q", s.Project, s.Title)
+	}
+	if s.UserMessages != 2 || len(s.Turns) != 2 {
+		t.Errorf("expected 2 prompts and 2 requests, got %d and %d", s.UserMessages, len(s.Turns))
+	}
+	// Per-request costs are taken as embedded.
+	if math.Abs(s.Cost-0.0615) > 1e-9 {
+		t.Errorf("expected embedded cost $0.0615, got $%.4f", s.Cost)
+	}
+	// The model switch recorded in task_metadata.json applies to later requests.
+	if s.Turns[0].Model != "claude-sonnet-4-5-20250929" || s.Turns[1].Model != "claude-opus-4-1-20250805" {
+		t.Errorf("unexpected turn models %q, %q", s.Turns[0].Model, s.Turns[1].Model)
+	}
+	if s.Turns[1].CacheRead != 12000 {
+		t.Errorf("expected 12000 cache reads, got %d", s.Turns[1].CacheRead)
+	}
+}
+
+func TestVSCodeAgentForks(t *testing.T) {
+	roo, err := NewVSCodeAgent(RooCodeFlavor, []string{testEditorDir}).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(roo.Sessions) != 1 || roo.Sessions[0].Project != "/Users/dev/web" {
+		t.Errorf("expected Roo task in /Users/dev/web, got %+v", roo.Sessions)
+	}
+
+	kilo, err := NewVSCodeAgent(KiloCodeFlavor, []string{testEditorDir}).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kilo.Sessions) != 1 {
+		t.Fatalf("expected 1 Kilo session, got %d", len(kilo.Sessions))
+	}
+	// Without a history item the project comes from the environment details.
+	if kilo.Sessions[0].Project != "/Users/dev/docs" {
+		t.Errorf("expected project from environment details, got %q", kilo.Sessions[0].Project)
+	}
+	if kilo.Sessions[0].Model != "unknown" {
+		t.Errorf("expected unknown model, got %q", kilo.Sessions[0].Model)
+	}
+}
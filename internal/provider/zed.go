@@ -0,0 +1,305 @@
+package provider
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/isaacaudet/aitop/internal/model"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Zed implements Provider for the Zed editor's agent panel. Threads live in
+// threads/threads.db under Zed's data dir, one row per thread holding its
+// JSON, usually zstd-compressed.
+type Zed struct {
+	DataDirs []string
+}
+
+func NewZed(dataDirs []string) *Zed {
+	return &Zed{DataDirs: dataDirs}
+}
+
+func (z *Zed) Name() string  { return "Zed" }
+func (z *Zed) Icon() string  { return "ℤ" }
+func (z *Zed) Color() string { return "#bac2de" } // Subtext1
+
+func (z *Zed) Available() bool {
+	for _, dir := range z.DataDirs {
+		if _, err := os.Stat(zedThreadsDB(dir)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func zedThreadsDB(dir string) string {
+	return filepath.Join(dir, "threads", "threads.db")
+}
+
+// zedThread is a thread's JSON. Threads from the original assistant panel
+// (format 0.1/0.2) have a summary and role-tagged messages; agent threads
+// (0.3) have a title and User/Agent messages.
+type zedThread struct {
+	Title                  string            `json:"title"`
+	Summary                string            `json:"summary"`
+	UpdatedAt              time.Time         `json:"updated_at"`
+	Messages               []json.RawMessage `json:"messages"`
+	InitialProjectSnapshot *struct {
+		WorktreeSnapshots []struct {
+			WorktreePath string `json:"worktree_path"`
+		} `json:"worktree_snapshots"`
+		Timestamp time.Time `json:"timestamp"`
+	} `json:"initial_project_snapshot"`
+	CumulativeTokenUsage struct {
+		InputTokens              int `json:"input_tokens"`
+		OutputTokens             int `json:"output_tokens"`
+		CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+		CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+	} `json:"cumulative_token_usage"`
+	Model *struct {
+		Provider string `json:"provider"`
+		Model    string `json:"model"`
+	} `json:"model"`
+}
+
+// zedMessage covers both message formats: {"role", "segments"} and
+// {"User": {"content"}} / {"Agent": {...}}.
+type zedMessage struct {
+	Role     string `json:"role"`
+	Segments []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"segments"`
+	User *struct {
+		Content []struct {
+			Text *string `json:"Text"`
+		} `json:"content"`
+	} `json:"User"`
+	Agent json.RawMessage `json:"Agent"`
+}
+
+// zedRow is a row of the threads table.
+type zedRow struct {
+	id        string
+	summary   string
+	updatedAt string
+	dataType  string
+	data      []byte
+}
+
+func (z *Zed) Load() (*ProviderData, error) {
+	data := &ProviderData{
+		ProviderName: z.Name(),
+		Icon:         z.Icon(),
+		Color:        z.Color(),
+		Metadata:     make(map[string]string),
+	}
+
+	var rows []zedRow
+	var lastErr error
+	loaded := 0
+	for _, dir := range z.DataDirs {
+		path := zedThreadsDB(dir)
+		if _, err := os.Stat(path); err != nil {
+			lastErr = err
+			continue
+		}
+		rs, err := loadZedThreads(path)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		loaded++
+		rows = append(rows, rs...)
+	}
+	if loaded == 0 {
+		if lastErr == nil {
+			lastErr = fmt.Errorf("no zed threads database found")
+		}
+		return nil, lastErr
+	}
+
+	totals := newUsageTotals()
+
+	seen := make(map[string]bool)
+	var promptLengths []int
+	for _, row := range rows {
+		if seen[row.id] {
+			continue
+		}
+		thread, err := decodeZedThread(row)
+		if err != nil {
+			continue
+		}
+		seen[row.id] = true
+
+		si, prompts := zedSession(row, thread)
+		usage := thread.CumulativeTokenUsage
+		tokenUsage := model.TokenUsage{
+			InputTokens:  usage.InputTokens,
+			OutputTokens: usage.OutputTokens,
+			CacheRead:    usage.CacheReadInputTokens,
+			CacheWrite:   usage.CacheCreationInputTokens,
+		}
+		si.Tokens = usage.InputTokens + usage.OutputTokens + usage.CacheReadInputTokens + usage.CacheCreationInputTokens
+		si.Cost = model.CalculateCost(si.Model, tokenUsage)
+
+		mb := totals.model(si.Model)
+		mb.InputTokens += usage.InputTokens
+		mb.OutputTokens += usage.OutputTokens
+		mb.CacheRead += usage.CacheReadInputTokens
+		mb.CacheWrite += usage.CacheCreationInputTokens
+		mb.Cost += si.Cost
+		mb.Requests++
+
+		// Zed keeps usage per thread, so it lands on the day the thread
+		// was last updated.
+		du := totals.day(si.EndTime)
+		du.Cost += si.Cost
+		du.Tokens += si.Tokens
+		du = totals.day(si.StartTime)
+		du.Sessions++
+		du.Messages += si.Messages
+		du.Prompts += len(prompts)
+		for _, p := range prompts {
+			promptLengths = append(promptLengths, utf8.RuneCountInString(p))
+		}
+
+		data.TotalCost += si.Cost
+		data.Sessions = append(data.Sessions, si)
+		totals.span(si.StartTime, si.EndTime)
+	}
+	if len(promptLengths) > 0 {
+		data.Prompts = newPromptStats(promptLengths, len(data.Sessions))
+	}
+
+	totals.fill(data)
+
+	return data, nil
+}
+
+// loadZedThreads reads every row of the threads table.
+func loadZedThreads(path string) ([]zedRow, error) {
+	db, err := sql.Open("sqlite3", path+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("opening zed threads db: %w", err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`SELECT id, COALESCE(summary, ''), COALESCE(updated_at, ''), COALESCE(data_type, ''), data FROM threads`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []zedRow
+	for rows.Next() {
+		var r zedRow
+		if rows.Scan(&r.id, &r.summary, &r.updatedAt, &r.dataType, &r.data) == nil {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+// zedDecoder decompresses thread blobs. The memory limit bounds what a corrupt
+// blob can expand to; real threads are nowhere near it.
+var zedDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderMaxMemory(64<<20))
+
+// decodeZedThread decompresses and parses a thread row.
+func decodeZedThread(row zedRow) (*zedThread, error) {
+	raw := row.data
+	switch row.dataType {
+	case "zstd":
+		var err error
+		if raw, err = zedDecoder.DecodeAll(raw, nil); err != nil {
+			return nil, fmt.Errorf("decompressing zed thread %s: %w", row.id, err)
+		}
+	case "json", "":
+	default:
+		return nil, fmt.Errorf("unknown zed thread data type %q", row.dataType)
+	}
+	var t zedThread
+	if err := json.Unmarshal(raw, &t); err != nil {
+		return nil, fmt.Errorf("parsing zed thread %s: %w", row.id, err)
+	}
+	return &t, nil
+}
+
+// zedSession builds a session from a thread, returning the user's prompts.
+func zedSession(row zedRow, t *zedThread) (SessionInfo, []string) {
+	si := SessionInfo{ID: row.id, Model: "unknown"}
+	if t.Model != nil && t.Model.Model != "" {
+		si.Model = bareModelName(t.Model.Model)
+	}
+
+	si.EndTime = t.UpdatedAt
+	if si.EndTime.IsZero() {
+		si.EndTime, _ = time.Parse(time.RFC3339Nano, row.updatedAt)
+	}
+	si.StartTime = si.EndTime
+	if snap := t.InitialProjectSnapshot; snap != nil {
+		if len(snap.WorktreeSnapshots) > 0 {
+			si.Project = snap.WorktreeSnapshots[0].WorktreePath
+		}
+		if !snap.Timestamp.IsZero() && snap.Timestamp.Before(si.StartTime) {
+			si.StartTime = snap.Timestamp
+		}
+	}
+
+	var prompts []string
+	for _, raw := range t.Messages {
+		var msg zedMessage
+		if json.Unmarshal(raw, &msg) != nil {
+			continue // e.g. the "Resume" marker
+		}
+		var text []string
+		switch {
+		case msg.Role != "":
+			si.Messages++
+			if msg.Role != "user" {
+				continue
+			}
+			for _, seg := range msg.Segments {
+				if seg.Type == "text" {
+					text = append(text, seg.Text)
+				}
+			}
+		case msg.User != nil:
+			si.Messages++
+			for _, c := range msg.User.Content {
+				if c.Text != nil {
+					text = append(text, *c.Text)
+				}
+			}
+		case msg.Agent != nil:
+			si.Messages++
+			continue
+		default:
+			continue
+		}
+		if prompt := strings.TrimSpace(strings.Join(text, "\n")); prompt != "" {
+			si.UserMessages++
+			prompts = append(prompts, prompt)
+		}
+	}
+	si.Prompts = len(prompts)
+
+	si.Title = t.Title
+	if si.Title == "" {
+		si.Title = t.Summary
+	}
+	if si.Title == "" {
+		si.Title = row.summary
+	}
+	if si.Title == "" && len(prompts) > 0 {
+		si.Title = promptTitle(prompts[0])
+	}
+	return si, prompts
+}
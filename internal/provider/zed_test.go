@@ -0,0 +1,89 @@
+package provider
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestZedThreads(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "threads"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite3", zedThreadsDB(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`CREATE TABLE threads (id TEXT PRIMARY KEY, summary TEXT NOT NULL, updated_at TEXT NOT NULL, data_type TEXT NOT NULL, data BLOB NOT NULL)`); err != nil {
+		t.Fatal(err)
+	}
+	// An assistant panel thread stored as JSON and an agent thread stored
+	// zstd-compressed, the way current Zed versions write them.
+	for _, row := range []struct{ id, summary, updated, dataType, file string }{
+		{"legacy", "Fix flaky websocket test", "2026-03-02T10:15:00Z", "json", "legacy-thread.json"},
+		{"agent", "Add pagination to the orders API", "2026-03-03T16:20:00Z", "zstd", "agent-thread.json.zst"},
+	} {
+		blob, err := os.ReadFile(filepath.Join("../../testdata/zed", row.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.Exec(`INSERT INTO threads VALUES (?, ?, ?, ?, ?)`, row.id, row.summary, row.updated, row.dataType, blob); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+
+	z := NewZed([]string{dir})
+	if !z.Available() {
+		t.Fatal("expected zed to be available")
+	}
+	data, err := z.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(data.Sessions))
+	}
+
+	sessions := make(map[string]SessionInfo)
+	for _, s := range data.Sessions {
+		sessions[s.ID] = s
+	}
+	legacy := sessions["legacy"]
+	if legacy.Title != "Fix flaky websocket test" || legacy.Project != "/home/dev/src/chat-server" {
+		t.Errorf("unexpected legacy session %q %q", legacy.Title, legacy.Project)
+	}
+	if legacy.Model != "claude-sonnet-4-latest" || legacy.Tokens != 47800 || legacy.Cost <= 0 {
+		t.Errorf("unexpected legacy usage %s %d %f", legacy.Model, legacy.Tokens, legacy.Cost)
+	}
+	if legacy.Messages != 4 || legacy.UserMessages != 2 {
+		t.Errorf("expected 4 messages with 2 prompts, got %d/%d", legacy.Messages, legacy.UserMessages)
+	}
+	if !legacy.StartTime.Equal(time.Date(2026, 3, 2, 9, 40, 0, 0, time.UTC)) {
+		t.Errorf("expected the project snapshot time, got %s", legacy.StartTime)
+	}
+
+	agent := sessions["agent"]
+	if agent.Title != "Add pagination to the orders API" || agent.Project != "/home/dev/src/shop" {
+		t.Errorf("unexpected agent session %q %q", agent.Title, agent.Project)
+	}
+	if agent.Model != "claude-opus-4-1" || agent.Tokens != 66500 {
+		t.Errorf("unexpected agent usage %s %d", agent.Model, agent.Tokens)
+	}
+	if agent.Messages != 4 || agent.UserMessages != 2 {
+		t.Errorf("expected 4 messages with 2 prompts, got %d/%d", agent.Messages, agent.UserMessages)
+	}
+
+	if len(data.Models) != 2 || data.Models[0].Model != "claude-opus-4-1" {
+		t.Fatalf("expected opus to cost the most, got %+v", data.Models)
+	}
+	if data.Models[0].CacheRead != 50000 || data.Models[0].CacheWrite != 6000 {
+		t.Errorf("unexpected cache tokens %+v", data.Models[0])
+	}
+	if data.Prompts == nil || data.Prompts.Total != 4 {
+		t.Errorf("expected 4 prompts, got %+v", data.Prompts)
+	}
+}
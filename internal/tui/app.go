@@ -10,6 +10,8 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/isaacaudet/aitop/internal/config"
+	"github.com/isaacaudet/aitop/internal/invoice"
+	"github.com/isaacaudet/aitop/internal/ledger"
 	"github.com/isaacaudet/aitop/internal/model"
 	"github.com/isaacaudet/aitop/internal/provider"
 )
@@ -22,9 +24,10 @@ const (
 	viewProviders
 	viewHeatmap
 	viewLive
+	viewReconcile
 )
 
-var viewNames = []string{"Dashboard", "Sessions", "Providers", "Heatmap", "Live"}
+var viewNames = []string{"Dashboard", "Sessions", "Providers", "Heatmap", "Live", "Reconcile"}
 
 // sortMode defines session sort modes.
 type sortMode int
@@ -53,6 +56,7 @@ var timePeriodNames = []string{"All Time", "This Month", "This Week", "Today"}
 // Model is the main Bubble Tea model.
 type Model struct {
 	cache     *model.StatsCache
+	statsDiff *model.StatsDelta // Nil until the stats cache has two snapshots
 	aggData   *provider.AggregatedData
 	providers []provider.Provider
 	cfg       config.Config
@@ -68,20 +72,27 @@ type Model struct {
 
 	// Live view state
 	liveView   liveView
+
+	reconcile *invoice.Report // Nil until invoices are imported
+	ledgerErr error           // Why the last load couldn't use the ledger
 }
 
 type dataLoadedMsg struct {
-	aggData *provider.AggregatedData
+	aggData   *provider.AggregatedData
+	reconcile *invoice.Report
+	ledgerErr error
 }
 
 type tickMsg time.Time
 
-// New creates a new TUI model.
-func New(cache *model.StatsCache, providers []provider.Provider) Model {
+// New creates a new TUI model. statsDiff is what changed in the stats cache
+// since its previous snapshot, if known.
+func New(cache *model.StatsCache, statsDiff *model.StatsDelta, providers []provider.Provider) Model {
 	cfg := config.Load()
 	vp := viewport.New(80, 40)
 	return Model{
 		cache:     cache,
+		statsDiff: statsDiff,
 		providers: providers,
 		cfg:       cfg,
 		view:      viewDashboard,
@@ -91,10 +102,19 @@ func New(cache *model.StatsCache, providers []provider.Provider) Model {
 	}
 }
 
-func loadDataCmd(providers []provider.Provider) tea.Cmd {
+// loadDataCmd loads every provider and merges in the ledger's history. It
+// records what it loaded in the ledger only if record is set, as on start
+// and manual refresh; the live view's reloads just read it.
+func loadDataCmd(providers []provider.Provider, cfg config.Config, record bool) tea.Cmd {
 	return func() tea.Msg {
-		agg := provider.LoadAll(providers)
-		return dataLoadedMsg{aggData: agg}
+		agg, ledgerErr := ledger.LoadAll(cfg.LedgerPath(), providers, record)
+		msg := dataLoadedMsg{aggData: agg, ledgerErr: ledgerErr}
+		invoiced, err := (&invoice.Store{Dir: cfg.InvoiceDir()}).Load()
+		if err == nil && len(invoiced) > 0 {
+			r := invoice.Reconcile(invoiced, invoice.Estimates(agg.Providers, cfg.Reconcile.Providers))
+			msg.reconcile = &r
+		}
+		return msg
 	}
 }
 
@@ -105,7 +125,7 @@ func tickCmd() tea.Cmd {
 }
 
 func (m Model) Init() tea.Cmd {
-	return tea.Batch(loadDataCmd(m.providers), tickCmd())
+	return tea.Batch(loadDataCmd(m.providers, m.cfg, true), tickCmd())
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -124,6 +144,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case dataLoadedMsg:
 		m.aggData = msg.aggData
+		m.reconcile = msg.reconcile
+		m.ledgerErr = msg.ledgerErr
 		// Build session view from all provider sessions.
 		var sessions []provider.SessionInfo
 		if m.aggData != nil {
@@ -138,7 +160,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case tickMsg:
 		if m.view == viewLive {
-			return m, tea.Batch(loadDataCmd(m.providers), tickCmd())
+			return m, tea.Batch(loadDataCmd(m.providers, m.cfg, false), tickCmd())
 		}
 		return m, tickCmd()
 
@@ -168,6 +190,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case key.Matches(msg, keys.View5):
 			m.view = viewLive
 			m.viewport.GotoTop()
+		case key.Matches(msg, keys.View6):
+			m.view = viewReconcile
+			m.viewport.GotoTop()
 		case key.Matches(msg, keys.Escape):
 			if m.showHelp {
 				m.showHelp = false
@@ -178,7 +203,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 		case key.Matches(msg, keys.Refresh):
-			return m, loadDataCmd(m.providers)
+			return m, loadDataCmd(m.providers, m.cfg, true)
 		case key.Matches(msg, keys.Sort):
 			if m.view == viewSessions {
 				m.sortMode = (m.sortMode + 1) % sortMode(len(sortModeNames))
@@ -358,7 +383,7 @@ func (m Model) View() string {
 	var content string
 	switch m.view {
 	case viewDashboard:
-		content = renderDashboard(m.cache, m.aggData, contentWidth, m.cfg)
+		content = renderDashboard(m.cache, m.statsDiff, m.aggData, contentWidth, m.cfg)
 	case viewSessions:
 		content = m.sessView.render(contentWidth)
 	case viewProviders:
@@ -367,6 +392,8 @@ func (m Model) View() string {
 		content = renderHeatmap(m.aggData, contentWidth)
 	case viewLive:
 		content = m.liveView.render(m.aggData, contentWidth)
+	case viewReconcile:
+		content = renderReconcile(m.reconcile, contentWidth)
 	}
 
 	if m.view == viewSessions {
@@ -384,8 +411,11 @@ func (m Model) View() string {
 	}
 
 	sb.WriteString("\n")
-	footer := StyleHelp.Render(" tab: views | 1-5: jump | j/k: scroll | ctrl+u/d: half-page | s: sort | t: period | ?: help | q: quit")
+	footer := StyleHelp.Render(" tab: views | 1-6: jump | j/k: scroll | ctrl+u/d: half-page | s: sort | t: period | ?: help | q: quit")
 	sb.WriteString(footer)
+	if m.ledgerErr != nil {
+		sb.WriteString(StyleWarning.Render("  ledger unavailable: " + m.ledgerErr.Error()))
+	}
 
 	return sb.String()
 }
@@ -106,8 +106,8 @@ func (t Table) Render() string {
 }
 
 func padCell(s string, width int, align int) string {
-	if len(s) > width {
-		s = s[:width]
+	if r := []rune(s); len(r) > width {
+		s = string(r[:width])
 	}
 	if align == 1 { // right-align
 		return fmt.Sprintf("%*s", width, s)
@@ -12,7 +12,7 @@ import (
 	"github.com/isaacaudet/aitop/internal/tui/components"
 )
 
-func renderDashboard(cache *model.StatsCache, aggData *provider.AggregatedData, width int, cfg config.Config) string {
+func renderDashboard(cache *model.StatsCache, statsDiff *model.StatsDelta, aggData *provider.AggregatedData, width int, cfg config.Config) string {
 	if cache == nil {
 		return StyleError.Render("No data loaded. Check ~/.claude/stats-cache.json")
 	}
@@ -27,7 +27,7 @@ func renderDashboard(cache *model.StatsCache, aggData *provider.AggregatedData,
 		boxWidth = 20
 	}
 
-	days := model.AggregateDaily(cache)
+	days := claudeDays(cache, aggData)
 
 	// Today sparkline (hourly activity).
 	var todaySparkVals []float64
@@ -158,12 +158,17 @@ func renderDashboard(cache *model.StatsCache, aggData *provider.AggregatedData,
 
 	// Model breakdown with aligned horizontal bars.
 	sb.WriteString(StyleSectionTitle.Render("Model Cost Breakdown"))
+	if statsDiff != nil {
+		sb.WriteString(StyleMuted.Render(fmt.Sprintf("  +$%.2f, %d sessions since the %s snapshot",
+			statsDiff.Cost(), statsDiff.Sessions, statsDiff.From)))
+	}
 	sb.WriteString("\n")
 
 	type modelEntry struct {
 		name  string
 		total int
 		cost  float64
+		grew  float64 // Cost added since the previous stats snapshot
 	}
 	var models []modelEntry
 	var maxCost float64
@@ -172,7 +177,11 @@ func renderDashboard(cache *model.StatsCache, aggData *provider.AggregatedData,
 		total := mu.InputTokens + mu.OutputTokens + mu.CacheReadInputTokens + mu.CacheCreationInputTokens
 		cost := model.CalculateCostFromModelUsage(name, mu)
 		displayName := model.NormalizeModelName(name)
-		models = append(models, modelEntry{name: displayName, total: total, cost: cost})
+		var grew float64
+		if statsDiff != nil {
+			grew = model.CalculateCostFromModelUsage(name, statsDiff.Models[name])
+		}
+		models = append(models, modelEntry{name: displayName, total: total, cost: cost, grew: grew})
 		if cost > maxCost {
 			maxCost = cost
 		}
@@ -194,6 +203,9 @@ func renderDashboard(cache *model.StatsCache, aggData *provider.AggregatedData,
 		bar := HorizontalBarAligned(m.name, m.cost, maxCost, barWidth, maxLabelLen, color)
 		sb.WriteString(bar)
 		sb.WriteString(StyleStatCost.Render(fmt.Sprintf("  $%.2f", m.cost)))
+		if m.grew >= 0.01 {
+			sb.WriteString(StyleMuted.Render(fmt.Sprintf("  +$%.2f", m.grew)))
+		}
 		sb.WriteString("\n")
 	}
 	sb.WriteString("\n")
@@ -230,3 +242,40 @@ func renderDashboard(cache *model.StatsCache, aggData *provider.AggregatedData,
 
 	return sb.String()
 }
+
+// claudeDays is Claude Code's daily usage from the stats cache, with the
+// days the ledger recorded more usage for, or that a rebuilt cache no longer
+// has, taken from the ledger-merged Claude Code provider.
+func claudeDays(cache *model.StatsCache, aggData *provider.AggregatedData) []model.DailyStats {
+	days := model.AggregateDaily(cache)
+	if aggData == nil {
+		return days
+	}
+	index := make(map[string]int, len(days))
+	for i, d := range days {
+		index[d.Date] = i
+	}
+	name := (&provider.Claude{}).Name()
+	for _, p := range aggData.Providers {
+		if p.ProviderName != name {
+			continue
+		}
+		for _, d := range p.DailyUsage {
+			recorded := model.DailyStats{
+				Date:        d.Date,
+				Messages:    d.Messages,
+				Sessions:    d.Sessions,
+				TotalTokens: d.Tokens,
+				Cost:        d.Cost,
+			}
+			if i, ok := index[d.Date]; !ok {
+				index[d.Date] = len(days)
+				days = append(days, recorded)
+			} else if d.Tokens > days[i].TotalTokens || d.Messages > days[i].Messages {
+				days[i] = recorded
+			}
+		}
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Date < days[j].Date })
+	return days
+}
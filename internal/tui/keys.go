@@ -10,6 +10,7 @@ type keyMap struct {
 	View3      key.Binding
 	View4      key.Binding
 	View5      key.Binding
+	View6      key.Binding
 	Up         key.Binding
 	Down       key.Binding
 	Enter      key.Binding
@@ -53,6 +54,10 @@ var keys = keyMap{
 		key.WithKeys("5"),
 		key.WithHelp("5", "live"),
 	),
+	View6: key.NewBinding(
+		key.WithKeys("6"),
+		key.WithHelp("6", "reconcile"),
+	),
 	Up: key.NewBinding(
 		key.WithKeys("k", "up"),
 		key.WithHelp("k/↑", "up"),
@@ -107,7 +112,7 @@ func helpView() string {
 	help := StyleSectionTitle.Render("Keyboard Shortcuts") + "\n\n"
 	bindings := []struct{ key, desc string }{
 		{"tab", "Cycle views"},
-		{"1-5", "Jump to view"},
+		{"1-6", "Jump to view"},
 		{"j/k", "Scroll up/down"},
 		{"ctrl+u/d", "Half page up/down"},
 		{"enter", "Expand selection"},
@@ -13,7 +13,13 @@ import (
 
 func renderProvidersView(aggData *provider.AggregatedData, width int, period timePeriod) string {
 	if aggData == nil || len(aggData.Providers) == 0 {
-		return StyleError.Render("No providers loaded.")
+		msg := "No providers loaded."
+		if aggData != nil {
+			for _, e := range aggData.Errors {
+				msg += fmt.Sprintf("\n  %s failed to load: %v", e.Provider, e.Err)
+			}
+		}
+		return StyleError.Render(msg)
 	}
 
 	var sb strings.Builder
@@ -59,6 +65,10 @@ func renderProvidersView(aggData *provider.AggregatedData, width int, period tim
 		sb.WriteString(provStyle.Render(fmt.Sprintf("%s %s", p.Icon, p.ProviderName)))
 		sb.WriteString("\n")
 
+		if p.Quota != nil {
+			sb.WriteString(renderQuotaGauge(p.Quota, width))
+		}
+
 		// Filter models by time period if applicable.
 		models := p.Models
 		if filterDate != "" && len(p.DailyUsage) > 0 {
@@ -92,13 +102,18 @@ func renderProvidersView(aggData *provider.AggregatedData, width int, period tim
 
 			var rows [][]string
 			for _, m := range models {
+				// Estimated figures are prefixed with "~".
+				est := ""
+				if m.Estimated {
+					est = "~"
+				}
 				rows = append(rows, []string{
 					m.Model,
-					components.FormatTokens(m.InputTokens),
-					components.FormatTokens(m.OutputTokens),
+					est + components.FormatTokens(m.InputTokens),
+					est + components.FormatTokens(m.OutputTokens),
 					components.FormatTokens(m.CacheRead),
 					components.FormatTokens(m.CacheWrite),
-					fmt.Sprintf("$%.2f", m.Cost),
+					est + fmt.Sprintf("$%.2f", m.Cost),
 				})
 			}
 
@@ -111,8 +126,8 @@ func renderProvidersView(aggData *provider.AggregatedData, width int, period tim
 			sb.WriteString(table.Render())
 
 		} else if len(models) > 0 {
-			// Models without cost (e.g., Cursor file extensions).
-			sort.Slice(models, func(i, j int) bool { return models[i].Generations > models[j].Generations })
+			// Models without cost: rank by generations or responses instead.
+			sort.Slice(models, func(i, j int) bool { return modelCount(models[i]) > modelCount(models[j]) })
 
 			barWidth := width - 50
 			if barWidth < 20 {
@@ -122,8 +137,8 @@ func renderProvidersView(aggData *provider.AggregatedData, width int, period tim
 			maxGen := 0
 			maxLabelLen := 0
 			for _, m := range models {
-				if m.Generations > maxGen {
-					maxGen = m.Generations
+				if modelCount(m) > maxGen {
+					maxGen = modelCount(m)
 				}
 				if len(m.Model) > maxLabelLen {
 					maxLabelLen = len(m.Model)
@@ -143,9 +158,9 @@ func renderProvidersView(aggData *provider.AggregatedData, width int, period tim
 			}
 			for i, m := range shown {
 				color := BarColors[i%len(BarColors)]
-				bar := HorizontalBarAligned(m.Model, float64(m.Generations), float64(maxGen), alignedBarWidth, maxLabelLen, color)
+				bar := HorizontalBarAligned(m.Model, float64(modelCount(m)), float64(maxGen), alignedBarWidth, maxLabelLen, color)
 				sb.WriteString(bar)
-				sb.WriteString(StyleStatValue.Render(fmt.Sprintf("  %d", m.Generations)))
+				sb.WriteString(StyleStatValue.Render(fmt.Sprintf("  %d", modelCount(m))))
 				sb.WriteString("\n")
 			}
 		} else if len(p.Sessions) > 0 {
@@ -160,6 +175,18 @@ func renderProvidersView(aggData *provider.AggregatedData, width int, period tim
 			))
 		}
 
+		if line := renderRequestStats(p.Sessions); line != "" {
+			sb.WriteString(line)
+		}
+
+		if p.Prompts != nil && p.Prompts.Total > 0 {
+			sb.WriteString(renderPromptStats(p.Prompts, width))
+		}
+
+		for _, d := range p.Dimensions {
+			sb.WriteString(renderDimension(d, width))
+		}
+
 		// Cost attribution bar — fixed-width label so all providers align.
 		if p.TotalCost > 0 {
 			var segments []BarSegment
@@ -191,5 +218,182 @@ func renderProvidersView(aggData *provider.AggregatedData, width int, period tim
 		StyleStatCost.Render(fmt.Sprintf("$%.2f", aggData.TotalCost)),
 	))
 
+	for _, e := range aggData.Errors {
+		sb.WriteString(StyleError.Render(fmt.Sprintf("  %s failed to load: %v", e.Provider, e.Err)))
+		sb.WriteString("\n")
+	}
+
 	return sb.String()
 }
+
+// renderPromptStats renders prompt counts and the prompt length distribution.
+func renderPromptStats(ps *provider.PromptStats, width int) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("\n  %s prompts across %s sessions  avg %s chars  max %s chars\n",
+		StyleStatValue.Render(components.FormatCount(ps.Total)),
+		StyleStatValue.Render(components.FormatCount(ps.Sessions)),
+		StyleStatValue.Render(fmt.Sprintf("%.0f", ps.AvgLength)),
+		StyleStatValue.Render(components.FormatCount(ps.MaxLength)),
+	))
+
+	maxCount := 0
+	maxLabelLen := 0
+	for _, b := range ps.LengthCounts {
+		if b.Count > maxCount {
+			maxCount = b.Count
+		}
+		if len(b.Label) > maxLabelLen {
+			maxLabelLen = len(b.Label)
+		}
+	}
+	barWidth := width - maxLabelLen - 20
+	if barWidth < 10 {
+		barWidth = 10
+	}
+	for i, b := range ps.LengthCounts {
+		color := BarColors[i%len(BarColors)]
+		sb.WriteString(HorizontalBarAligned(b.Label, float64(b.Count), float64(maxCount), barWidth, maxLabelLen, color))
+		sb.WriteString(StyleStatValue.Render(fmt.Sprintf("  %d", b.Count)))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// renderQuotaGauge renders a request quota as a gauge with its reset date
+// and the daily pace that keeps usage within the allowance.
+func renderQuotaGauge(q *provider.QuotaStatus, width int) string {
+	now := time.Now()
+	pct := q.Percent()
+
+	color := ColorGreen
+	switch {
+	case pct >= 90:
+		color = ColorRed
+	case pct >= 70:
+		color = ColorYellow
+	}
+
+	barWidth := width - 60
+	if barWidth < 10 {
+		barWidth = 10
+	}
+	if barWidth > 40 {
+		barWidth = 40
+	}
+	filled := int(pct / 100 * float64(barWidth))
+	if filled > barWidth {
+		filled = barWidth
+	}
+	bar := lipgloss.NewStyle().Foreground(color).Render(strings.Repeat("█", filled)) +
+		lipgloss.NewStyle().Foreground(ColorOverlay).Render(strings.Repeat("░", barWidth-filled))
+
+	label := "Requests"
+	if q.Name != "" {
+		label = q.Name + " requests"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("  %s %s %s  %s  resets %s\n",
+		StyleMuted.Render(label),
+		bar,
+		StyleStatValue.Render(q.Usage()),
+		lipgloss.NewStyle().Foreground(color).Bold(true).Render(fmt.Sprintf("%.0f%%", pct)),
+		StyleStatValue.Render(q.ResetAt.Format("Jan 2")),
+	))
+	sb.WriteString(fmt.Sprintf("  %s/day so far  %s/day left to last the cycle",
+		StyleStatValue.Render(fmt.Sprintf("%.1f", q.DailyAverage(now))),
+		StyleStatValue.Render(fmt.Sprintf("%.1f", q.DailyBudget(now))),
+	))
+	if at, ok := q.ExhaustedAt(now); ok {
+		sb.WriteString("  " + StyleWarning.Render(q.Exhausted+" from "+at.Format("Jan 2")))
+	}
+	sb.WriteString("\n")
+
+	if len(q.ByModel) > 0 {
+		sb.WriteString(renderDimension(provider.Dimension{Name: "Requests this cycle by model", Items: q.ByModel}, width))
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// renderDimension renders a non-model breakdown (e.g. generations by file
+// type) as bars, showing at most the ten largest items.
+func renderDimension(d provider.Dimension, width int) string {
+	if len(d.Items) == 0 {
+		return ""
+	}
+	items := append([]provider.DimensionItem(nil), d.Items...)
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Count > items[j].Count })
+	if len(items) > 10 {
+		items = items[:10]
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\n  " + StyleMuted.Render(d.Name) + "\n")
+
+	maxCount := 0
+	maxLabelLen := 0
+	for _, it := range items {
+		if it.Count > maxCount {
+			maxCount = it.Count
+		}
+		if len(it.Label) > maxLabelLen {
+			maxLabelLen = len(it.Label)
+		}
+	}
+	if maxLabelLen > 20 {
+		maxLabelLen = 20
+	}
+	barWidth := width - maxLabelLen - 15
+	if barWidth < 10 {
+		barWidth = 10
+	}
+	for i, it := range items {
+		color := BarColors[i%len(BarColors)]
+		sb.WriteString(HorizontalBarAligned(it.Label, float64(it.Count), float64(maxCount), barWidth, maxLabelLen, color))
+		sb.WriteString(StyleStatValue.Render(fmt.Sprintf("  %d", it.Count)))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// renderRequestStats summarizes API request counts, error rate and latency
+// for providers whose sessions carry request-level telemetry.
+func renderRequestStats(sessions []provider.SessionInfo) string {
+	var requests, errors int
+	var latency time.Duration
+	for _, s := range sessions {
+		requests += s.Requests
+		errors += s.Errors
+		latency += s.Latency
+	}
+	if requests == 0 {
+		return ""
+	}
+	errStyle := StyleStatValue
+	errRate := float64(errors) / float64(requests) * 100
+	if errRate >= 5 {
+		errStyle = StyleWarning
+	}
+	return fmt.Sprintf("\n  API: %s requests  %s errors  avg %s\n",
+		StyleStatValue.Render(components.FormatCount(requests)),
+		errStyle.Render(fmt.Sprintf("%.1f%%", errRate)),
+		StyleStatValue.Render(formatLatency(latency/time.Duration(requests))),
+	)
+}
+
+func formatLatency(d time.Duration) string {
+	if d < time.Second {
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	}
+	return fmt.Sprintf("%.1fs", d.Seconds())
+}
+
+// modelCount is a model's code generations for tools that count them, such
+// as Cursor, and its responses otherwise.
+func modelCount(m provider.ModelBreakdown) int {
+	if m.Generations > 0 {
+		return m.Generations
+	}
+	return m.Requests
+}
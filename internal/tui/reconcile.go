@@ -0,0 +1,72 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/isaacaudet/aitop/internal/invoice"
+)
+
+// renderReconcile compares imported invoices with estimated spend, with
+// significant gaps in red.
+func renderReconcile(r *invoice.Report, width int) string {
+	if r == nil {
+		return StyleMuted.Render("No invoices imported. Run `aitop import usage <csv>` with an Anthropic Console,\n" +
+			"OpenAI usage or Google Cloud billing export to compare it with aitop's estimates.")
+	}
+
+	var sb strings.Builder
+	sb.WriteString(StyleSectionTitle.Render(fmt.Sprintf("Invoiced vs Estimated  %s to %s", r.From, r.To)))
+	sb.WriteString("\n\n")
+	total := invoice.Gap{Invoiced: r.Invoiced, Estimated: r.Estimated}
+	sb.WriteString(fmt.Sprintf("  Invoiced %s  Estimated %s  Gap %s\n",
+		StyleStatCost.Render(fmt.Sprintf("$%.2f", r.Invoiced)),
+		StyleStatValue.Render(fmt.Sprintf("$%.2f", r.Estimated)),
+		gapStyle(total).Render(total.FormatDiff()),
+	))
+
+	labelWidth := 22
+	if width < 80 {
+		labelWidth = 16
+	}
+	header := func(label string) string {
+		row := fmt.Sprintf("  %-*s %-9s %10s %10s  %s", labelWidth, label, "Vendor", "Invoiced", "Estimated", "Gap")
+		return StyleTableHeader.Render(row) + "\n"
+	}
+
+	sb.WriteString(StyleSectionTitle.Render("By Day"))
+	sb.WriteString("\n")
+	sb.WriteString(header("Date"))
+	for _, g := range r.Days {
+		sb.WriteString(renderGapRow(g.Date, g, labelWidth))
+	}
+
+	sb.WriteString(StyleSectionTitle.Render("By Model"))
+	sb.WriteString("\n")
+	sb.WriteString(header("Model"))
+	for _, g := range r.Models {
+		sb.WriteString(renderGapRow(g.Model, g, labelWidth))
+	}
+
+	sb.WriteString("\n")
+	sb.WriteString(StyleMuted.Render("  Red gaps are at least $1 and 10%. Set [reconcile] providers to count only API-billed tools."))
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+func renderGapRow(label string, g invoice.Gap, labelWidth int) string {
+	if r := []rune(label); len(r) > labelWidth {
+		label = string(r[:labelWidth])
+	}
+	row := fmt.Sprintf("  %-*s %-9s %10s %10s  ", labelWidth, label, g.Vendor,
+		fmt.Sprintf("$%.2f", g.Invoiced), fmt.Sprintf("$%.2f", g.Estimated))
+	return StyleTableRow.Render(row) + gapStyle(g).Render(g.FormatDiff()) + "\n"
+}
+
+func gapStyle(g invoice.Gap) lipgloss.Style {
+	if g.Significant() {
+		return StyleNegative.Bold(true)
+	}
+	return StyleMuted
+}
@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"cmp"
 	"fmt"
 	"sort"
 	"strings"
@@ -74,10 +75,9 @@ func (sv sessionsView) render(width int) string {
 		projectOrder := []string{}
 		projectMap := make(map[string]*projectGroup)
 		for i, s := range sv.sessions {
-			proj := s.Project
-			if proj == "" {
-				proj = "(unknown)"
-			}
+			// Group by the project's base name so full working directories
+			// (Codex, Gemini, Cursor) roll up with Claude's project names.
+			proj := projectLabel(s.Project)
 			if _, ok := projectMap[proj]; !ok {
 				projectMap[proj] = &projectGroup{name: proj}
 				projectOrder = append(projectOrder, proj)
@@ -89,6 +89,7 @@ func (sv sessionsView) render(width int) string {
 
 		columns := []components.Column{
 			{Title: "Project", Width: 22},
+			{Title: "Title", Width: 28},
 			{Title: "Date", Width: 14},
 			{Title: "Duration", Width: 10},
 			{Title: "Msgs", Width: 8, Align: 1},
@@ -104,13 +105,19 @@ func (sv sessionsView) render(width int) string {
 			if s.UserMessages > 0 {
 				msgStr = fmt.Sprintf("%d/%d", s.UserMessages, s.Messages)
 			}
+			tokStr := components.FormatTokens(s.Tokens)
+			costStr := fmt.Sprintf("$%.2f", s.Cost)
+			if s.Estimated {
+				tokStr, costStr = "~"+tokStr, "~"+costStr
+			}
 			rows = append(rows, []string{
-				truncate(s.Project, 22),
+				truncate(projectLabel(s.Project), 22),
+				truncate(s.Title, 28),
 				s.StartTime.Format("Jan 02 15:04"),
 				formatDuration(duration),
 				msgStr,
-				components.FormatTokens(s.Tokens),
-				fmt.Sprintf("$%.2f", s.Cost),
+				tokStr,
+				costStr,
 				truncate(s.Model, 16),
 			})
 		}
@@ -135,8 +142,8 @@ func (sv sessionsView) render(width int) string {
 			})
 			maxLabelLen := 0
 			for _, name := range projectOrder {
-				if len(name) > maxLabelLen {
-					maxLabelLen = len(name)
+				if len(provider.ShortProject(name)) > maxLabelLen {
+					maxLabelLen = len(provider.ShortProject(name))
 				}
 			}
 			if maxLabelLen > 30 {
@@ -162,7 +169,7 @@ func (sv sessionsView) render(width int) string {
 					continue
 				}
 				color := BarColors[i%len(BarColors)]
-				displayName := truncate(name, maxLabelLen)
+				displayName := truncate(provider.ShortProject(name), maxLabelLen)
 				bar := HorizontalBarAligned(displayName, pg.cost, maxProjCost, barWidth, maxLabelLen, color)
 				sb.WriteString(bar)
 				sb.WriteString(StyleStatCost.Render(fmt.Sprintf("  $%.2f", pg.cost)))
@@ -186,7 +193,10 @@ func renderSessionDetailFromProvider(s provider.SessionInfo) string {
 	sb.WriteString(StyleSectionTitle.Render("Session Detail"))
 	sb.WriteString("\n\n")
 	sb.WriteString(fmt.Sprintf("  ID:       %s\n", StyleStatValue.Render(s.ID)))
-	sb.WriteString(fmt.Sprintf("  Project:  %s\n", StyleStatValue.Render(s.Project)))
+	if s.Title != "" {
+		sb.WriteString(fmt.Sprintf("  Title:    %s\n", StyleStatValue.Render(s.Title)))
+	}
+	sb.WriteString(fmt.Sprintf("  Project:  %s\n", StyleStatValue.Render(cmp.Or(s.Project, projectLabel("")))))
 	if s.Model != "" {
 		sb.WriteString(fmt.Sprintf("  Model:    %s\n", StyleStatValue.Render(s.Model)))
 	}
@@ -202,11 +212,26 @@ func renderSessionDetailFromProvider(s provider.SessionInfo) string {
 		}
 		sb.WriteString(fmt.Sprintf("  Messages: %s\n", StyleStatValue.Render(msgStr)))
 	}
+	if s.Requests > 0 {
+		reqStr := fmt.Sprintf("%d (%d failed, avg %s)", s.Requests, s.Errors, formatLatency(s.Latency/time.Duration(s.Requests)))
+		sb.WriteString(fmt.Sprintf("  Requests: %s\n", StyleStatValue.Render(reqStr)))
+	}
+	if s.Prompts > 0 {
+		sb.WriteString(fmt.Sprintf("  Prompts:  %s\n", StyleStatValue.Render(fmt.Sprintf("%d", s.Prompts))))
+	}
+	estimated := ""
+	if s.Estimated {
+		estimated = StyleMuted.Render(" (estimated)")
+	}
 	if s.Tokens > 0 {
-		sb.WriteString(fmt.Sprintf("  Tokens:   %s\n", StyleStatValue.Render(components.FormatTokens(s.Tokens))))
+		sb.WriteString(fmt.Sprintf("  Tokens:   %s%s\n", StyleStatValue.Render(components.FormatTokens(s.Tokens)), estimated))
 	}
 	if s.Cost > 0 {
-		sb.WriteString(fmt.Sprintf("  Cost:     %s\n", StyleStatCost.Render(fmt.Sprintf("$%.2f", s.Cost))))
+		sb.WriteString(fmt.Sprintf("  Cost:     %s%s\n", StyleStatCost.Render(fmt.Sprintf("$%.2f", s.Cost)), estimated))
+	}
+
+	if len(s.Turns) > 0 {
+		sb.WriteString(renderSessionTurns(s.Turns))
 	}
 
 	sb.WriteString("\n")
@@ -216,6 +241,57 @@ func renderSessionDetailFromProvider(s provider.SessionInfo) string {
 	return sb.String()
 }
 
+// maxDetailTurns caps the per-turn table in the session detail view.
+const maxDetailTurns = 15
+
+// renderSessionTurns renders the most recent per-response usage rows of a session.
+func renderSessionTurns(turns []provider.TurnUsage) string {
+	var sb strings.Builder
+	sb.WriteString("\n")
+	sb.WriteString(StyleSectionTitle.Render(fmt.Sprintf("Turns (%d)", len(turns))))
+	sb.WriteString("\n")
+
+	shown := turns
+	if len(shown) > maxDetailTurns {
+		shown = shown[len(shown)-maxDetailTurns:]
+	}
+
+	columns := []components.Column{
+		{Title: "Time", Width: 14},
+		{Title: "Model", Width: 18},
+		{Title: "Input", Width: 10, Align: 1},
+		{Title: "Output", Width: 10, Align: 1},
+		{Title: "Cache R/W", Width: 14, Align: 1},
+		{Title: "Cost", Width: 10, Align: 1},
+	}
+	var rows [][]string
+	for _, t := range shown {
+		ts := "--"
+		if !t.Timestamp.IsZero() {
+			ts = t.Timestamp.Local().Format("Jan 02 15:04")
+		}
+		rows = append(rows, []string{
+			ts,
+			truncate(t.Model, 18),
+			components.FormatTokens(t.InputTokens),
+			components.FormatTokens(t.OutputTokens),
+			components.FormatTokens(t.CacheRead) + "/" + components.FormatTokens(t.CacheWrite),
+			fmt.Sprintf("$%.4f", t.Cost),
+		})
+	}
+	table := components.Table{
+		Columns:    columns,
+		Rows:       rows,
+		Selected:   -1,
+		MaxVisible: len(rows),
+	}
+	sb.WriteString(table.Render())
+	if len(turns) > len(shown) {
+		sb.WriteString(StyleMuted.Render(fmt.Sprintf("  … %d earlier turns\n", len(turns)-len(shown))))
+	}
+	return sb.String()
+}
+
 func formatDuration(d time.Duration) string {
 	if d <= 0 {
 		return "--"
@@ -229,9 +305,16 @@ func formatDuration(d time.Duration) string {
 	return fmt.Sprintf("%dh%dm", int(d.Hours()), int(d.Minutes())%60)
 }
 
+// projectLabel is a session's short project name, or a placeholder for
+// sessions whose logs don't say where they ran.
+func projectLabel(project string) string {
+	return cmp.Or(provider.ShortProject(project), "(unknown)")
+}
+
 func truncate(s string, max int) string {
-	if len(s) <= max {
+	r := []rune(s)
+	if len(r) <= max {
 		return s
 	}
-	return s[:max-1] + "…"
+	return string(r[:max-1]) + "…"
 }